@@ -0,0 +1,121 @@
+// Package apperrors defines the typed errors the services return. Each error
+// carries a kind, which decides the HTTP status it is rendered with, and a
+// stable machine-readable code that clients can branch on instead of the
+// human-readable message.
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindRateLimited  Kind = "rate_limited"
+)
+
+// FieldError describes what is wrong with one field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error. Two errors with the same code match under
+// errors.Is, so a sentinel still matches after its message was reworded with
+// Withf or field details were attached.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	// RetryAfter tells the client how long to wait before trying again.
+	RetryAfter time.Duration
+	cause      error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return New(KindRateLimited, code, message)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Withf returns a copy of the error with a formatted message.
+func (e *Error) Withf(format string, args ...any) *Error {
+	copied := *e
+	copied.Message = fmt.Sprintf(format, args...)
+	return &copied
+}
+
+// WithFields returns a copy of the error with per-field details.
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &copied
+}
+
+// WithRetryAfter returns a copy of the error telling the client to retry after
+// the given duration.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	copied := *e
+	copied.RetryAfter = d
+	return &copied
+}
+
+// Wrap returns a copy of the error that keeps err as its cause, for logging;
+// the cause is never shown to clients.
+func (e *Error) Wrap(err error) *Error {
+	copied := *e
+	copied.cause = err
+	return &copied
+}
+
+// As returns the domain error in err's chain, if any.
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
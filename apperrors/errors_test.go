@@ -0,0 +1,50 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMatchesByCode(t *testing.T) {
+	notFound := NotFound("leave_request_not_found", "leave request not found")
+
+	reworded := notFound.Withf("leave request %d not found", 7)
+	assert.ErrorIs(t, reworded, notFound)
+	assert.Equal(t, "leave request 7 not found", reworded.Error())
+	assert.Equal(t, "leave request not found", notFound.Error())
+
+	wrapped := fmt.Errorf("loading: %w", reworded)
+	appErr, ok := As(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, KindNotFound, appErr.Kind)
+
+	assert.NotErrorIs(t, notFound, NotFound("employee_not_found", "employee not found"))
+	_, ok = As(errors.New("boom"))
+	assert.False(t, ok)
+}
+
+func TestWithFieldsAndWrap(t *testing.T) {
+	validation := Validation("validation_error", "Validation failed")
+	cause := errors.New("parse error")
+
+	withFields := validation.WithFields(FieldError{Field: "start_date", Message: "is required"}).Wrap(cause)
+
+	assert.Len(t, withFields.Fields, 1)
+	assert.Empty(t, validation.Fields)
+	assert.ErrorIs(t, withFields, cause)
+	assert.Equal(t, "Validation failed", withFields.Error())
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	rateLimited := RateLimited("too_many_login_attempts", "too many login attempts")
+
+	withRetry := rateLimited.WithRetryAfter(90 * time.Second)
+
+	assert.Equal(t, 90*time.Second, withRetry.RetryAfter)
+	assert.Zero(t, rateLimited.RetryAfter)
+	assert.ErrorIs(t, withRetry, rateLimited)
+}
@@ -6,6 +6,10 @@ type AppConfig struct {
 	Port        int    `mapstructure:"port"`
 	Environment string `mapstructure:"environment"`
 	LogLevel    string `mapstructure:"log_level"`
+	// ProxyHeader names the header holding the client IP, e.g.
+	// X-Forwarded-For, when the API runs behind a reverse proxy. Leave it
+	// empty otherwise, as clients could forge it.
+	ProxyHeader string `mapstructure:"proxy_header"`
 }
 
 type DatabaseConfig struct {
@@ -17,14 +21,151 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	Expiration int    `mapstructure:"expiration"` // in hours
+	// Algorithm is HS256 (the default), which signs with Secret, or RS256 or
+	// ES256, which sign with the private key of SigningKeyID.
+	Algorithm string `mapstructure:"algorithm"`
+	Secret    string `mapstructure:"secret"`
+	// Keys lists the asymmetric keys accepted when verifying tokens. Keep a
+	// retired key, with only its public key, until the tokens it signed have
+	// expired.
+	Keys []JWTKeyConfig `mapstructure:"keys"`
+	// SigningKeyID is the kid of the key that signs new tokens. Defaults to
+	// the first key with a private key.
+	SigningKeyID string `mapstructure:"signing_key_id"`
+	// Issuer and Audience are the iss and aud claims of access tokens, which
+	// services verifying them with the published keys must check. Both
+	// default to hr-leave-request.
+	Issuer            string `mapstructure:"issuer"`
+	Audience          string `mapstructure:"audience"`
+	AccessExpiration  int    `mapstructure:"access_expiration"`  // in minutes, defaults to 15
+	RefreshExpiration int    `mapstructure:"refresh_expiration"` // in hours, defaults to 720
+}
+
+type JWTKeyConfig struct {
+	// ID is the kid the key is published and looked up under.
+	ID string `mapstructure:"id"`
+	// PrivateKeyFile is the PEM file of the private key, needed to sign.
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	// PublicKeyFile is the PEM file of the public key. It is only needed for
+	// keys without a private key.
+	PublicKeyFile string `mapstructure:"public_key_file"`
+}
+
+type AuthConfig struct {
+	// AllowSelfRegistration keeps POST /auth/register open. Self-registered
+	// accounts always get the employee role; everyone else joins through an
+	// invitation.
+	AllowSelfRegistration bool `mapstructure:"allow_self_registration"`
+	// InvitationExpiration is how long an invitation can be accepted, in
+	// hours. Defaults to 72.
+	InvitationExpiration int `mapstructure:"invitation_expiration"`
+	// PasswordResetExpiration is how long a password reset token can be
+	// used, in minutes. Defaults to 30.
+	PasswordResetExpiration int `mapstructure:"password_reset_expiration"`
+	// PasswordResetURL is the page of the client that sets the new password.
+	// The reset token is appended to it as the token query parameter.
+	PasswordResetURL string `mapstructure:"password_reset_url"`
+	// LoginThrottle limits failed login attempts per account and per client IP.
+	LoginThrottle LoginThrottleConfig `mapstructure:"login_throttle"`
+	// MFA configures TOTP two-factor authentication.
+	MFA MFAConfig `mapstructure:"mfa"`
+	// OIDC configures single sign-on with an OpenID Connect provider.
+	OIDC OIDCConfig `mapstructure:"oidc"`
+}
+
+type OIDCConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// IssuerURL is the issuer of the provider; its metadata is discovered
+	// from /.well-known/openid-configuration below it.
+	IssuerURL    string `mapstructure:"issuer_url"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	// RedirectURL is the callback registered with the provider, i.e. the
+	// public URL of GET /api/v1/auth/oidc/callback.
+	RedirectURL string `mapstructure:"redirect_url"`
+	// Scopes requested besides openid. Defaults to email and profile.
+	Scopes []string `mapstructure:"scopes"`
+	// EmailClaim and GroupsClaim name the ID token claims holding the email
+	// address and the groups. Default to "email" and "groups".
+	EmailClaim  string `mapstructure:"email_claim"`
+	GroupsClaim string `mapstructure:"groups_claim"`
+	// AutoProvision creates an employee on the first login of an unknown
+	// email address. Otherwise only existing employees can sign in.
+	AutoProvision bool `mapstructure:"auto_provision"`
+	// RoleMappings gives the role of the first mapping whose group is among
+	// the groups of the user. Users without a match get DefaultRole, which
+	// defaults to "employee".
+	RoleMappings []OIDCRoleMappingConfig `mapstructure:"role_mappings"`
+	DefaultRole  string                  `mapstructure:"default_role"`
+	// SyncRoles updates the role of existing employees from their groups at
+	// every login. Otherwise the mapping only applies to new employees.
+	SyncRoles bool `mapstructure:"sync_roles"`
+}
+
+type OIDCRoleMappingConfig struct {
+	Group string `mapstructure:"group"`
+	Role  string `mapstructure:"role"`
+}
+
+type MFAConfig struct {
+	// Issuer is the name authenticator apps show next to the account.
+	// Defaults to "HR Leave Request".
+	Issuer string `mapstructure:"issuer"`
+	// RequiredForApprovers makes two-factor authentication mandatory for the
+	// roles that can approve leave: those holding leave:approve:all or
+	// leave:read:team, and those named in an approval chain.
+	RequiredForApprovers bool `mapstructure:"required_for_approvers"`
+}
+
+type LoginThrottleConfig struct {
+	// MaxAttempts is how many failed logins an account may have within the
+	// window before it is locked. Defaults to 5.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// MaxIPAttempts is how many failed logins, across all accounts, a client
+	// IP may have within the window before it is locked. Defaults to 20.
+	MaxIPAttempts int `mapstructure:"max_ip_attempts"`
+	// Window is the period failed logins are counted over, in minutes.
+	// Defaults to 15.
+	Window int `mapstructure:"window"`
+	// LockoutDuration is how long an account or IP stays locked, in minutes.
+	// Defaults to 15.
+	LockoutDuration int `mapstructure:"lockout_duration"`
+}
+
+type LeaveConfig struct {
+	// Entitlements holds the default annual entitlement in days per leave type.
+	// Leave types without an entry are not tracked against a balance.
+	Entitlements map[string]float64 `mapstructure:"entitlements"`
+	// ApprovalChains lists the ordered approval steps per leave type. Leave
+	// types without an entry only need their line manager's approval.
+	ApprovalChains map[string][]ApprovalStepConfig `mapstructure:"approval_chains"`
+}
+
+type ApprovalStepConfig struct {
+	// Approver is either "line_manager" or the role that signs off the step,
+	// e.g. "hr".
+	Approver string `mapstructure:"approver"`
+	// MinDays limits the step to requests of at least this many working days.
+	MinDays float64 `mapstructure:"min_days"`
+}
+
+type CalendarConfig struct {
+	// Weekend lists the non-working weekdays by name, e.g. "saturday".
+	Weekend []string `mapstructure:"weekend"`
+	// Holidays lists public holidays as YYYY-MM-DD dates.
+	Holidays []string `mapstructure:"holidays"`
+	// HoursPerDay is the length of a working day, used to convert hourly
+	// leave into days. Defaults to 8.
+	HoursPerDay float64 `mapstructure:"hours_per_day"`
 }
 
 type ApplicationConfig struct {
 	AppConfig AppConfig      `mapstructure:"app"`
 	Database  DatabaseConfig `mapstructure:"database"`
 	JWT       JWTConfig      `mapstructure:"jwt"`
+	Auth      AuthConfig     `mapstructure:"auth"`
+	Leave     LeaveConfig    `mapstructure:"leave"`
+	Calendar  CalendarConfig `mapstructure:"calendar"`
 }
 
 func LoadConfig() (*ApplicationConfig, error) {
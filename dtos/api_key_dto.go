@@ -0,0 +1,24 @@
+package dtos
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" validate:"required,min=3,max=100"`
+	Permissions []string   `json:"permissions" validate:"required,min=1,dive,required,max=100"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse describes an API key. Key is only returned when the key is
+// created; afterwards it can only be recognised by its prefix.
+type APIKeyResponse struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	CreatedBy   uint       `json:"created_by"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	Key         string     `json:"key,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -1,18 +1,75 @@
 package dtos
 
+import "time"
+
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
 
 type RegisterRequest struct {
-	Name     string  `json:"name" validate:"required,min=3,max=100"`
-	Email    string  `json:"email" validate:"required,email,max=100"`
-	Password string  `json:"password" validate:"required,min=6,max=255"`
-	Role     *string `json:"role" validate:"omitempty,oneof=employee hr manager"`
+	Name     string `json:"name" validate:"required,min=3,max=100"`
+	Email    string `json:"email" validate:"required,email,max=100"`
+	Password string `json:"password" validate:"required,min=6,max=255"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required,min=3,max=100"`
+	Password string `json:"password" validate:"required,min=6,max=255"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest optionally names the refresh token to revoke along with the
+// access token used for the call.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6,max=255"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,max=255"`
+}
+
+// AuthResponse carries a short-lived access token in Token and the refresh
+// token to obtain the next one.
 type AuthResponse struct {
-	Token string           `json:"token"`
-	User  EmployeeResponse `json:"user"`
+	Token            string           `json:"token"`
+	ExpiresAt        time.Time        `json:"expires_at"`
+	RefreshToken     string           `json:"refresh_token"`
+	RefreshExpiresAt time.Time        `json:"refresh_expires_at"`
+	User             EmployeeResponse `json:"user"`
+}
+
+// LoginResponse is the outcome of a login. When the employee has two-factor
+// authentication enabled it only carries MFAToken, to be exchanged for the
+// tokens together with a code; otherwise it carries the AuthResponse fields.
+// MFAEnrollmentRequired tells an employee whose role requires two-factor
+// authentication to set it up before using the API.
+type LoginResponse struct {
+	*AuthResponse
+	MFARequired           bool       `json:"mfa_required,omitempty"`
+	MFAToken              string     `json:"mfa_token,omitempty"`
+	MFATokenExpiresAt     *time.Time `json:"mfa_token_expires_at,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+}
+
+// MFALoginRequest completes a login with the token from LoginResponse and
+// either a TOTP code or a recovery code.
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=20"`
 }
@@ -3,27 +3,101 @@ package dtos
 import "time"
 
 type CreateEmployeeRequest struct {
-	Name     string  `json:"name" validate:"required,min=3,max=100"`
-	Email    string  `json:"email" validate:"required,email,max=100"`
-	Password string  `json:"password" validate:"required,min=6,max=255"`
-	Role     *string `json:"role" validate:"omitempty,oneof=employee hr manager"`
+	Name      string  `json:"name" validate:"required,min=3,max=100"`
+	Email     string  `json:"email" validate:"required,email,max=100"`
+	Password  string  `json:"password" validate:"required,min=6,max=255"`
+	Role      *string `json:"role" validate:"omitempty,max=50"`
+	ManagerID *uint   `json:"manager_id" validate:"omitempty,min=1"`
+}
+
+// UpdateEmployeeRequest changes the given fields of an employee; omitted
+// fields are left as they are.
+type UpdateEmployeeRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=3,max=100"`
+	Email *string `json:"email" validate:"omitempty,email,max=100"`
+	Role  *string `json:"role" validate:"omitempty,max=50"`
+}
+
+// AssignManagerRequest sets the line manager of an employee; a null
+// manager_id removes it.
+type AssignManagerRequest struct {
+	ManagerID *uint `json:"manager_id" validate:"omitempty,min=1"`
 }
 
 type EmployeeResponse struct {
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Role          *string    `json:"role,omitempty"`
+	ManagerID     *uint      `json:"manager_id,omitempty"`
+	StartDate     *time.Time `json:"start_date,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+}
+
+// EmployeeRoleChangeResponse is one entry of an employee's role history.
+// FromRole is null for the role granted on creation, ActorID for roles
+// granted without an actor, such as through single sign-on.
+type EmployeeRoleChangeResponse struct {
 	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      *string   `json:"role,omitempty"`
+	ActorID   *uint     `json:"actor_id"`
+	FromRole  *string   `json:"from_role"`
+	ToRole    string    `json:"to_role"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmployeePreferences are personal settings for clients to honour, such as
+// the language and time zone to show dates in.
+type EmployeePreferences struct {
+	Locale   *string `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"`
+}
+
+// ProfileResponse is the signed-in employee's own record.
+type ProfileResponse struct {
+	EmployeeResponse
+	Preferences EmployeePreferences `json:"preferences"`
+}
+
+// UpdateProfileRequest changes what employees may edit about themselves.
+// Omitted fields are left as they are; an empty preference clears it.
+type UpdateProfileRequest struct {
+	Name        *string              `json:"name" validate:"omitempty,min=3,max=100"`
+	Preferences *EmployeePreferences `json:"preferences"`
 }
 
 type GetEmployeesRequest struct {
-	Page     int    `query:"page" validate:"min=1"`
-	PageSize int    `query:"page_size" validate:"min=1,max=100"`
-	Search   string `query:"search"`
-	SortBy   string `query:"sort_by" validate:"omitempty,oneof=name email created_at"`
-	SortDir  string `query:"sort_dir" validate:"omitempty,oneof=asc desc"`
+	Page           int    `query:"page" validate:"omitempty,min=1"`
+	PageSize       int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+	Search         string `query:"search"`
+	SortBy         string `query:"sort_by" validate:"omitempty,oneof=name email created_at"`
+	SortDir        string `query:"sort_dir" validate:"omitempty,oneof=asc desc"`
+	IncludeDeleted bool   `query:"include_deleted"`
+}
+
+// ImportEmployeesRequest holds the query parameters of an employee import;
+// a dry run only validates the file.
+type ImportEmployeesRequest struct {
+	DryRun bool `query:"dry_run"`
+}
+
+// ImportEmployeesResponse reports on a CSV import. Rows are numbered as in
+// the file, the header being row 1. Employees are only listed once imported,
+// never for a dry run.
+type ImportEmployeesResponse struct {
+	DryRun    bool               `json:"dry_run"`
+	Rows      int                `json:"rows"`
+	Imported  int                `json:"imported"`
+	Errors    []ImportRowError   `json:"errors"`
+	Employees []EmployeeResponse `json:"employees,omitempty"`
+}
+
+// ImportRowError describes what is wrong with one column of an import row.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 type PaginationMetadata struct {
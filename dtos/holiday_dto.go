@@ -0,0 +1,32 @@
+package dtos
+
+import "time"
+
+type CreateHolidayRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name" validate:"required,max=150"`
+}
+
+type UpdateHolidayRequest struct {
+	Date *string `json:"date" validate:"omitempty,datetime=2006-01-02"`
+	Name *string `json:"name" validate:"omitempty,max=150"`
+}
+
+type GetHolidaysRequest struct {
+	Year int `query:"year" validate:"omitempty,min=2000,max=2100"`
+}
+
+type HolidayResponse struct {
+	ID        uint      `json:"id"`
+	Date      string    `json:"date"`
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ImportHolidaysResponse struct {
+	Imported  int    `json:"imported"`
+	FirstDate string `json:"first_date"`
+	LastDate  string `json:"last_date"`
+}
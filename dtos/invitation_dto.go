@@ -0,0 +1,22 @@
+package dtos
+
+import "time"
+
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+	Role  string `json:"role" validate:"required,max=50"`
+}
+
+// InvitationResponse describes an invitation. Token is only returned when the
+// invitation is created, to be handed to the invitee.
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  uint       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
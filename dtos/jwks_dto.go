@@ -0,0 +1,21 @@
+package dtos
+
+// JWK is a public key in JSON Web Key format (RFC 7517). RSA keys set N and
+// E, EC keys Crv, X and Y.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSResponse is the JSON Web Key Set other services verify our tokens
+// with.
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,19 @@
+package dtos
+
+type GetLeaveBalancesRequest struct {
+	Year int `query:"year" validate:"omitempty,min=2000,max=2100"`
+}
+
+type SetLeaveEntitlementRequest struct {
+	Type         string   `json:"type" validate:"required,oneof=sick vacation personal other"`
+	Year         int      `json:"year" validate:"required,min=2000,max=2100"`
+	EntitledDays *float64 `json:"entitled_days" validate:"required,min=0,max=366"`
+}
+
+type LeaveBalanceResponse struct {
+	Type          string  `json:"type"`
+	Year          int     `json:"year"`
+	EntitledDays  float64 `json:"entitled_days"`
+	UsedDays      float64 `json:"used_days"`
+	RemainingDays float64 `json:"remaining_days"`
+}
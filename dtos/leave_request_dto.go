@@ -1,40 +1,78 @@
 package dtos
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type CreateLeaveRequestRequest struct {
-	StartDate time.Time `json:"start_date" validate:"required"`
-	EndDate   time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
-	Type      string    `json:"type" validate:"required,oneof=sick vacation personal other"`
-	Reason    *string   `json:"reason" validate:"omitempty"`
+	StartDate   time.Time `json:"start_date" validate:"required"`
+	EndDate     time.Time `json:"end_date" validate:"required,gtefield=StartDate"`
+	Granularity string    `json:"granularity" validate:"omitempty,oneof=full_day half_day_am half_day_pm hours"`
+	Type        string    `json:"type" validate:"required,oneof=sick vacation personal other"`
+	Reason      *string   `json:"reason" validate:"omitempty"`
+	Draft       bool      `json:"draft"`
 }
 
 type UpdateLeaveRequestRequest struct {
-	StartDate *time.Time `json:"start_date" validate:"omitempty"`
-	EndDate   *time.Time `json:"end_date" validate:"omitempty,gtfield=StartDate"`
-	Type      *string    `json:"type" validate:"omitempty,oneof=sick vacation personal other"`
-	Status    *string    `json:"status" validate:"omitempty,oneof=pending approved rejected"`
-	Reason    *string    `json:"reason" validate:"omitempty"`
+	StartDate   *time.Time `json:"start_date" validate:"omitempty"`
+	EndDate     *time.Time `json:"end_date" validate:"omitempty"`
+	Granularity *string    `json:"granularity" validate:"omitempty,oneof=full_day half_day_am half_day_pm hours"`
+	Type        *string    `json:"type" validate:"omitempty,oneof=sick vacation personal other"`
+	Reason      *string    `json:"reason" validate:"omitempty"`
+}
+
+// DecideLeaveRequestRequest is the optional body of the approve and reject
+// endpoints. A comment is mandatory when rejecting.
+type DecideLeaveRequestRequest struct {
+	Comment *string `json:"comment" validate:"omitempty,max=1000"`
 }
 
 type LeaveRequestResponse struct {
-	ID         uint              `json:"id"`
-	EmployeeID uint              `json:"employee_id"`
-	Employee   *EmployeeResponse `json:"employee,omitempty"`
-	StartDate  time.Time         `json:"start_date"`
-	EndDate    time.Time         `json:"end_date"`
-	Type       string            `json:"type"`
-	Status     string            `json:"status"`
-	Reason     *string           `json:"reason,omitempty"`
-	CreatedAt  time.Time         `json:"created_at"`
-	UpdatedAt  time.Time         `json:"updated_at"`
+	ID              uint              `json:"id"`
+	EmployeeID      uint              `json:"employee_id"`
+	Employee        *EmployeeResponse `json:"employee,omitempty"`
+	StartDate       time.Time         `json:"start_date"`
+	EndDate         time.Time         `json:"end_date"`
+	Granularity     string            `json:"granularity"`
+	WorkingDays     float64           `json:"working_days"`
+	Type            string            `json:"type"`
+	Status          string            `json:"status"`
+	Reason          *string           `json:"reason,omitempty"`
+	DecisionComment *string           `json:"decision_comment,omitempty"`
+	DecidedBy       *uint             `json:"decided_by,omitempty"`
+	DecidedAt       *time.Time        `json:"decided_at,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// LeaveRequestApprovalResponse is one step of the approval chain of a leave
+// request.
+type LeaveRequestApprovalResponse struct {
+	Step      int        `json:"step"`
+	Approver  string     `json:"approver"`
+	Status    string     `json:"status"`
+	DecidedBy *uint      `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+	Comment   *string    `json:"comment,omitempty"`
+}
+
+// LeaveRequestEventResponse is one entry of a leave request's history.
+// Changes maps each changed field to its "from" and "to" values.
+type LeaveRequestEventResponse struct {
+	ID             uint            `json:"id"`
+	LeaveRequestID uint            `json:"leave_request_id"`
+	ActorID        uint            `json:"actor_id"`
+	Action         string          `json:"action"`
+	Changes        json.RawMessage `json:"changes"`
+	CreatedAt      time.Time       `json:"created_at"`
 }
 
 type GetLeaveRequestsRequest struct {
-	Page       int        `query:"page" validate:"min=1"`
-	PageSize   int        `query:"page_size" validate:"min=1,max=100"`
+	Page       int        `query:"page" validate:"omitempty,min=1"`
+	PageSize   int        `query:"page_size" validate:"omitempty,min=1,max=100"`
 	EmployeeID *uint      `query:"employee_id"`
-	Status     *string    `query:"status" validate:"omitempty,oneof=pending approved rejected"`
+	Status     *string    `query:"status" validate:"omitempty,oneof=draft pending approved rejected cancelled withdrawn cancellation_requested"`
 	Type       *string    `query:"type" validate:"omitempty,oneof=sick vacation personal other"`
 	StartDate  *time.Time `query:"start_date"`
 	EndDate    *time.Time `query:"end_date"`
@@ -42,6 +80,11 @@ type GetLeaveRequestsRequest struct {
 	SortDir    string     `query:"sort_dir" validate:"omitempty,oneof=asc desc"`
 }
 
+type GetPendingApprovalsRequest struct {
+	Page     int `query:"page" validate:"omitempty,min=1"`
+	PageSize int `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
 type GetLeaveRequestsResponse struct {
 	Data       []LeaveRequestResponse `json:"data"`
 	Pagination PaginationMetadata     `json:"pagination"`
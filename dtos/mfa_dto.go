@@ -0,0 +1,21 @@
+package dtos
+
+// MFAEnrollmentResponse carries the TOTP secret to add to an authenticator
+// app, either typed in or scanned from ProvisioningURI as a QR code.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFACodeRequest proves possession of the second factor with either a TOTP
+// code or a recovery code.
+type MFACodeRequest struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=20"`
+}
+
+// MFARecoveryCodesResponse lists new recovery codes. They are only shown
+// once.
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
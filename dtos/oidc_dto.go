@@ -0,0 +1,11 @@
+package dtos
+
+// OIDCCallbackRequest is what the identity provider sends back to the
+// redirect URL: an authorization code, or an error when the user did not
+// sign in.
+type OIDCCallbackRequest struct {
+	Code             string `query:"code" validate:"required_without=Error"`
+	State            string `query:"state" validate:"required"`
+	Error            string `query:"error"`
+	ErrorDescription string `query:"error_description"`
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyHandler struct {
+	service   services.APIKeyService
+	validator *validator.Validate
+}
+
+func NewAPIKeyHandler(service services.APIKeyService, validator *validator.Validate) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req dtos.CreateAPIKeyRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID and role from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	apiKey, err := h.service.CreateAPIKey(userID, userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create API key")
+		return err
+	}
+
+	logrus.WithField("api_key_id", apiKey.ID).Info("API key created successfully")
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "API key created successfully, store it now as it cannot be shown again",
+		Data:    apiKey,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	apiKeys, err := h.service.GetAPIKeys(userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get API keys")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "API keys retrieved successfully",
+		Data:    apiKeys,
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid API key ID")
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	err = h.service.RevokeAPIKey(uint(id), userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to revoke API key")
+		return err
+	}
+
+	logrus.WithField("api_key_id", id).Info("API key revoked successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+	})
+}
@@ -9,41 +9,29 @@ import (
 )
 
 type AuthHandler struct {
-	authService services.AuthService
-	validator   *validator.Validate
+	authService     services.AuthService
+	passwordService services.PasswordService
+	validator       *validator.Validate
 }
 
-func NewAuthHandler(authService services.AuthService, validator *validator.Validate) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, passwordService services.PasswordService, validator *validator.Validate) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validator:   validator,
+		authService:     authService,
+		passwordService: passwordService,
+		validator:       validator,
 	}
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req dtos.LoginRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "bad_request",
-			Message: "Invalid request body",
-		})
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "validation_error",
-			Message: "Validation failed",
-			Details: err.Error(),
-		})
-	}
-
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, c.IP())
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(dtos.ErrorResponse{
-			Error:   "unauthorized",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -52,28 +40,146 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req dtos.RegisterRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "bad_request",
-			Message: "Invalid request body",
-		})
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "validation_error",
-			Message: "Validation failed",
-			Details: err.Error(),
-		})
+	response, err := h.authService.Register(&req)
+	if err != nil {
+		return err
 	}
 
-	response, err := h.authService.Register(&req)
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// LoginMFA completes a login with a TOTP or recovery code.
+func (h *AuthHandler) LoginMFA(c *fiber.Ctx) error {
+	var req dtos.MFALoginRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	response, err := h.authService.LoginMFA(&req, c.IP())
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// AcceptInvitation registers an invited employee from their invitation token.
+func (h *AuthHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req dtos.AcceptInvitationRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	response, err := h.authService.AcceptInvitation(&req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "registration_failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(response)
 }
+
+// Refresh exchanges a refresh token for a new access and refresh token.
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req dtos.RefreshTokenRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	response, err := h.authService.Refresh(&req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Logout revokes the caller's access token and, when the body names one,
+// their refresh token.
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req dtos.LogoutRequest
+
+	// The body is optional
+	if len(c.Body()) > 0 {
+		if err := bindBody(c, h.validator, &req); err != nil {
+			return err
+		}
+	}
+
+	// Get token claims from JWT middleware
+	claims := c.Locals("token_claims").(*services.AccessClaims)
+
+	if err := h.authService.Logout(claims, &req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Logged out successfully",
+	})
+}
+
+// ForgotPassword sends a password reset token to the employee. It answers the
+// same whether or not the email belongs to an employee.
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dtos.ForgotPasswordRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	if err := h.passwordService.ForgotPassword(&req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "If the email belongs to an employee, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password with a password reset token.
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dtos.ResetPasswordRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	if err := h.passwordService.ResetPassword(&req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Password reset successfully, please log in again",
+	})
+}
+
+// ChangePassword replaces the caller's password after checking the current
+// one.
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	var req dtos.ChangePasswordRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	if err := h.passwordService.ChangePassword(userID, &req); err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Password changed successfully, please log in again",
+	})
+}
@@ -1,45 +1,52 @@
 package handlers
 
 import (
+	"bytes"
+	"hr-leave-request/apperrors"
 	"hr-leave-request/dtos"
 	"hr-leave-request/services"
+	"io"
 	"strconv"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
 type EmployeeHandler struct {
-	service services.EmployeeService
+	service       services.EmployeeService
+	importService services.EmployeeImportService
+	validator     *validator.Validate
 }
 
-func NewEmployeeHandler(service services.EmployeeService) *EmployeeHandler {
-	return &EmployeeHandler{service: service}
+func NewEmployeeHandler(service services.EmployeeService, importService services.EmployeeImportService, validator *validator.Validate) *EmployeeHandler {
+	return &EmployeeHandler{
+		service:       service,
+		importService: importService,
+		validator:     validator,
+	}
 }
 
 func (h *EmployeeHandler) CreateEmployee(c *fiber.Ctx) error {
 	var req dtos.CreateEmployeeRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		logrus.WithError(err).Error("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
 	}
 
-	employee, err := h.service.CreateEmployee(&req)
+	employee, err := h.service.CreateEmployee(userID, userRole, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create employee")
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "email already exists" {
-			statusCode = fiber.StatusConflict
-		}
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Create Failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	logrus.WithField("employee_id", employee.ID).Info("Employee created successfully")
@@ -50,27 +57,83 @@ func (h *EmployeeHandler) CreateEmployee(c *fiber.Ctx) error {
 	})
 }
 
+// ImportEmployees accepts a CSV file either as the "file" field of a
+// multipart form or as the raw request body. With dry_run=true the file is
+// only validated.
+func (h *EmployeeHandler) ImportEmployees(c *fiber.Ctx) error {
+	var req dtos.ImportEmployeesRequest
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	var file io.Reader = bytes.NewReader(c.Body())
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		upload, err := fileHeader.Open()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to open uploaded employee import file")
+			return services.ErrInvalidEmployeeImportFile.Wrap(err)
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	result, err := h.importService.ImportEmployees(userID, userRole, file, req.DryRun)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to import employees")
+		return err
+	}
+
+	if result.DryRun {
+		return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+			Success: true,
+			Message: "Employee import validated",
+			Data:    result,
+		})
+	}
+
+	logrus.WithField("imported", result.Imported).Info("Employees imported successfully")
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Employees imported successfully",
+		Data:    result,
+	})
+}
+
 func (h *EmployeeHandler) GetEmployeeByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid employee ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
 	}
 
-	employee, err := h.service.GetEmployeeByID(uint(id))
+	var employee *dtos.EmployeeResponse
+	if apiKey, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+		// Services using an API key have no employee of their own
+		employee, err = h.service.GetEmployeeByIDForAPIKey(uint(id), apiKey)
+	} else {
+		// Get user info from JWT middleware
+		userID := c.Locals("user_id").(uint)
+		var userRole string
+		if role := c.Locals("role"); role != nil {
+			if roleStr, ok := role.(string); ok {
+				userRole = roleStr
+			}
+		}
+
+		employee, err = h.service.GetEmployeeByID(uint(id), userID, userRole)
+	}
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get employee")
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "employee not found" {
-			statusCode = fiber.StatusNotFound
-		}
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Get Failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
@@ -83,23 +146,31 @@ func (h *EmployeeHandler) GetEmployeeByID(c *fiber.Ctx) error {
 func (h *EmployeeHandler) GetEmployees(c *fiber.Ctx) error {
 	var req dtos.GetEmployeesRequest
 
-	// Parse query parameters
-	if err := c.QueryParser(&req); err != nil {
-		logrus.WithError(err).Error("Failed to parse query parameters")
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid query parameters",
-			Details: err.Error(),
-		})
+	// Parse and validate query parameters
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
 	}
 
-	employees, err := h.service.GetEmployees(&req)
+	var employees *dtos.GetEmployeesResponse
+	var err error
+	if apiKey, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+		// Services using an API key have no employee of their own
+		employees, err = h.service.GetEmployeesForAPIKey(apiKey, &req)
+	} else {
+		// Get user info from JWT middleware
+		userID := c.Locals("user_id").(uint)
+		var userRole string
+		if role := c.Locals("role"); role != nil {
+			if roleStr, ok := role.(string); ok {
+				userRole = roleStr
+			}
+		}
+
+		employees, err = h.service.GetEmployees(userID, userRole, &req)
+	}
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get employees")
-		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponse{
-			Error:   "Get Failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponsePaginated{
@@ -109,3 +180,199 @@ func (h *EmployeeHandler) GetEmployees(c *fiber.Ctx) error {
 		Pagination: employees.Pagination,
 	})
 }
+
+func (h *EmployeeHandler) GetRoleChanges(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	changes, err := h.service.GetRoleChanges(uint(id), userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get role history")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Role history retrieved successfully",
+		Data:    changes,
+	})
+}
+
+func (h *EmployeeHandler) AssignManager(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	var req dtos.AssignManagerRequest
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	employee, err := h.service.AssignManager(uint(id), userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to assign manager")
+		return err
+	}
+
+	logrus.WithField("employee_id", id).Info("Manager assigned successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Manager assigned successfully",
+		Data:    employee,
+	})
+}
+
+func (h *EmployeeHandler) UpdateEmployee(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	var req dtos.UpdateEmployeeRequest
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID and role from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	employee, err := h.service.UpdateEmployee(uint(id), userID, userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update employee")
+		return err
+	}
+
+	logrus.WithField("employee_id", id).Info("Employee updated successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Employee updated successfully",
+		Data:    employee,
+	})
+}
+
+func (h *EmployeeHandler) DeactivateEmployee(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	// Get user ID and role from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	employee, err := h.service.DeactivateEmployee(uint(id), userID, userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to deactivate employee")
+		return err
+	}
+
+	logrus.WithField("employee_id", id).Info("Employee deactivated successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Employee deactivated successfully",
+		Data:    employee,
+	})
+}
+
+func (h *EmployeeHandler) RestoreEmployee(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	employee, err := h.service.RestoreEmployee(uint(id), userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to restore employee")
+		return err
+	}
+
+	logrus.WithField("employee_id", id).Info("Employee restored successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Employee restored successfully",
+		Data:    employee,
+	})
+}
+
+func (h *EmployeeHandler) GetProfile(c *fiber.Ctx) error {
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	profile, err := h.service.GetProfile(userID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get profile")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Profile retrieved successfully",
+		Data:    profile,
+	})
+}
+
+func (h *EmployeeHandler) UpdateProfile(c *fiber.Ctx) error {
+	var req dtos.UpdateProfileRequest
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	profile, err := h.service.UpdateProfile(userID, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update profile")
+		return err
+	}
+
+	logrus.WithField("employee_id", userID).Info("Profile updated successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Profile updated successfully",
+		Data:    profile,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Errors for requests that cannot be parsed. The parser error is kept as the
+// cause for logging only.
+var (
+	errInvalidRequestBody     = apperrors.Validation("invalid_request_body", "Invalid request body")
+	errInvalidQueryParameters = apperrors.Validation("invalid_query_parameters", "Invalid query parameters")
+)
+
+var kindStatusCodes = map[apperrors.Kind]int{
+	apperrors.KindValidation:   fiber.StatusBadRequest,
+	apperrors.KindUnauthorized: fiber.StatusUnauthorized,
+	apperrors.KindForbidden:    fiber.StatusForbidden,
+	apperrors.KindNotFound:     fiber.StatusNotFound,
+	apperrors.KindConflict:     fiber.StatusConflict,
+	apperrors.KindRateLimited:  fiber.StatusTooManyRequests,
+}
+
+// ErrorHandler renders every error returned by a handler or middleware as a
+// dtos.ErrorResponse. Domain errors keep their code, message and field
+// details, plus a Retry-After header when they ask the client to wait; Fiber
+// errors get a code derived from their status; anything else is an internal
+// error whose message is not exposed.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	if appErr, ok := apperrors.As(err); ok {
+		statusCode, known := kindStatusCodes[appErr.Kind]
+		if !known {
+			statusCode = fiber.StatusInternalServerError
+		}
+		response := dtos.ErrorResponse{
+			Error:   appErr.Code,
+			Message: appErr.Message,
+		}
+		if len(appErr.Fields) > 0 {
+			response.Details = appErr.Fields
+		}
+		if appErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		}
+		return c.Status(statusCode).JSON(response)
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(dtos.ErrorResponse{
+			Error:   strings.ReplaceAll(strings.ToLower(utils.StatusMessage(fiberErr.Code)), " ", "_"),
+			Message: fiberErr.Message,
+		})
+	}
+
+	logrus.WithError(err).WithField("path", c.Path()).Error("Unhandled error")
+	return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponse{
+		Error:   "internal_error",
+		Message: "internal server error",
+	})
+}
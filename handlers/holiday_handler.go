@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bytes"
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+	"io"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type HolidayHandler struct {
+	service   services.HolidayService
+	validator *validator.Validate
+}
+
+func NewHolidayHandler(service services.HolidayService, validator *validator.Validate) *HolidayHandler {
+	return &HolidayHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *HolidayHandler) CreateHoliday(c *fiber.Ctx) error {
+	var req dtos.CreateHolidayRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	holiday, err := h.service.CreateHoliday(userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create holiday")
+		return err
+	}
+
+	logrus.WithField("holiday_id", holiday.ID).Info("Holiday created successfully")
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holiday created successfully",
+		Data:    holiday,
+	})
+}
+
+func (h *HolidayHandler) GetHolidayByID(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid holiday ID")
+	}
+
+	holiday, err := h.service.GetHolidayByID(uint(id))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get holiday")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holiday retrieved successfully",
+		Data:    holiday,
+	})
+}
+
+func (h *HolidayHandler) GetHolidays(c *fiber.Ctx) error {
+	var req dtos.GetHolidaysRequest
+
+	// Parse and validate query parameters
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	holidays, err := h.service.GetHolidays(&req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get holidays")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holidays retrieved successfully",
+		Data:    holidays,
+	})
+}
+
+func (h *HolidayHandler) UpdateHoliday(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid holiday ID")
+	}
+
+	var req dtos.UpdateHolidayRequest
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	holiday, err := h.service.UpdateHoliday(uint(id), userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update holiday")
+		return err
+	}
+
+	logrus.WithField("holiday_id", id).Info("Holiday updated successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holiday updated successfully",
+		Data:    holiday,
+	})
+}
+
+func (h *HolidayHandler) DeleteHoliday(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid holiday ID")
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	err = h.service.DeleteHoliday(uint(id), userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to delete holiday")
+		return err
+	}
+
+	logrus.WithField("holiday_id", id).Info("Holiday deleted successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holiday deleted successfully",
+	})
+}
+
+// ImportHolidays accepts an iCalendar file either as the "file" field of a
+// multipart form or as the raw request body.
+func (h *HolidayHandler) ImportHolidays(c *fiber.Ctx) error {
+	var calendar io.Reader = bytes.NewReader(c.Body())
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to open uploaded calendar file")
+			return services.ErrInvalidCalendarFile.Wrap(err)
+		}
+		defer file.Close()
+		calendar = file
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	result, err := h.service.ImportHolidays(userRole, calendar)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to import holidays")
+		return err
+	}
+
+	logrus.WithField("imported", result.Imported).Info("Holidays imported successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Holidays imported successfully",
+		Data:    result,
+	})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type InvitationHandler struct {
+	service   services.InvitationService
+	validator *validator.Validate
+}
+
+func NewInvitationHandler(service services.InvitationService, validator *validator.Validate) *InvitationHandler {
+	return &InvitationHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *InvitationHandler) CreateInvitation(c *fiber.Ctx) error {
+	var req dtos.CreateInvitationRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID and role from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	invitation, err := h.service.CreateInvitation(userID, userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create invitation")
+		return err
+	}
+
+	logrus.WithField("invitation_id", invitation.ID).Info("Invitation created successfully")
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Invitation created successfully",
+		Data:    invitation,
+	})
+}
+
+func (h *InvitationHandler) RevokeInvitation(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid invitation ID")
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	err = h.service.RevokeInvitation(uint(id), userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to revoke invitation")
+		return err
+	}
+
+	logrus.WithField("invitation_id", id).Info("Invitation revoked successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Invitation revoked successfully",
+	})
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"hr-leave-request/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	keys services.JWTKeys
+}
+
+func NewJWKSHandler(keys services.JWTKeys) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS publishes the public keys our tokens are signed with, so that other
+// services can verify them without sharing a secret.
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(h.keys.JWKS())
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LeaveBalanceHandler struct {
+	service   services.LeaveBalanceService
+	validator *validator.Validate
+}
+
+func NewLeaveBalanceHandler(service services.LeaveBalanceService, validator *validator.Validate) *LeaveBalanceHandler {
+	return &LeaveBalanceHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+func (h *LeaveBalanceHandler) GetEmployeeBalances(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	var req dtos.GetLeaveBalancesRequest
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	var balances []dtos.LeaveBalanceResponse
+	if apiKey, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+		// Services using an API key have no employee of their own
+		balances, err = h.service.GetEmployeeBalancesForAPIKey(uint(id), apiKey, req.Year)
+	} else {
+		// Get user info from JWT middleware
+		userID := c.Locals("user_id").(uint)
+		var userRole string
+		if role := c.Locals("role"); role != nil {
+			if roleStr, ok := role.(string); ok {
+				userRole = roleStr
+			}
+		}
+
+		balances, err = h.service.GetEmployeeBalances(uint(id), userID, userRole, req.Year)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get leave balances")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave balances retrieved successfully",
+		Data:    balances,
+	})
+}
+
+func (h *LeaveBalanceHandler) GetOwnBalances(c *fiber.Ctx) error {
+	var req dtos.GetLeaveBalancesRequest
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	balances, err := h.service.GetBalances(userID, req.Year)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get leave balances")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave balances retrieved successfully",
+		Data:    balances,
+	})
+}
+
+func (h *LeaveBalanceHandler) SetEmployeeEntitlement(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid employee ID")
+	}
+
+	var req dtos.SetLeaveEntitlementRequest
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user role from JWT middleware
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	balance, err := h.service.SetEntitlement(uint(id), userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to set leave entitlement")
+		return err
+	}
+
+	logrus.WithField("employee_id", id).Info("Leave entitlement set successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave entitlement set successfully",
+		Data:    balance,
+	})
+}
@@ -1,32 +1,33 @@
 package handlers
 
 import (
+	"hr-leave-request/apperrors"
 	"hr-leave-request/dtos"
 	"hr-leave-request/services"
 	"strconv"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
 type LeaveRequestHandler struct {
-	service services.LeaveRequestService
+	service   services.LeaveRequestService
+	validator *validator.Validate
 }
 
-func NewLeaveRequestHandler(service services.LeaveRequestService) *LeaveRequestHandler {
-	return &LeaveRequestHandler{service: service}
+func NewLeaveRequestHandler(service services.LeaveRequestService, validator *validator.Validate) *LeaveRequestHandler {
+	return &LeaveRequestHandler{
+		service:   service,
+		validator: validator,
+	}
 }
 
 func (h *LeaveRequestHandler) CreateLeaveRequest(c *fiber.Ctx) error {
 	var req dtos.CreateLeaveRequestRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		logrus.WithError(err).Error("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
 	}
 
 	// Get user ID from JWT middleware
@@ -35,20 +36,7 @@ func (h *LeaveRequestHandler) CreateLeaveRequest(c *fiber.Ctx) error {
 	leaveRequest, err := h.service.CreateLeaveRequest(userID, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to create leave request")
-		statusCode := fiber.StatusInternalServerError
-		message := err.Error()
-
-		switch message {
-		case "employee not found":
-			statusCode = fiber.StatusNotFound
-		case "start date cannot be after end date", "cannot create leave request for past dates", "overlapping approved leave request exists for this date range":
-			statusCode = fiber.StatusBadRequest
-		}
-
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Create Failed",
-			Message: message,
-		})
+		return err
 	}
 
 	logrus.WithField("leave_request_id", leaveRequest.ID).Info("Leave request created successfully")
@@ -63,23 +51,28 @@ func (h *LeaveRequestHandler) GetLeaveRequestByID(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid leave request ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
 	}
 
-	leaveRequest, err := h.service.GetLeaveRequestByID(uint(id))
+	var leaveRequest *dtos.LeaveRequestResponse
+	if apiKey, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+		// Services using an API key have no employee of their own
+		leaveRequest, err = h.service.GetLeaveRequestByIDForAPIKey(uint(id), apiKey)
+	} else {
+		// Get user info from JWT middleware
+		userID := c.Locals("user_id").(uint)
+		var userRole string
+		if role := c.Locals("role"); role != nil {
+			if roleStr, ok := role.(string); ok {
+				userRole = roleStr
+			}
+		}
+
+		leaveRequest, err = h.service.GetLeaveRequestByID(uint(id), userID, userRole)
+	}
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get leave request")
-		statusCode := fiber.StatusInternalServerError
-		if err.Error() == "leave request not found" {
-			statusCode = fiber.StatusNotFound
-		}
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Get Failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
@@ -89,26 +82,148 @@ func (h *LeaveRequestHandler) GetLeaveRequestByID(c *fiber.Ctx) error {
 	})
 }
 
+func (h *LeaveRequestHandler) GetPendingApprovals(c *fiber.Ctx) error {
+	var req dtos.GetPendingApprovalsRequest
+
+	// Parse and validate query parameters
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	result, err := h.service.GetPendingApprovals(userID, userRole, &req)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get pending approvals")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponsePaginated{
+		Success:    true,
+		Message:    "Pending approvals retrieved successfully",
+		Data:       result.Data,
+		Pagination: result.Pagination,
+	})
+}
+
+func (h *LeaveRequestHandler) GetLeaveRequestHistory(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	history, err := h.service.GetLeaveRequestHistory(uint(id), userID, userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get leave request history")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave request history retrieved successfully",
+		Data:    history,
+	})
+}
+
+func (h *LeaveRequestHandler) GetLeaveRequestApprovals(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	approvals, err := h.service.GetLeaveRequestApprovals(uint(id), userID, userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get leave request approvals")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave request approvals retrieved successfully",
+		Data:    approvals,
+	})
+}
+
 func (h *LeaveRequestHandler) GetLeaveRequests(c *fiber.Ctx) error {
 	var req dtos.GetLeaveRequestsRequest
 
-	// Parse query parameters
-	if err := c.QueryParser(&req); err != nil {
-		logrus.WithError(err).Error("Failed to parse query parameters")
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid query parameters",
-			Details: err.Error(),
-		})
+	// Parse and validate query parameters
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	var leaveRequests *dtos.GetLeaveRequestsResponse
+	var err error
+	if apiKey, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+		// Services using an API key have no employee of their own
+		leaveRequests, err = h.service.GetLeaveRequestsForAPIKey(apiKey, &req)
+	} else {
+		// Get user info from JWT middleware
+		userID := c.Locals("user_id").(uint)
+		var userRole string
+		if role := c.Locals("role"); role != nil {
+			if roleStr, ok := role.(string); ok {
+				userRole = roleStr
+			}
+		}
+
+		leaveRequests, err = h.service.GetLeaveRequests(userID, userRole, &req)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get leave requests")
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponsePaginated{
+		Success:    true,
+		Message:    "Leave requests retrieved successfully",
+		Data:       leaveRequests.Data,
+		Pagination: leaveRequests.Pagination,
+	})
+}
+
+func (h *LeaveRequestHandler) GetOwnLeaveRequests(c *fiber.Ctx) error {
+	var req dtos.GetLeaveRequestsRequest
+
+	// Parse and validate query parameters
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
 	}
 
-	leaveRequests, err := h.service.GetLeaveRequests(&req)
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	leaveRequests, err := h.service.GetOwnLeaveRequests(userID, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get leave requests")
-		return c.Status(fiber.StatusInternalServerError).JSON(dtos.ErrorResponse{
-			Error:   "Get Failed",
-			Message: err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponsePaginated{
@@ -123,20 +238,12 @@ func (h *LeaveRequestHandler) UpdateLeaveRequest(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid leave request ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
 	}
 
 	var req dtos.UpdateLeaveRequestRequest
-	if err := c.BodyParser(&req); err != nil {
-		logrus.WithError(err).Error("Failed to parse request body")
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid request body",
-			Details: err.Error(),
-		})
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
 	}
 
 	// Get user info from JWT middleware
@@ -151,22 +258,7 @@ func (h *LeaveRequestHandler) UpdateLeaveRequest(c *fiber.Ctx) error {
 	leaveRequest, err := h.service.UpdateLeaveRequest(uint(id), userID, userRole, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to update leave request")
-		statusCode := fiber.StatusInternalServerError
-		message := err.Error()
-
-		switch message {
-		case "leave request not found":
-			statusCode = fiber.StatusNotFound
-		case "unauthorized to update this leave request", "only HR or manager can update leave request status":
-			statusCode = fiber.StatusForbidden
-		case "start date cannot be after end date", "cannot update leave request to past dates", "overlapping approved leave request exists for this date range":
-			statusCode = fiber.StatusBadRequest
-		}
-
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Update Failed",
-			Message: message,
-		})
+		return err
 	}
 
 	logrus.WithField("leave_request_id", id).Info("Leave request updated successfully")
@@ -181,10 +273,7 @@ func (h *LeaveRequestHandler) DeleteLeaveRequest(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid leave request ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
 	}
 
 	// Get user info from JWT middleware
@@ -199,20 +288,7 @@ func (h *LeaveRequestHandler) DeleteLeaveRequest(c *fiber.Ctx) error {
 	err = h.service.DeleteLeaveRequest(uint(id), userID, userRole)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to delete leave request")
-		statusCode := fiber.StatusInternalServerError
-		message := err.Error()
-
-		switch message {
-		case "leave request not found":
-			statusCode = fiber.StatusNotFound
-		case "unauthorized to delete this leave request":
-			statusCode = fiber.StatusForbidden
-		}
-
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Delete Failed",
-			Message: message,
-		})
+		return err
 	}
 
 	logrus.WithField("leave_request_id", id).Info("Leave request deleted successfully")
@@ -226,13 +302,19 @@ func (h *LeaveRequestHandler) ApproveLeaveRequest(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid leave request ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// The decision comment is optional when approving, so is the body
+	var req dtos.DecideLeaveRequestRequest
+	if len(c.Body()) > 0 {
+		if err := bindBody(c, h.validator, &req); err != nil {
+			return err
+		}
 	}
 
-	// Get user role from JWT middleware
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
 	var userRole string
 	if role := c.Locals("role"); role != nil {
 		if roleStr, ok := role.(string); ok {
@@ -240,25 +322,10 @@ func (h *LeaveRequestHandler) ApproveLeaveRequest(c *fiber.Ctx) error {
 		}
 	}
 
-	leaveRequest, err := h.service.ApproveLeaveRequest(uint(id), userRole)
+	leaveRequest, err := h.service.ApproveLeaveRequest(uint(id), userID, userRole, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to approve leave request")
-		statusCode := fiber.StatusInternalServerError
-		message := err.Error()
-
-		switch message {
-		case "leave request not found":
-			statusCode = fiber.StatusNotFound
-		case "only HR can approve leave requests":
-			statusCode = fiber.StatusForbidden
-		case "overlapping approved leave request exists for this date range":
-			statusCode = fiber.StatusBadRequest
-		}
-
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Approve Failed",
-			Message: message,
-		})
+		return err
 	}
 
 	logrus.WithField("leave_request_id", id).Info("Leave request approved successfully")
@@ -273,13 +340,18 @@ func (h *LeaveRequestHandler) RejectLeaveRequest(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dtos.ErrorResponse{
-			Error:   "Bad Request",
-			Message: "Invalid leave request ID",
-		})
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	var req dtos.DecideLeaveRequestRequest
+	if len(c.Body()) > 0 {
+		if err := bindBody(c, h.validator, &req); err != nil {
+			return err
+		}
 	}
 
-	// Get user role from JWT middleware
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
 	var userRole string
 	if role := c.Locals("role"); role != nil {
 		if roleStr, ok := role.(string); ok {
@@ -287,23 +359,10 @@ func (h *LeaveRequestHandler) RejectLeaveRequest(c *fiber.Ctx) error {
 		}
 	}
 
-	leaveRequest, err := h.service.RejectLeaveRequest(uint(id), userRole)
+	leaveRequest, err := h.service.RejectLeaveRequest(uint(id), userID, userRole, &req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to reject leave request")
-		statusCode := fiber.StatusInternalServerError
-		message := err.Error()
-
-		switch message {
-		case "leave request not found":
-			statusCode = fiber.StatusNotFound
-		case "only HR can reject leave requests":
-			statusCode = fiber.StatusForbidden
-		}
-
-		return c.Status(statusCode).JSON(dtos.ErrorResponse{
-			Error:   "Reject Failed",
-			Message: message,
-		})
+		return err
 	}
 
 	logrus.WithField("leave_request_id", id).Info("Leave request rejected successfully")
@@ -313,3 +372,81 @@ func (h *LeaveRequestHandler) RejectLeaveRequest(c *fiber.Ctx) error {
 		Data:    leaveRequest,
 	})
 }
+
+func (h *LeaveRequestHandler) SubmitLeaveRequest(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	leaveRequest, err := h.service.SubmitLeaveRequest(uint(id), userID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to submit leave request")
+		return err
+	}
+
+	logrus.WithField("leave_request_id", id).Info("Leave request submitted successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave request submitted successfully",
+		Data:    leaveRequest,
+	})
+}
+
+func (h *LeaveRequestHandler) CancelLeaveRequest(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// Get user info from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	leaveRequest, err := h.service.CancelLeaveRequest(uint(id), userID, userRole)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to cancel leave request")
+		return err
+	}
+
+	logrus.WithField("leave_request_id", id).Info("Leave request cancelled successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave request cancelled successfully",
+		Data:    leaveRequest,
+	})
+}
+
+func (h *LeaveRequestHandler) WithdrawLeaveRequest(c *fiber.Ctx) error {
+	idParam := c.Params("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		return apperrors.Validation("invalid_id", "Invalid leave request ID")
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	leaveRequest, err := h.service.WithdrawLeaveRequest(uint(id), userID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to withdraw leave request")
+		return err
+	}
+
+	logrus.WithField("leave_request_id", id).Info("Leave request withdrawn successfully")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Leave request withdrawn successfully",
+		Data:    leaveRequest,
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type MFAHandler struct {
+	service   services.MFAService
+	validator *validator.Validate
+}
+
+func NewMFAHandler(service services.MFAService, validator *validator.Validate) *MFAHandler {
+	return &MFAHandler{
+		service:   service,
+		validator: validator,
+	}
+}
+
+// Enroll starts the TOTP setup of the caller and returns the secret to add to
+// an authenticator app.
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	enrollment, err := h.service.Enroll(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Add the secret to your authenticator app and confirm with a code",
+		Data:    enrollment,
+	})
+}
+
+// Confirm enables two-factor authentication with a first TOTP code and
+// returns the recovery codes.
+func (h *MFAHandler) Confirm(c *fiber.Ctx) error {
+	var req dtos.MFACodeRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	recoveryCodes, err := h.service.Confirm(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithField("employee_id", userID).Info("Two-factor authentication enabled")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication enabled, store the recovery codes safely",
+		Data:    recoveryCodes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes.
+func (h *MFAHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	var req dtos.MFACodeRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID from JWT middleware
+	userID := c.Locals("user_id").(uint)
+
+	recoveryCodes, err := h.service.RegenerateRecoveryCodes(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Recovery codes regenerated, the previous ones no longer work",
+		Data:    recoveryCodes,
+	})
+}
+
+// Disable turns off two-factor authentication for the caller.
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	var req dtos.MFACodeRequest
+
+	if err := bindBody(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	// Get user ID and role from JWT middleware
+	userID := c.Locals("user_id").(uint)
+	var userRole string
+	if role := c.Locals("role"); role != nil {
+		if roleStr, ok := role.(string); ok {
+			userRole = roleStr
+		}
+	}
+
+	if err := h.service.Disable(userID, userRole, &req); err != nil {
+		return err
+	}
+
+	logrus.WithField("employee_id", userID).Info("Two-factor authentication disabled")
+	return c.Status(fiber.StatusOK).JSON(dtos.SuccessResponse{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	})
+}
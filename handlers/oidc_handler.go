@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"hr-leave-request/dtos"
+	"hr-leave-request/services"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/v1/auth/oidc"
+)
+
+type OIDCHandler struct {
+	oidcService services.OIDCService
+	validator   *validator.Validate
+}
+
+func NewOIDCHandler(oidcService services.OIDCService, validator *validator.Validate) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		validator:   validator,
+	}
+}
+
+// Login sends the browser to the identity provider, keeping the state of the
+// attempt in a short-lived cookie for the callback.
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	authorization, err := h.oidcService.AuthorizationURL()
+	if err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    authorization.StateToken,
+		Path:     oidcStateCookiePath,
+		Expires:  authorization.ExpiresAt,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		// Lax, as the provider redirects back with a top-level navigation
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(authorization.URL, fiber.StatusFound)
+}
+
+// Callback completes the login when the identity provider redirects back.
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var req dtos.OIDCCallbackRequest
+
+	if err := bindQuery(c, h.validator, &req); err != nil {
+		return err
+	}
+
+	stateToken := c.Cookies(oidcStateCookie)
+	// The state is single-use
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Path:     oidcStateCookiePath,
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+	})
+
+	response, err := h.oidcService.Callback(&req, stateToken)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"hr-leave-request/config"
 	"hr-leave-request/middleware"
+	"hr-leave-request/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -10,14 +10,14 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
-func SetupRoutes(app *fiber.App, employeeHandler *EmployeeHandler, authHandler *AuthHandler, leaveRequestHandler *LeaveRequestHandler, cfg *config.ApplicationConfig) {
+func SetupRoutes(app *fiber.App, employeeHandler *EmployeeHandler, authHandler *AuthHandler, leaveRequestHandler *LeaveRequestHandler, leaveBalanceHandler *LeaveBalanceHandler, holidayHandler *HolidayHandler, invitationHandler *InvitationHandler, apiKeyHandler *APIKeyHandler, mfaHandler *MFAHandler, oidcHandler *OIDCHandler, jwksHandler *JWKSHandler, permissionChecker services.PermissionChecker, tokenService services.TokenService, apiKeyService services.APIKeyService, mfaService services.MFAService) {
 	// Middleware
 	app.Use(recover.New())
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, " + middleware.APIKeyHeader,
 	}))
 
 	// Health check
@@ -28,6 +28,9 @@ func SetupRoutes(app *fiber.App, employeeHandler *EmployeeHandler, authHandler *
 		})
 	})
 
+	// Public verification keys of our tokens
+	app.Get("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// API v1 routes
 	api := app.Group("/api")
 	v1 := api.Group("/v1")
@@ -36,30 +39,112 @@ func SetupRoutes(app *fiber.App, employeeHandler *EmployeeHandler, authHandler *
 	auth := v1.Group("/auth")
 	{
 		auth.Post("/login", authHandler.Login)
+		auth.Post("/login/mfa", authHandler.LoginMFA)
 		auth.Post("/register", authHandler.Register)
+		auth.Post("/invitations/accept", authHandler.AcceptInvitation)
+		auth.Post("/refresh", authHandler.Refresh)
+		auth.Post("/logout", middleware.JWTMiddleware(tokenService), authHandler.Logout)
+		auth.Post("/password/forgot", authHandler.ForgotPassword)
+		auth.Post("/password/reset", authHandler.ResetPassword)
+		auth.Put("/password", middleware.JWTMiddleware(tokenService), authHandler.ChangePassword)
+		auth.Get("/oidc/login", oidcHandler.Login)
+		auth.Get("/oidc/callback", oidcHandler.Callback)
+	}
+
+	// Two-factor authentication routes, reachable before enrolling
+	mfa := auth.Group("/mfa")
+	mfa.Use(middleware.JWTMiddleware(tokenService))
+	{
+		mfa.Post("/enroll", mfaHandler.Enroll)
+		mfa.Post("/confirm", mfaHandler.Confirm)
+		mfa.Post("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		mfa.Delete("/", mfaHandler.Disable)
+	}
+
+	// Read routes open to service API keys as well as employees. They come
+	// before the protected group, whose middleware only accepts access
+	// tokens, and a key needs the permission named on the route.
+	apiKeyOrJWT := middleware.JWTOrAPIKeyMiddleware(tokenService, apiKeyService)
+	requireMFAEnrollment := middleware.RequireMFAEnrollment(mfaService)
+	readEmployees := middleware.RequireAPIKeyPermission(services.PermissionEmployeeRead)
+	readAllLeave := middleware.RequireAPIKeyPermission(services.PermissionLeaveReadAll)
+	{
+		// Employees only, but it must come before /leave-requests/:id
+		v1.Get("/leave-requests/pending-approval", middleware.JWTMiddleware(tokenService), requireMFAEnrollment, leaveRequestHandler.GetPendingApprovals)
+		v1.Get("/employees", apiKeyOrJWT, requireMFAEnrollment, readEmployees, employeeHandler.GetEmployees)
+		v1.Get("/employees/:id", apiKeyOrJWT, requireMFAEnrollment, readEmployees, employeeHandler.GetEmployeeByID)
+		v1.Get("/employees/:id/balances", apiKeyOrJWT, requireMFAEnrollment, readAllLeave, leaveBalanceHandler.GetEmployeeBalances)
+		v1.Get("/leave-requests", apiKeyOrJWT, requireMFAEnrollment, readAllLeave, leaveRequestHandler.GetLeaveRequests)
+		v1.Get("/leave-requests/:id", apiKeyOrJWT, requireMFAEnrollment, readAllLeave, leaveRequestHandler.GetLeaveRequestByID)
+		v1.Get("/holidays", apiKeyOrJWT, requireMFAEnrollment, holidayHandler.GetHolidays)
+		v1.Get("/holidays/:id", apiKeyOrJWT, requireMFAEnrollment, holidayHandler.GetHolidayByID)
 	}
 
 	// Protected routes
 	protected := v1.Group("")
-	protected.Use(middleware.JWTMiddleware(cfg))
+	protected.Use(middleware.JWTMiddleware(tokenService))
+	protected.Use(requireMFAEnrollment)
+
+	// Self-service routes for the signed-in employee (protected)
+	me := protected.Group("/me")
+	{
+		me.Get("/", employeeHandler.GetProfile)
+		me.Patch("/", employeeHandler.UpdateProfile)
+		me.Get("/leave-requests", leaveRequestHandler.GetOwnLeaveRequests)
+		me.Get("/balances", leaveBalanceHandler.GetOwnBalances)
+	}
 
 	// Employee routes (protected)
 	employees := protected.Group("/employees")
 	{
-		employees.Post("/", employeeHandler.CreateEmployee)
-		employees.Get("/", employeeHandler.GetEmployees)
-		employees.Get("/:id", employeeHandler.GetEmployeeByID)
+		employees.Post("/", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeCreate), employeeHandler.CreateEmployee)
+		employees.Post("/import", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeCreate), employeeHandler.ImportEmployees)
+		employees.Put("/:id/manager", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeUpdate), employeeHandler.AssignManager)
+		employees.Get("/:id/role-changes", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeRead), employeeHandler.GetRoleChanges)
+		employees.Put("/:id", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeUpdate), employeeHandler.UpdateEmployee)
+		employees.Patch("/:id/deactivate", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeUpdate), employeeHandler.DeactivateEmployee)
+		employees.Patch("/:id/restore", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeUpdate), employeeHandler.RestoreEmployee)
+		employees.Put("/:id/balances", middleware.RequirePermission(permissionChecker, services.PermissionLeaveBalanceManage), leaveBalanceHandler.SetEmployeeEntitlement)
+	}
+
+	// Invitation routes (protected)
+	invitations := protected.Group("/invitations")
+	{
+		invitations.Post("/", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeCreate), invitationHandler.CreateInvitation)
+		invitations.Delete("/:id", middleware.RequirePermission(permissionChecker, services.PermissionEmployeeCreate), invitationHandler.RevokeInvitation)
+	}
+
+	// API key routes (protected)
+	apiKeys := protected.Group("/api-keys")
+	manageAPIKeys := middleware.RequirePermission(permissionChecker, services.PermissionAPIKeyManage)
+	{
+		apiKeys.Post("/", manageAPIKeys, apiKeyHandler.CreateAPIKey)
+		apiKeys.Get("/", manageAPIKeys, apiKeyHandler.GetAPIKeys)
+		apiKeys.Delete("/:id", manageAPIKeys, apiKeyHandler.RevokeAPIKey)
 	}
 
 	// Leave request routes (protected)
 	leaveRequests := protected.Group("/leave-requests")
 	{
 		leaveRequests.Post("/", leaveRequestHandler.CreateLeaveRequest)
-		leaveRequests.Get("/", leaveRequestHandler.GetLeaveRequests)
-		leaveRequests.Get("/:id", leaveRequestHandler.GetLeaveRequestByID)
+		leaveRequests.Get("/:id/history", leaveRequestHandler.GetLeaveRequestHistory)
+		leaveRequests.Get("/:id/approvals", leaveRequestHandler.GetLeaveRequestApprovals)
 		leaveRequests.Put("/:id", leaveRequestHandler.UpdateLeaveRequest)
 		leaveRequests.Delete("/:id", leaveRequestHandler.DeleteLeaveRequest)
 		leaveRequests.Patch("/:id/approve", leaveRequestHandler.ApproveLeaveRequest)
 		leaveRequests.Patch("/:id/reject", leaveRequestHandler.RejectLeaveRequest)
+		leaveRequests.Patch("/:id/submit", leaveRequestHandler.SubmitLeaveRequest)
+		leaveRequests.Patch("/:id/cancel", leaveRequestHandler.CancelLeaveRequest)
+		leaveRequests.Patch("/:id/withdraw", leaveRequestHandler.WithdrawLeaveRequest)
+	}
+
+	// Holiday routes (protected)
+	holidays := protected.Group("/holidays")
+	manageHolidays := middleware.RequirePermission(permissionChecker, services.PermissionHolidayManage)
+	{
+		holidays.Post("/", manageHolidays, holidayHandler.CreateHoliday)
+		holidays.Post("/import", manageHolidays, holidayHandler.ImportHolidays)
+		holidays.Put("/:id", manageHolidays, holidayHandler.UpdateHoliday)
+		holidays.Delete("/:id", manageHolidays, holidayHandler.DeleteHoliday)
 	}
 }
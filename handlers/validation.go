@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"hr-leave-request/apperrors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+var errValidationFailed = apperrors.Validation("validation_error", "Validation failed")
+
+// bindBody parses the request body into req, which must be a pointer to a
+// struct, and validates it against its validate tags.
+func bindBody(c *fiber.Ctx, validate *validator.Validate, req any) error {
+	if err := c.BodyParser(req); err != nil {
+		logrus.WithError(err).Error("Failed to parse request body")
+		return errInvalidRequestBody.Wrap(err)
+	}
+	return validateRequest(validate, req)
+}
+
+// bindQuery parses the query string into req, which must be a pointer to a
+// struct, and validates it against its validate tags.
+func bindQuery(c *fiber.Ctx, validate *validator.Validate, req any) error {
+	if err := c.QueryParser(req); err != nil {
+		logrus.WithError(err).Error("Failed to parse query parameters")
+		return errInvalidQueryParameters.Wrap(err)
+	}
+	return validateRequest(validate, req)
+}
+
+// validateRequest checks req against its validate tags and reports every
+// failing field with a human-readable message, keyed by its JSON or query
+// name.
+func validateRequest(validate *validator.Validate, req any) error {
+	err := validate.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fields := make([]apperrors.FieldError, len(validationErrors))
+	for i, fieldErr := range validationErrors {
+		fields[i] = apperrors.FieldError{
+			Field:   fieldErr.Field(),
+			Message: fieldErrorMessage(req, fieldErr),
+		}
+	}
+	return errValidationFailed.WithFields(fields...)
+}
+
+func fieldErrorMessage(req any, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", param)
+		}
+		return fmt.Sprintf("must be at least %s", param)
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", param)
+		}
+		return fmt.Sprintf("must be at most %s", param)
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "gtefield":
+		return "must not be before " + requestFieldName(req, param)
+	case "datetime":
+		if param == "2006-01-02" {
+			return "must be a date in YYYY-MM-DD format"
+		}
+		return "must match the format " + param
+	case "bcp47_language_tag":
+		return "must be a language tag such as en-GB"
+	case "timezone":
+		return "must be a time zone such as Europe/Berlin"
+	default:
+		return "is invalid"
+	}
+}
+
+// requestFieldName returns the name clients know a struct field of req by.
+func requestFieldName(req any, field string) string {
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if structField, ok := t.FieldByName(field); ok {
+		if name := tagName(structField); name != "" {
+			return name
+		}
+	}
+	return field
+}
+
+// tagName returns the JSON or query name of a struct field, if it has one.
+func tagName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// NewValidator returns a validator that reports fields under their JSON or
+// query name.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(tagName)
+	return validate
+}
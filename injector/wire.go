@@ -9,7 +9,6 @@ import (
 	"hr-leave-request/repositories"
 	"hr-leave-request/services"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/wire"
 )
@@ -18,15 +17,53 @@ func InitializeApp() (*fiber.App, error) {
 	wire.Build(
 		config.LoadConfig,
 		config.NewDatabase,
-		NewValidator,
+		handlers.NewValidator,
 		repositories.NewEmployeeRepository,
 		repositories.NewLeaveRequestRepository,
+		repositories.NewLeaveRequestEventRepository,
+		repositories.NewEmployeeRoleChangeRepository,
+		repositories.NewLeaveRequestApprovalRepository,
+		repositories.NewLeaveBalanceRepository,
+		repositories.NewHolidayRepository,
+		repositories.NewRolePermissionRepository,
+		repositories.NewInvitationRepository,
+		repositories.NewRefreshTokenRepository,
+		repositories.NewRevokedTokenRepository,
+		repositories.NewPasswordResetTokenRepository,
+		repositories.NewLoginLockoutRepository,
+		repositories.NewMFAEnrollmentRepository,
+		repositories.NewMFARecoveryCodeRepository,
+		repositories.NewAPIKeyRepository,
+		repositories.NewTransactor,
+		services.NewPermissionChecker,
+		services.NewJWTKeys,
+		services.NewTokenService,
 		services.NewEmployeeService,
+		services.NewEmployeeImportService,
+		services.NewMemoryLoginAttemptStore,
+		services.NewLoginThrottle,
+		services.NewMFAService,
 		services.NewAuthService,
+		services.NewOIDCService,
+		services.NewLogNotifier,
+		services.NewPasswordService,
+		services.NewLeaveBalanceService,
+		services.NewWorkingCalendar,
+		services.NewApprovalPolicy,
 		services.NewLeaveRequestService,
+		services.NewHolidayService,
+		services.NewInvitationService,
+		services.NewAPIKeyService,
 		handlers.NewEmployeeHandler,
 		handlers.NewAuthHandler,
 		handlers.NewLeaveRequestHandler,
+		handlers.NewLeaveBalanceHandler,
+		handlers.NewHolidayHandler,
+		handlers.NewInvitationHandler,
+		handlers.NewAPIKeyHandler,
+		handlers.NewMFAHandler,
+		handlers.NewOIDCHandler,
+		handlers.NewJWKSHandler,
 		NewFiberApp,
 	)
 	return nil, nil
@@ -36,17 +73,26 @@ func NewFiberApp(
 	employeeHandler *handlers.EmployeeHandler,
 	authHandler *handlers.AuthHandler,
 	leaveRequestHandler *handlers.LeaveRequestHandler,
+	leaveBalanceHandler *handlers.LeaveBalanceHandler,
+	holidayHandler *handlers.HolidayHandler,
+	invitationHandler *handlers.InvitationHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	mfaHandler *handlers.MFAHandler,
+	oidcHandler *handlers.OIDCHandler,
+	jwksHandler *handlers.JWKSHandler,
+	permissionChecker services.PermissionChecker,
+	tokenService services.TokenService,
+	apiKeyService services.APIKeyService,
+	mfaService services.MFAService,
 	cfg *config.ApplicationConfig,
 ) *fiber.App {
 	app := fiber.New(fiber.Config{
-		AppName: "HR Leave Request API",
+		AppName:      "HR Leave Request API",
+		ErrorHandler: handlers.ErrorHandler,
+		ProxyHeader:  cfg.AppConfig.ProxyHeader,
 	})
 
-	handlers.SetupRoutes(app, employeeHandler, authHandler, leaveRequestHandler, cfg)
+	handlers.SetupRoutes(app, employeeHandler, authHandler, leaveRequestHandler, leaveBalanceHandler, holidayHandler, invitationHandler, apiKeyHandler, mfaHandler, oidcHandler, jwksHandler, permissionChecker, tokenService, apiKeyService, mfaService)
 
 	return app
 }
-
-func NewValidator() *validator.Validate {
-	return validator.New()
-}
@@ -7,7 +7,6 @@
 package injector
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"hr-leave-request/config"
 	"hr-leave-request/handlers"
@@ -27,15 +26,62 @@ func InitializeApp() (*fiber.App, error) {
 		return nil, err
 	}
 	employeeRepository := repositories.NewEmployeeRepository(db)
-	employeeService := services.NewEmployeeService(employeeRepository)
-	employeeHandler := handlers.NewEmployeeHandler(employeeService)
-	authService := services.NewAuthService(employeeRepository, applicationConfig)
-	validate := NewValidator()
-	authHandler := handlers.NewAuthHandler(authService, validate)
+	employeeRoleChangeRepository := repositories.NewEmployeeRoleChangeRepository(db)
 	leaveRequestRepository := repositories.NewLeaveRequestRepository(db)
-	leaveRequestService := services.NewLeaveRequestService(leaveRequestRepository, employeeRepository)
-	leaveRequestHandler := handlers.NewLeaveRequestHandler(leaveRequestService)
-	app := NewFiberApp(employeeHandler, authHandler, leaveRequestHandler, applicationConfig)
+	leaveRequestEventRepository := repositories.NewLeaveRequestEventRepository(db)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+	revokedTokenRepository := repositories.NewRevokedTokenRepository(db)
+	jwtKeys, err := services.NewJWTKeys(applicationConfig)
+	if err != nil {
+		return nil, err
+	}
+	transactor := repositories.NewTransactor(db)
+	tokenService := services.NewTokenService(employeeRepository, refreshTokenRepository, revokedTokenRepository, jwtKeys, transactor, applicationConfig)
+	rolePermissionRepository := repositories.NewRolePermissionRepository(db)
+	permissionChecker := services.NewPermissionChecker(rolePermissionRepository)
+	employeeService := services.NewEmployeeService(employeeRepository, employeeRoleChangeRepository, leaveRequestRepository, leaveRequestEventRepository, tokenService, permissionChecker, transactor)
+	passwordResetTokenRepository := repositories.NewPasswordResetTokenRepository(db)
+	notifier := services.NewLogNotifier(applicationConfig)
+	employeeImportService := services.NewEmployeeImportService(employeeRepository, employeeRoleChangeRepository, passwordResetTokenRepository, notifier, permissionChecker, transactor, applicationConfig)
+	validate := handlers.NewValidator()
+	employeeHandler := handlers.NewEmployeeHandler(employeeService, employeeImportService, validate)
+	invitationRepository := repositories.NewInvitationRepository(db)
+	loginAttemptStore := services.NewMemoryLoginAttemptStore()
+	loginLockoutRepository := repositories.NewLoginLockoutRepository(db)
+	loginThrottle := services.NewLoginThrottle(loginAttemptStore, loginLockoutRepository, applicationConfig)
+	mfaEnrollmentRepository := repositories.NewMFAEnrollmentRepository(db)
+	mfaRecoveryCodeRepository := repositories.NewMFARecoveryCodeRepository(db)
+	mfaService := services.NewMFAService(employeeRepository, mfaEnrollmentRepository, mfaRecoveryCodeRepository, permissionChecker, transactor, applicationConfig)
+	authService := services.NewAuthService(employeeRepository, employeeRoleChangeRepository, invitationRepository, tokenService, loginThrottle, mfaService, jwtKeys, transactor, applicationConfig)
+	passwordService := services.NewPasswordService(employeeRepository, passwordResetTokenRepository, tokenService, notifier, transactor, applicationConfig)
+	authHandler := handlers.NewAuthHandler(authService, passwordService, validate)
+	leaveRequestApprovalRepository := repositories.NewLeaveRequestApprovalRepository(db)
+	leaveBalanceRepository := repositories.NewLeaveBalanceRepository(db)
+	leaveBalanceService := services.NewLeaveBalanceService(leaveBalanceRepository, employeeRepository, permissionChecker, applicationConfig)
+	holidayRepository := repositories.NewHolidayRepository(db)
+	workingCalendar, err := services.NewWorkingCalendar(holidayRepository, applicationConfig)
+	if err != nil {
+		return nil, err
+	}
+	approvalPolicy, err := services.NewApprovalPolicy(applicationConfig)
+	if err != nil {
+		return nil, err
+	}
+	leaveRequestService := services.NewLeaveRequestService(leaveRequestRepository, leaveRequestEventRepository, leaveRequestApprovalRepository, employeeRepository, leaveBalanceService, workingCalendar, approvalPolicy, permissionChecker, transactor)
+	leaveRequestHandler := handlers.NewLeaveRequestHandler(leaveRequestService, validate)
+	leaveBalanceHandler := handlers.NewLeaveBalanceHandler(leaveBalanceService, validate)
+	holidayService := services.NewHolidayService(holidayRepository, permissionChecker)
+	holidayHandler := handlers.NewHolidayHandler(holidayService, validate)
+	invitationService := services.NewInvitationService(invitationRepository, employeeRepository, permissionChecker, jwtKeys, applicationConfig)
+	invitationHandler := handlers.NewInvitationHandler(invitationService, validate)
+	apiKeyRepository := repositories.NewAPIKeyRepository(db)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, permissionChecker)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService, validate)
+	mfaHandler := handlers.NewMFAHandler(mfaService, validate)
+	oidcService := services.NewOIDCService(employeeRepository, employeeRoleChangeRepository, tokenService, mfaService, jwtKeys, transactor, applicationConfig)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, validate)
+	jwksHandler := handlers.NewJWKSHandler(jwtKeys)
+	app := NewFiberApp(employeeHandler, authHandler, leaveRequestHandler, leaveBalanceHandler, holidayHandler, invitationHandler, apiKeyHandler, mfaHandler, oidcHandler, jwksHandler, permissionChecker, tokenService, apiKeyService, mfaService, applicationConfig)
 	return app, nil
 }
 
@@ -45,16 +91,25 @@ func NewFiberApp(
 	employeeHandler *handlers.EmployeeHandler,
 	authHandler *handlers.AuthHandler,
 	leaveRequestHandler *handlers.LeaveRequestHandler,
+	leaveBalanceHandler *handlers.LeaveBalanceHandler,
+	holidayHandler *handlers.HolidayHandler,
+	invitationHandler *handlers.InvitationHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	mfaHandler *handlers.MFAHandler,
+	oidcHandler *handlers.OIDCHandler,
+	jwksHandler *handlers.JWKSHandler,
+	permissionChecker services.PermissionChecker,
+	tokenService services.TokenService,
+	apiKeyService services.APIKeyService,
+	mfaService services.MFAService,
 	cfg *config.ApplicationConfig,
 ) *fiber.App {
 	app := fiber.New(fiber.Config{
-		AppName: "HR Leave Request API",
+		AppName:      "HR Leave Request API",
+		ErrorHandler: handlers.ErrorHandler,
+		ProxyHeader:  cfg.AppConfig.ProxyHeader,
 	})
-	handlers.SetupRoutes(app, employeeHandler, authHandler, leaveRequestHandler, cfg)
+	handlers.SetupRoutes(app, employeeHandler, authHandler, leaveRequestHandler, leaveBalanceHandler, holidayHandler, invitationHandler, apiKeyHandler, mfaHandler, oidcHandler, jwksHandler, permissionChecker, tokenService, apiKeyService, mfaService)
 
 	return app
 }
-
-func NewValidator() *validator.Validate {
-	return validator.New()
-}
@@ -0,0 +1,2 @@
+DROP TABLE leave_balance_transactions;
+DROP TABLE leave_balances;
\ No newline at end of file
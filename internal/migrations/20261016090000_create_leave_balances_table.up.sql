@@ -0,0 +1,28 @@
+CREATE TABLE leave_balances (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    type ENUM('sick', 'vacation', 'personal', 'other') NOT NULL,
+    year SMALLINT NOT NULL,
+    entitled_days DECIMAL(6,2) NOT NULL DEFAULT 0,
+    used_days DECIMAL(6,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    UNIQUE INDEX idx_leave_balances_employee_type_year (employee_id, type, year)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE leave_balance_transactions (
+    id INT NOT NULL AUTO_INCREMENT,
+    leave_balance_id INT NOT NULL,
+    leave_request_id INT NULL,
+    kind ENUM('debit', 'credit') NOT NULL,
+    days DECIMAL(6,2) NOT NULL,
+    note VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (leave_balance_id) REFERENCES leave_balances(id),
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id),
+    INDEX idx_leave_balance_id (leave_balance_id),
+    INDEX idx_leave_request_id (leave_request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
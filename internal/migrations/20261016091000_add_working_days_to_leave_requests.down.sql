@@ -0,0 +1,2 @@
+ALTER TABLE leave_requests
+DROP COLUMN working_days;
\ No newline at end of file
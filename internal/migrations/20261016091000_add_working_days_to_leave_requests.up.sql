@@ -0,0 +1,3 @@
+ALTER TABLE leave_requests
+ADD COLUMN working_days DECIMAL(5,2) NOT NULL DEFAULT 0
+AFTER end_date;
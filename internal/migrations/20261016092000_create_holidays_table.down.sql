@@ -0,0 +1 @@
+DROP TABLE holidays;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE holidays (
+    id INT NOT NULL AUTO_INCREMENT,
+    date DATE NOT NULL,
+    name VARCHAR(150) NOT NULL,
+    source ENUM('manual', 'ics') NOT NULL DEFAULT 'manual',
+    uid VARCHAR(255) NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_holidays_date (date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
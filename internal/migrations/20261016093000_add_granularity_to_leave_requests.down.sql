@@ -0,0 +1,2 @@
+ALTER TABLE leave_requests
+DROP COLUMN granularity;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE leave_requests
+ADD COLUMN granularity ENUM('full_day', 'half_day_am', 'half_day_pm', 'hours') NOT NULL DEFAULT 'full_day'
+AFTER end_date;
@@ -0,0 +1,10 @@
+ALTER TABLE leave_requests
+    DROP CHECK chk_leave_requests_status;
+
+UPDATE leave_requests SET status = 'pending' WHERE status = 'draft';
+UPDATE leave_requests SET status = 'approved' WHERE status = 'cancellation_requested';
+UPDATE leave_requests SET status = 'rejected' WHERE status IN ('cancelled', 'withdrawn');
+
+ALTER TABLE leave_requests
+    MODIFY COLUMN status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    ADD CONSTRAINT chk_leave_requests_status CHECK (status IN ('pending', 'approved', 'rejected'));
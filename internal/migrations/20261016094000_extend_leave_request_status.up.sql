@@ -0,0 +1,26 @@
+-- The original CHECK on status was created without a name, so look up the
+-- name MySQL generated for it instead of assuming one
+SET @status_check = (
+    SELECT cc.CONSTRAINT_NAME
+    FROM information_schema.CHECK_CONSTRAINTS cc
+    JOIN information_schema.TABLE_CONSTRAINTS tc
+        ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA
+        AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+    WHERE tc.TABLE_SCHEMA = DATABASE()
+        AND tc.TABLE_NAME = 'leave_requests'
+        AND tc.CONSTRAINT_TYPE = 'CHECK'
+        AND cc.CHECK_CLAUSE LIKE '%`status`%'
+    LIMIT 1
+);
+SET @drop_status_check = IF(
+    @status_check IS NULL,
+    'DO 0',
+    CONCAT('ALTER TABLE leave_requests DROP CHECK `', @status_check, '`')
+);
+PREPARE drop_status_check FROM @drop_status_check;
+EXECUTE drop_status_check;
+DEALLOCATE PREPARE drop_status_check;
+
+ALTER TABLE leave_requests
+    MODIFY COLUMN status ENUM('draft', 'pending', 'approved', 'rejected', 'cancelled', 'withdrawn', 'cancellation_requested') NOT NULL DEFAULT 'pending',
+    ADD CONSTRAINT chk_leave_requests_status CHECK (status IN ('draft', 'pending', 'approved', 'rejected', 'cancelled', 'withdrawn', 'cancellation_requested'));
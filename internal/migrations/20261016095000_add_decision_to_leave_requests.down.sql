@@ -0,0 +1,6 @@
+ALTER TABLE leave_requests
+    DROP FOREIGN KEY fk_leave_requests_decided_by,
+    DROP INDEX idx_leave_requests_decided_by,
+    DROP COLUMN decided_at,
+    DROP COLUMN decided_by,
+    DROP COLUMN decision_comment;
@@ -0,0 +1,6 @@
+ALTER TABLE leave_requests
+    ADD COLUMN decision_comment TEXT NULL AFTER reason,
+    ADD COLUMN decided_by INT NULL AFTER decision_comment,
+    ADD COLUMN decided_at DATETIME NULL AFTER decided_by,
+    ADD INDEX idx_leave_requests_decided_by (decided_by),
+    ADD CONSTRAINT fk_leave_requests_decided_by FOREIGN KEY (decided_by) REFERENCES employees(id);
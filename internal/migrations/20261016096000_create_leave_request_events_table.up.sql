@@ -0,0 +1,13 @@
+CREATE TABLE leave_request_events (
+    id INT NOT NULL AUTO_INCREMENT,
+    leave_request_id INT NOT NULL,
+    actor_id INT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    changes JSON NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id),
+    FOREIGN KEY (actor_id) REFERENCES employees(id),
+    INDEX idx_leave_request_id (leave_request_id),
+    INDEX idx_actor_id (actor_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
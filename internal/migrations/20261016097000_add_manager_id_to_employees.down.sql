@@ -0,0 +1,4 @@
+ALTER TABLE employees
+    DROP FOREIGN KEY fk_employees_manager_id,
+    DROP INDEX idx_employees_manager_id,
+    DROP COLUMN manager_id;
@@ -0,0 +1,4 @@
+ALTER TABLE employees
+    ADD COLUMN manager_id INT NULL AFTER role,
+    ADD INDEX idx_employees_manager_id (manager_id),
+    ADD CONSTRAINT fk_employees_manager_id FOREIGN KEY (manager_id) REFERENCES employees(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE leave_request_approvals (
+    id INT NOT NULL AUTO_INCREMENT,
+    leave_request_id INT NOT NULL,
+    step INT NOT NULL,
+    approver VARCHAR(50) NOT NULL,
+    status ENUM('pending', 'approved', 'rejected', 'superseded') NOT NULL DEFAULT 'pending',
+    decided_by INT NULL,
+    decided_at DATETIME NULL,
+    comment TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (leave_request_id) REFERENCES leave_requests(id),
+    FOREIGN KEY (decided_by) REFERENCES employees(id),
+    INDEX idx_leave_request_id_status (leave_request_id, status),
+    INDEX idx_decided_by (decided_by)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,19 @@
+CREATE TABLE role_permissions (
+    id INT NOT NULL AUTO_INCREMENT,
+    role VARCHAR(50) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_role_permission (role, permission)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('hr', 'leave:read:all'),
+    ('hr', 'leave:read:team'),
+    ('hr', 'leave:update:all'),
+    ('hr', 'leave:approve:all'),
+    ('hr', 'holiday:manage'),
+    ('hr', 'employee:create'),
+    ('hr', 'employee:update'),
+    ('manager', 'leave:read:team'),
+    ('manager', 'leave:update:all');
@@ -0,0 +1,17 @@
+CREATE TABLE invitations (
+    id INT NOT NULL AUTO_INCREMENT,
+    email VARCHAR(100) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    invited_by INT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    accepted_at DATETIME NULL,
+    employee_id INT NULL,
+    revoked_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (invited_by) REFERENCES employees(id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    INDEX idx_email (email),
+    INDEX idx_invited_by (invited_by)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,16 @@
+CREATE TABLE refresh_tokens (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    family_id CHAR(32) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME NULL,
+    replaced_by_id INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (replaced_by_id) REFERENCES refresh_tokens(id),
+    UNIQUE INDEX idx_token_hash (token_hash),
+    INDEX idx_employee_id (employee_id),
+    INDEX idx_family_id (family_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
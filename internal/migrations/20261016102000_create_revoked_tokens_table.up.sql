@@ -0,0 +1,10 @@
+CREATE TABLE revoked_tokens (
+    jti CHAR(32) NOT NULL,
+    employee_id INT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (jti),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    INDEX idx_employee_id (employee_id),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
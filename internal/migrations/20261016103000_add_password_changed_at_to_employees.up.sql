@@ -0,0 +1,2 @@
+ALTER TABLE employees
+    ADD COLUMN password_changed_at DATETIME NULL AFTER password;
@@ -0,0 +1,12 @@
+CREATE TABLE password_reset_tokens (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    UNIQUE INDEX idx_token_hash (token_hash),
+    INDEX idx_employee_id (employee_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
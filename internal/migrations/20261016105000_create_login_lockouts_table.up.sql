@@ -0,0 +1,14 @@
+CREATE TABLE login_lockouts (
+    id INT NOT NULL AUTO_INCREMENT,
+    scope VARCHAR(20) NOT NULL,
+    email VARCHAR(100) NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    employee_id INT NULL,
+    attempts INT NOT NULL,
+    locked_until DATETIME NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id) ON DELETE SET NULL,
+    INDEX idx_email (email),
+    INDEX idx_ip_address (ip_address)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE mfa_enrollments (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at DATETIME NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    UNIQUE INDEX idx_employee_id (employee_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
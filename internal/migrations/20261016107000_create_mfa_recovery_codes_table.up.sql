@@ -0,0 +1,10 @@
+CREATE TABLE mfa_recovery_codes (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    UNIQUE INDEX idx_employee_code_hash (employee_id, code_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
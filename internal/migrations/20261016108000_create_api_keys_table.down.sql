@@ -0,0 +1,3 @@
+DELETE FROM role_permissions WHERE permission IN ('api_key:manage', 'employee:read');
+DROP TABLE api_key_permissions;
+DROP TABLE api_keys;
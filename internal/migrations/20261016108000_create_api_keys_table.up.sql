@@ -0,0 +1,29 @@
+CREATE TABLE api_keys (
+    id INT NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    created_by INT NOT NULL,
+    expires_at DATETIME NULL,
+    last_used_at DATETIME NULL,
+    revoked_at DATETIME NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (created_by) REFERENCES employees(id),
+    UNIQUE INDEX idx_key_hash (key_hash),
+    INDEX idx_created_by (created_by)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE api_key_permissions (
+    id INT NOT NULL AUTO_INCREMENT,
+    api_key_id INT NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_api_key_permission (api_key_id, permission)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('hr', 'api_key:manage'),
+    ('hr', 'employee:read');
@@ -0,0 +1,3 @@
+ALTER TABLE employees
+    ADD COLUMN locale VARCHAR(35) NULL AFTER manager_id,
+    ADD COLUMN timezone VARCHAR(64) NULL AFTER locale;
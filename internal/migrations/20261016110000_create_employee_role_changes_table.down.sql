@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission = 'employee:role:assign';
+DROP TABLE employee_role_changes;
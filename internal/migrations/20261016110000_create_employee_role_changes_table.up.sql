@@ -0,0 +1,16 @@
+CREATE TABLE employee_role_changes (
+    id INT NOT NULL AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    actor_id INT NOT NULL,
+    from_role VARCHAR(50) NULL,
+    to_role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (employee_id) REFERENCES employees(id),
+    FOREIGN KEY (actor_id) REFERENCES employees(id),
+    INDEX idx_employee_id (employee_id),
+    INDEX idx_actor_id (actor_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('hr', 'employee:role:assign');
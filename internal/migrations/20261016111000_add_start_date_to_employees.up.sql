@@ -0,0 +1,2 @@
+ALTER TABLE employees
+    ADD COLUMN start_date DATE NULL AFTER manager_id;
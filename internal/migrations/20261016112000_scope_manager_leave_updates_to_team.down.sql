@@ -0,0 +1,4 @@
+DELETE FROM role_permissions WHERE role = 'manager' AND permission = 'leave:update:team';
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('manager', 'leave:update:all');
@@ -0,0 +1,3 @@
+DELETE FROM employee_role_changes WHERE actor_id IS NULL;
+
+ALTER TABLE employee_role_changes MODIFY actor_id INT NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE employee_role_changes MODIFY actor_id INT NULL;
@@ -0,0 +1,3 @@
+-- The original casing of the emails is not kept, and lowercased emails work
+-- with the code before this migration, so there is nothing to undo.
+DO 0;
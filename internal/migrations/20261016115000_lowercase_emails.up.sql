@@ -0,0 +1,9 @@
+-- Emails are stored trimmed and lowercased and looked up the same way. The
+-- binary comparison finds rows that differ only in case.
+UPDATE employees
+SET email = LOWER(TRIM(email))
+WHERE BINARY email <> LOWER(TRIM(email));
+
+UPDATE invitations
+SET email = LOWER(TRIM(email))
+WHERE BINARY email <> LOWER(TRIM(email));
@@ -0,0 +1,2 @@
+INSERT INTO role_permissions (role, permission) VALUES
+    ('hr', 'leave:balance:manage');
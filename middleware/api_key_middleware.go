@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader carries the key of a service calling the API.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware authenticates a service from its API key. The request is
+// given the service identity under "api_key"; user_id and role stay unset,
+// as no employee is involved.
+func APIKeyMiddleware(apiKeys services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(APIKeyHeader)
+		if key == "" {
+			return apperrors.Unauthorized("unauthorized", "Missing API key")
+		}
+
+		identity, err := apiKeys.Authenticate(key)
+		if err != nil {
+			return err
+		}
+
+		c.Locals("api_key", identity)
+
+		return c.Next()
+	}
+}
+
+// JWTOrAPIKeyMiddleware authenticates with the API key when the request
+// carries one, and with the bearer access token otherwise. Only routes whose
+// handlers cope with a service identity may use it.
+func JWTOrAPIKeyMiddleware(tokens services.TokenService, apiKeys services.APIKeyService) fiber.Handler {
+	jwtAuth := JWTMiddleware(tokens)
+	apiKeyAuth := APIKeyMiddleware(apiKeys)
+
+	return func(c *fiber.Ctx) error {
+		if c.Get(APIKeyHeader) != "" {
+			return apiKeyAuth(c)
+		}
+		return jwtAuth(c)
+	}
+}
+
+// RequireAPIKeyPermission only lets services through whose API key holds the
+// permission. Employees pass unchecked; what they may see is decided as
+// usual by the handlers.
+func RequireAPIKeyPermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identity, ok := c.Locals("api_key").(*services.APIKeyIdentity)
+		if ok && !identity.Permissions.Has(permission) {
+			return services.ErrPermissionDenied.Withf("missing permission %s", permission)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"hr-leave-request/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireMFAEnrollment refuses the request while the authenticated user's
+// role requires two-factor authentication and they have not set it up. It
+// must run after JWTMiddleware; services using an API key are not affected.
+func RequireMFAEnrollment(mfa services.MFAService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+			return c.Next()
+		}
+
+		userID, _ := c.Locals("user_id").(uint)
+		role, _ := c.Locals("role").(string)
+
+		required, err := mfa.EnrollmentRequired(userID, role)
+		if err != nil {
+			return err
+		}
+		if required {
+			return services.ErrMFAEnrollmentRequired
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"hr-leave-request/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission only lets the request through when the role of the
+// authenticated user, or the API key of the service, holds the permission.
+// It must run after JWTMiddleware or APIKeyMiddleware.
+func RequirePermission(checker services.PermissionChecker, permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if identity, ok := c.Locals("api_key").(*services.APIKeyIdentity); ok {
+			if !identity.Permissions.Has(permission) {
+				return services.ErrPermissionDenied.Withf("missing permission %s", permission)
+			}
+			return c.Next()
+		}
+
+		role, _ := c.Locals("role").(string)
+
+		allowed, err := checker.HasPermission(role, permission)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return services.ErrPermissionDenied.Withf("missing permission %s", permission)
+		}
+
+		return c.Next()
+	}
+}
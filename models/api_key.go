@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey lets a service, such as a payroll job, call the API without an
+// employee account. Only a hash of the key is stored; the key itself is shown
+// once, when it is created.
+type APIKey struct {
+	ID          uint               `gorm:"primaryKey" json:"id"`
+	Name        string             `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix      string             `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash     string             `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	Permissions []APIKeyPermission `gorm:"foreignKey:APIKeyID" json:"permissions"`
+	CreatedBy   uint               `gorm:"not null;index" json:"created_by"`
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time         `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time         `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// APIKeyPermission grants a permission to an API key, the way
+// RolePermission does to a role.
+type APIKeyPermission struct {
+	ID         uint   `gorm:"primaryKey" json:"-"`
+	APIKeyID   uint   `gorm:"not null;index" json:"-"`
+	Permission string `gorm:"type:varchar(100);not null" json:"permission"`
+}
+
+func (APIKeyPermission) TableName() string {
+	return "api_key_permissions"
+}
@@ -7,14 +7,19 @@ import (
 )
 
 type Employee struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"type:varchar(100);not null" json:"name"`
-	Email     string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"type:varchar(255);not null" json:"-"`
-	Role      *string        `gorm:"type:varchar(50);default:'employee'" json:"role,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	Name              string         `gorm:"type:varchar(100);not null" json:"name"`
+	Email             string         `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"`
+	Password          string         `gorm:"type:varchar(255);not null" json:"-"`
+	PasswordChangedAt *time.Time     `json:"-"`
+	Role              *string        `gorm:"type:varchar(50);default:'employee'" json:"role,omitempty"`
+	ManagerID         *uint          `gorm:"index" json:"manager_id,omitempty"`
+	StartDate         *time.Time     `gorm:"type:date" json:"start_date,omitempty"`
+	Locale            *string        `gorm:"type:varchar(35)" json:"locale,omitempty"`
+	Timezone          *string        `gorm:"type:varchar(64)" json:"timezone,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (Employee) TableName() string {
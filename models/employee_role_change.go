@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// EmployeeRoleChange is an entry in the audit trail of the roles granted to
+// an employee. FromRole is nil when the role was granted on creation, and
+// ActorID is nil when nobody granted it, as on self-registration or single
+// sign-on.
+type EmployeeRoleChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	EmployeeID uint      `gorm:"not null;index" json:"employee_id"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"`
+	FromRole   *string   `gorm:"type:varchar(50)" json:"from_role"`
+	ToRole     string    `gorm:"type:varchar(50);not null" json:"to_role"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (EmployeeRoleChange) TableName() string {
+	return "employee_role_changes"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+type Holiday struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Date      time.Time `gorm:"type:date;uniqueIndex;not null" json:"date"`
+	Name      string    `gorm:"type:varchar(150);not null" json:"name"`
+	Source    string    `gorm:"type:enum('manual','ics');not null;default:'manual'" json:"source"`
+	UID       *string   `gorm:"column:uid;type:varchar(255)" json:"uid,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Holiday) TableName() string {
+	return "holidays"
+}
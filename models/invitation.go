@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Invitation lets HR bring in a new employee with a given role. The invitee
+// accepts it once, through the signed token handed out when it was created.
+type Invitation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"type:varchar(100);not null;index" json:"email"`
+	Role       string     `gorm:"type:varchar(50);not null" json:"role"`
+	InvitedBy  uint       `gorm:"not null;index" json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	EmployeeID *uint      `json:"employee_id,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Invitation) TableName() string {
+	return "invitations"
+}
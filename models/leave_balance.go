@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+type LeaveBalance struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	EmployeeID   uint      `gorm:"not null;uniqueIndex:idx_leave_balances_employee_type_year" json:"employee_id"`
+	Type         string    `gorm:"type:enum('sick','vacation','personal','other');not null;uniqueIndex:idx_leave_balances_employee_type_year" json:"type"`
+	Year         int       `gorm:"not null;uniqueIndex:idx_leave_balances_employee_type_year" json:"year"`
+	EntitledDays float64   `gorm:"type:decimal(6,2);not null;default:0" json:"entitled_days"`
+	UsedDays     float64   `gorm:"type:decimal(6,2);not null;default:0" json:"used_days"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (LeaveBalance) TableName() string {
+	return "leave_balances"
+}
+
+// RemainingDays returns the part of the entitlement that has not been used yet.
+func (b *LeaveBalance) RemainingDays() float64 {
+	return b.EntitledDays - b.UsedDays
+}
+
+type LeaveBalanceTransaction struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	LeaveBalanceID uint      `gorm:"not null;index" json:"leave_balance_id"`
+	LeaveRequestID *uint     `gorm:"index" json:"leave_request_id,omitempty"`
+	Kind           string    `gorm:"type:enum('debit','credit');not null" json:"kind"`
+	Days           float64   `gorm:"type:decimal(6,2);not null" json:"days"`
+	Note           *string   `gorm:"type:varchar(255)" json:"note,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (LeaveBalanceTransaction) TableName() string {
+	return "leave_balance_transactions"
+}
@@ -7,17 +7,22 @@ import (
 )
 
 type LeaveRequest struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	EmployeeID uint           `gorm:"not null;index" json:"employee_id"`
-	Employee   *Employee      `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
-	StartDate  time.Time      `gorm:"type:datetime;not null" json:"start_date"`
-	EndDate    time.Time      `gorm:"type:datetime;not null" json:"end_date"`
-	Type       string         `gorm:"type:enum('sick','vacation','personal','other');not null" json:"type"`
-	Status     string         `gorm:"type:enum('pending','approved','rejected');not null;default:'pending'" json:"status"`
-	Reason     *string        `gorm:"type:text" json:"reason,omitempty"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	EmployeeID      uint           `gorm:"not null;index" json:"employee_id"`
+	Employee        *Employee      `gorm:"foreignKey:EmployeeID" json:"employee,omitempty"`
+	StartDate       time.Time      `gorm:"type:datetime;not null" json:"start_date"`
+	EndDate         time.Time      `gorm:"type:datetime;not null" json:"end_date"`
+	Granularity     string         `gorm:"type:enum('full_day','half_day_am','half_day_pm','hours');not null;default:'full_day'" json:"granularity"`
+	WorkingDays     float64        `gorm:"type:decimal(5,2);not null;default:0" json:"working_days"`
+	Type            string         `gorm:"type:enum('sick','vacation','personal','other');not null" json:"type"`
+	Status          string         `gorm:"type:enum('draft','pending','approved','rejected','cancelled','withdrawn','cancellation_requested');not null;default:'pending'" json:"status"`
+	Reason          *string        `gorm:"type:text" json:"reason,omitempty"`
+	DecisionComment *string        `gorm:"type:text" json:"decision_comment,omitempty"`
+	DecidedBy       *uint          `gorm:"index" json:"decided_by,omitempty"`
+	DecidedAt       *time.Time     `gorm:"type:datetime" json:"decided_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (LeaveRequest) TableName() string {
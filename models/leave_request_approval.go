@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// LeaveRequestApproval is one step of the approval chain of a leave request.
+// Approver is "line_manager" or the role that signs off the step. Steps are
+// superseded when the request is edited while awaiting approval, and a fresh
+// chain is started.
+type LeaveRequestApproval struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	LeaveRequestID uint       `gorm:"not null;index" json:"leave_request_id"`
+	Step           int        `gorm:"not null" json:"step"`
+	Approver       string     `gorm:"type:varchar(50);not null" json:"approver"`
+	Status         string     `gorm:"type:enum('pending','approved','rejected','superseded');not null;default:'pending'" json:"status"`
+	DecidedBy      *uint      `gorm:"index" json:"decided_by,omitempty"`
+	DecidedAt      *time.Time `gorm:"type:datetime" json:"decided_at,omitempty"`
+	Comment        *string    `gorm:"type:text" json:"comment,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (LeaveRequestApproval) TableName() string {
+	return "leave_request_approvals"
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// LeaveRequestEvent is an entry in the audit trail of a leave request. Changes
+// holds a JSON object mapping each changed field to its "from" and "to" values.
+type LeaveRequestEvent struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	LeaveRequestID uint      `gorm:"not null;index" json:"leave_request_id"`
+	ActorID        uint      `gorm:"not null;index" json:"actor_id"`
+	Action         string    `gorm:"type:varchar(50);not null" json:"action"`
+	Changes        string    `gorm:"type:json;not null" json:"changes"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (LeaveRequestEvent) TableName() string {
+	return "leave_request_events"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginLockout records that an account or a client IP was locked out after
+// too many failed logins. Email is empty for IP lockouts, and EmployeeID is
+// only set when the email belongs to an employee.
+type LoginLockout struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Scope       string    `gorm:"type:varchar(20);not null" json:"scope"`
+	Email       *string   `gorm:"type:varchar(100);index" json:"email,omitempty"`
+	IPAddress   string    `gorm:"type:varchar(45);not null;index" json:"ip_address"`
+	EmployeeID  *uint     `json:"employee_id,omitempty"`
+	Attempts    int       `gorm:"not null" json:"attempts"`
+	LockedUntil time.Time `gorm:"not null" json:"locked_until"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (LoginLockout) TableName() string {
+	return "login_lockouts"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// MFAEnrollment holds the TOTP secret of an employee. Two-factor
+// authentication is only enabled once the enrollment is confirmed with a
+// first code. LastUsedStep is the time step of the last accepted code, so
+// that a code cannot be used twice.
+type MFAEnrollment struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	EmployeeID   uint       `gorm:"not null;uniqueIndex" json:"employee_id"`
+	Secret       string     `gorm:"type:varchar(64);not null" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (MFAEnrollment) TableName() string {
+	return "mfa_enrollments"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// MFARecoveryCode is a single-use code that stands in for a TOTP code when
+// the employee lost their authenticator. Only its SHA-256 hash is stored.
+type MFARecoveryCode struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	EmployeeID uint       `gorm:"not null;index" json:"employee_id"`
+	CodeHash   string     `gorm:"type:char(64);not null" json:"-"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
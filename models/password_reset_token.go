@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordResetToken lets an employee who forgot their password set a new
+// one. Only its SHA-256 hash is stored and it can be used once.
+type PasswordResetToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	EmployeeID uint       `gorm:"not null;index" json:"employee_id"`
+	TokenHash  string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is a long-lived token exchanged for new access tokens. Only
+// its SHA-256 hash is stored. Every refresh replaces it with a new token of
+// the same family, so reusing a replaced token reveals that it leaked.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	EmployeeID   uint       `gorm:"not null;index" json:"employee_id"`
+	TokenHash    string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	FamilyID     string     `gorm:"type:char(32);not null;index" json:"family_id"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID *uint      `json:"replaced_by_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// RevokedToken lists an access token, by its jti claim, that was revoked
+// before it expired. Entries are only needed until ExpiresAt.
+type RevokedToken struct {
+	JTI        string    `gorm:"column:jti;type:char(32);primaryKey" json:"jti"`
+	EmployeeID uint      `gorm:"not null;index" json:"employee_id"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// RolePermission grants a permission, such as "leave:approve:all", to every
+// employee holding the role.
+type RolePermission struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Role       string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_role_permission" json:"role"`
+	Permission string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_role_permission" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
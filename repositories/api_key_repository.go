@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(apiKey *models.APIKey) error
+	FindAll() ([]models.APIKey, error)
+	FindByID(id uint) (*models.APIKey, error)
+	FindByHash(keyHash string) (*models.APIKey, error)
+	Revoke(id uint, revokedAt time.Time) (bool, error)
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create stores the key together with its permissions.
+func (r *apiKeyRepository) Create(apiKey *models.APIKey) error {
+	return r.db.Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) FindAll() ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := r.db.Preload("Permissions").Order("created_at DESC").Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.Preload("Permissions").First(&apiKey, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.Preload("Permissions").Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// Revoke disables the key for good. It reports false when the key was
+// already revoked.
+func (r *apiKeyRepository) Revoke(id uint, revokedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TouchLastUsed records when the key was last used. It leaves updated_at
+// alone, which tracks changes to the key itself.
+func (r *apiKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).Error
+}
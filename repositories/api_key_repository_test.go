@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyFindByHash(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT \\* FROM `api_keys` WHERE key_hash = \\? ORDER BY `api_keys`.`id` LIMIT \\?").
+		WithArgs("hash", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "key_hash", "created_by"}).
+			AddRow(4, "Payroll export", "hrk_abcdefgh", "hash", 1))
+	mock.ExpectQuery("SELECT \\* FROM `api_key_permissions` WHERE `api_key_permissions`.`api_key_id` = \\?").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "api_key_id", "permission"}).
+			AddRow(1, 4, "leave:read:all").
+			AddRow(2, 4, "employee:read"))
+
+	repo := NewAPIKeyRepository(db)
+	apiKey, err := repo.FindByHash("hash")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), apiKey.ID)
+	assert.Len(t, apiKey.Permissions, 2)
+	assert.Equal(t, "leave:read:all", apiKey.Permissions[0].Permission)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRevoke(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		rowsUpdated int64
+		wantRevoked bool
+	}{
+		{name: "active key is revoked", rowsUpdated: 1, wantRevoked: true},
+		{name: "revoked key is not revoked again", rowsUpdated: 0, wantRevoked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE `api_keys` SET `revoked_at`=\\?,`updated_at`=\\? WHERE id = \\? AND revoked_at IS NULL").
+				WithArgs(now, sqlmock.AnyArg(), 4).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsUpdated))
+			mock.ExpectCommit()
+
+			repo := NewAPIKeyRepository(db)
+			revoked, err := repo.Revoke(4, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRevoked, revoked)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAPIKeyTouchLastUsed(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `api_keys` SET `last_used_at`=\\? WHERE id = \\?").
+		WithArgs(now, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewAPIKeyRepository(db)
+	err := repo.TouchLastUsed(4, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
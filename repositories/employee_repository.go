@@ -7,12 +7,18 @@ import (
 )
 
 type EmployeeRepository interface {
+	WithTx(tx *gorm.DB) EmployeeRepository
 	Create(employee *models.Employee) error
 	FindByID(id uint) (*models.Employee, error)
 	FindByEmail(email string) (*models.Employee, error)
-	FindAll(page, pageSize int, search, sortBy, sortDir string) ([]models.Employee, int64, error)
+	FindByIDWithDeleted(id uint) (*models.Employee, error)
+	FindByEmailWithDeleted(email string) (*models.Employee, error)
+	FindAll(page, pageSize int, search, sortBy, sortDir string, includeDeleted bool, visibleTo *uint) ([]models.Employee, int64, error)
 	Update(employee *models.Employee) error
+	UpdateManager(id uint, managerID *uint) error
+	UpdateProfile(employee *models.Employee) error
 	Delete(id uint) error
+	Restore(id uint) error
 }
 
 type employeeRepository struct {
@@ -23,6 +29,10 @@ func NewEmployeeRepository(db *gorm.DB) EmployeeRepository {
 	return &employeeRepository{db: db}
 }
 
+func (r *employeeRepository) WithTx(tx *gorm.DB) EmployeeRepository {
+	return &employeeRepository{db: tx}
+}
+
 func (r *employeeRepository) Create(employee *models.Employee) error {
 	return r.db.Create(employee).Error
 }
@@ -45,11 +55,42 @@ func (r *employeeRepository) FindByEmail(email string) (*models.Employee, error)
 	return &employee, nil
 }
 
-func (r *employeeRepository) FindAll(page, pageSize int, search, sortBy, sortDir string) ([]models.Employee, int64, error) {
+// FindByIDWithDeleted finds an employee whether deactivated or not.
+func (r *employeeRepository) FindByIDWithDeleted(id uint) (*models.Employee, error) {
+	var employee models.Employee
+	err := r.db.Unscoped().First(&employee, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// FindByEmailWithDeleted finds an employee by email whether deactivated or
+// not. Deactivated employees keep their address, which stays unique.
+func (r *employeeRepository) FindByEmailWithDeleted(email string) (*models.Employee, error) {
+	var employee models.Employee
+	err := r.db.Unscoped().Where("email = ?", email).First(&employee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+// FindAll lists employees. With visibleTo, only that employee, their manager
+// and their direct reports are listed.
+func (r *employeeRepository) FindAll(page, pageSize int, search, sortBy, sortDir string, includeDeleted bool, visibleTo *uint) ([]models.Employee, int64, error) {
 	var employees []models.Employee
 	var total int64
 
 	query := r.db.Model(&models.Employee{})
+	if includeDeleted {
+		query = query.Unscoped()
+	}
+
+	if visibleTo != nil {
+		query = query.Where("id = ? OR manager_id = ? OR id IN (?)", *visibleTo, *visibleTo,
+			r.db.Model(&models.Employee{}).Select("manager_id").Where("id = ?", *visibleTo))
+	}
 
 	// Apply search filter
 	if search != "" {
@@ -84,6 +125,23 @@ func (r *employeeRepository) Update(employee *models.Employee) error {
 	return r.db.Model(employee).Updates(employee).Error
 }
 
+// UpdateManager sets or, with a nil managerID, clears the line manager of an
+// employee. Update cannot clear it since Updates skips nil fields.
+func (r *employeeRepository) UpdateManager(id uint, managerID *uint) error {
+	return r.db.Model(&models.Employee{}).Where("id = ?", id).Update("manager_id", managerID).Error
+}
+
+// UpdateProfile saves the fields employees manage themselves. Preferences
+// set to nil are cleared, which Update cannot do.
+func (r *employeeRepository) UpdateProfile(employee *models.Employee) error {
+	return r.db.Model(employee).Select("name", "locale", "timezone").Updates(employee).Error
+}
+
 func (r *employeeRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Employee{}, id).Error
 }
+
+// Restore reactivates an employee deactivated with Delete.
+func (r *employeeRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&models.Employee{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
@@ -49,7 +49,7 @@ func TestCreate(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 				mock.ExpectExec("INSERT INTO `employees`").
-					WithArgs("John Doe", "john@example.com", "hashedpassword", &role, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
+					WithArgs("John Doe", "john@example.com", "hashedpassword", nil, &role, nil, nil, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), nil).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 				mock.ExpectCommit()
 			},
@@ -230,18 +230,21 @@ func TestFindByEmail(t *testing.T) {
 func TestFindAll(t *testing.T) {
 	role := "employee"
 	now := time.Now()
+	viewerID := uint(5)
 
 	tests := []struct {
-		name          string
-		page          int
-		pageSize      int
-		search        string
-		sortBy        string
-		sortDir       string
-		mockSetup     func(sqlmock.Sqlmock)
-		expectedCount int
-		expectedTotal int64
-		wantError     bool
+		name           string
+		page           int
+		pageSize       int
+		search         string
+		sortBy         string
+		sortDir        string
+		includeDeleted bool
+		visibleTo      *uint
+		mockSetup      func(sqlmock.Sqlmock)
+		expectedCount  int
+		expectedTotal  int64
+		wantError      bool
 	}{
 		{
 			name:     "get all employees",
@@ -319,6 +322,53 @@ func TestFindAll(t *testing.T) {
 			expectedTotal: 5,
 			wantError:     false,
 		},
+		{
+			name:           "include deactivated employees",
+			page:           1,
+			pageSize:       10,
+			sortBy:         "created_at",
+			sortDir:        "desc",
+			includeDeleted: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(2)
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `employees`$").
+					WillReturnRows(countRows)
+
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "password", "role", "created_at", "updated_at", "deleted_at"}).
+					AddRow(1, "John Doe", "john@example.com", "hash1", role, now, now, nil).
+					AddRow(2, "Jane Doe", "jane@example.com", "hash2", role, now, now, now)
+				mock.ExpectQuery("SELECT \\* FROM `employees` ORDER BY").
+					WithArgs(10).
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			expectedTotal: 2,
+			wantError:     false,
+		},
+		{
+			name:      "only employees visible to the viewer",
+			page:      1,
+			pageSize:  10,
+			sortBy:    "created_at",
+			sortDir:   "desc",
+			visibleTo: &viewerID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(2)
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `employees` WHERE \\(id = \\? OR manager_id = \\? OR id IN \\(SELECT `manager_id` FROM `employees` WHERE id = \\? AND `employees`.`deleted_at` IS NULL\\)\\)").
+					WithArgs(5, 5, 5).
+					WillReturnRows(countRows)
+
+				rows := sqlmock.NewRows([]string{"id", "name", "email", "password", "role", "created_at", "updated_at", "deleted_at"}).
+					AddRow(5, "John Doe", "john@example.com", "hash1", role, now, now, nil).
+					AddRow(6, "Jane Doe", "jane@example.com", "hash2", role, now, now, nil)
+				mock.ExpectQuery("SELECT \\* FROM `employees` WHERE \\(id = \\? OR manager_id = \\? OR id IN").
+					WithArgs(5, 5, 5, 10).
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			expectedTotal: 2,
+			wantError:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -329,7 +379,7 @@ func TestFindAll(t *testing.T) {
 			tt.mockSetup(mock)
 
 			repo := NewEmployeeRepository(db)
-			results, total, err := repo.FindAll(tt.page, tt.pageSize, tt.search, tt.sortBy, tt.sortDir)
+			results, total, err := repo.FindAll(tt.page, tt.pageSize, tt.search, tt.sortBy, tt.sortDir, tt.includeDeleted, tt.visibleTo)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -410,6 +460,73 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateManager(t *testing.T) {
+	managerID := uint(3)
+
+	tests := []struct {
+		name      string
+		managerID *uint
+		mockSetup func(sqlmock.Sqlmock)
+		wantError bool
+	}{
+		{
+			name:      "assign manager",
+			managerID: &managerID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `employees` SET `manager_id`=\\?,`updated_at`=\\? WHERE id = \\?").
+					WithArgs(managerID, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantError: false,
+		},
+		{
+			name:      "clear manager",
+			managerID: nil,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `employees` SET `manager_id`=\\?").
+					WithArgs(nil, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantError: false,
+		},
+		{
+			name:      "database error",
+			managerID: &managerID,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `employees` SET `manager_id`").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewEmployeeRepository(db)
+			err := repo.UpdateManager(1, tt.managerID)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestDelete(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -463,3 +580,96 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestFindByIDWithDeleted(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "password", "role", "created_at", "updated_at", "deleted_at"}).
+		AddRow(1, "John Doe", "john@example.com", "hashedpassword", "employee", now, now, now)
+	mock.ExpectQuery("SELECT \\* FROM `employees` WHERE `employees`.`id` = \\? ORDER BY").
+		WithArgs(1, 1).
+		WillReturnRows(rows)
+
+	repo := NewEmployeeRepository(db)
+	result, err := repo.FindByIDWithDeleted(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), result.ID)
+	assert.True(t, result.DeletedAt.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestore(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        uint
+		mockSetup func(sqlmock.Sqlmock)
+		wantError bool
+	}{
+		{
+			name: "successful restore",
+			id:   1,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `employees` SET `deleted_at`=\\?,`updated_at`=\\? WHERE id = \\?$").
+					WithArgs(nil, sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantError: false,
+		},
+		{
+			name: "database error on restore",
+			id:   2,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `employees` SET `deleted_at`").
+					WithArgs(nil, sqlmock.AnyArg(), 2).
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewEmployeeRepository(db)
+			err := repo.Restore(tt.id)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	// Cleared preferences are written as NULL
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `employees` SET `name`=\\?,`locale`=\\?,`timezone`=\\?,`updated_at`=\\? WHERE `employees`.`deleted_at` IS NULL AND `id` = \\?").
+		WithArgs("Jane Doe", nil, "Europe/Berlin", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	timezone := "Europe/Berlin"
+	repo := NewEmployeeRepository(db)
+	err := repo.UpdateProfile(&models.Employee{ID: 1, Name: "Jane Doe", Timezone: &timezone})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+)
+
+type EmployeeRoleChangeRepository interface {
+	WithTx(tx *gorm.DB) EmployeeRoleChangeRepository
+	Create(change *models.EmployeeRoleChange) error
+	FindByEmployeeID(employeeID uint) ([]models.EmployeeRoleChange, error)
+}
+
+type employeeRoleChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmployeeRoleChangeRepository(db *gorm.DB) EmployeeRoleChangeRepository {
+	return &employeeRoleChangeRepository{db: db}
+}
+
+func (r *employeeRoleChangeRepository) WithTx(tx *gorm.DB) EmployeeRoleChangeRepository {
+	return &employeeRoleChangeRepository{db: tx}
+}
+
+func (r *employeeRoleChangeRepository) Create(change *models.EmployeeRoleChange) error {
+	return r.db.Create(change).Error
+}
+
+// FindByEmployeeID returns the role history of an employee, oldest first.
+func (r *employeeRoleChangeRepository) FindByEmployeeID(employeeID uint) ([]models.EmployeeRoleChange, error) {
+	var changes []models.EmployeeRoleChange
+	err := r.db.Where("employee_id = ?", employeeID).
+		Order("created_at ASC, id ASC").
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
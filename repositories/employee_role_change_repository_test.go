@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"database/sql"
+	"hr-leave-request/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateEmployeeRoleChange(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `employee_role_changes`").
+		WithArgs(4, 1, "employee", "manager", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	fromRole := "employee"
+	actorID := uint(1)
+	repo := NewEmployeeRoleChangeRepository(db)
+	change := &models.EmployeeRoleChange{EmployeeID: 4, ActorID: &actorID, FromRole: &fromRole, ToRole: "manager"}
+	err := repo.Create(change)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), change.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindEmployeeRoleChangesByEmployeeID(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "changes ordered oldest first",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "employee_id", "actor_id", "from_role", "to_role", "created_at"}).
+					AddRow(1, 4, 1, nil, "employee", now).
+					AddRow(2, 4, 1, "employee", "manager", now)
+				mock.ExpectQuery("SELECT \\* FROM `employee_role_changes` WHERE employee_id = \\? ORDER BY created_at ASC, id ASC").
+					WithArgs(4).
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `employee_role_changes`").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewEmployeeRoleChangeRepository(db)
+			changes, err := repo.FindByEmployeeID(4)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, changes, tt.expectedCount)
+				assert.Nil(t, changes[0].FromRole)
+				assert.Equal(t, "manager", changes[1].ToRole)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
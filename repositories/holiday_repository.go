@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HolidayRepository interface {
+	Create(holiday *models.Holiday) error
+	FindByID(id uint) (*models.Holiday, error)
+	FindByDate(date time.Time) (*models.Holiday, error)
+	FindBetween(startDate, endDate time.Time) ([]models.Holiday, error)
+	Update(holiday *models.Holiday) error
+	Delete(id uint) error
+	Upsert(holidays []models.Holiday) error
+}
+
+type holidayRepository struct {
+	db *gorm.DB
+}
+
+func NewHolidayRepository(db *gorm.DB) HolidayRepository {
+	return &holidayRepository{db: db}
+}
+
+func (r *holidayRepository) Create(holiday *models.Holiday) error {
+	return r.db.Create(holiday).Error
+}
+
+func (r *holidayRepository) FindByID(id uint) (*models.Holiday, error) {
+	var holiday models.Holiday
+	err := r.db.First(&holiday, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+func (r *holidayRepository) FindByDate(date time.Time) (*models.Holiday, error) {
+	var holiday models.Holiday
+	err := r.db.Where("date = ?", date.Format(time.DateOnly)).First(&holiday).Error
+	if err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// FindBetween returns the holidays falling on the calendar dates of startDate
+// through endDate, both inclusive, ordered by date.
+func (r *holidayRepository) FindBetween(startDate, endDate time.Time) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	err := r.db.Where("date BETWEEN ? AND ?", startDate.Format(time.DateOnly), endDate.Format(time.DateOnly)).
+		Order("date ASC").
+		Find(&holidays).Error
+	if err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (r *holidayRepository) Update(holiday *models.Holiday) error {
+	return r.db.Save(holiday).Error
+}
+
+func (r *holidayRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Holiday{}, id).Error
+}
+
+// Upsert inserts the given holidays, overwriting the name and origin of any
+// holiday already stored for the same date.
+func (r *holidayRepository) Upsert(holidays []models.Holiday) error {
+	if len(holidays) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "source", "uid", "updated_at"}),
+	}).Create(&holidays).Error
+}
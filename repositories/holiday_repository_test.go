@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"database/sql"
+	"hr-leave-request/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHolidayFindBetween(t *testing.T) {
+	now := time.Now()
+	startDate := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "holidays in range",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "date", "name", "source", "uid", "created_at", "updated_at"}).
+					AddRow(1, time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), "Christmas", "manual", nil, now, now)
+				mock.ExpectQuery("SELECT \\* FROM `holidays` WHERE date BETWEEN").
+					WithArgs("2026-12-01", "2026-12-31").
+					WillReturnRows(rows)
+			},
+			expectedCount: 1,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `holidays` WHERE date BETWEEN").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewHolidayRepository(db)
+			results, err := repo.FindBetween(startDate, endDate)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, len(results))
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestHolidayUpsert(t *testing.T) {
+	holidays := []models.Holiday{
+		{Date: time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas", Source: "ics"},
+		{Date: time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC), Name: "Boxing Day", Source: "ics"},
+	}
+
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `holidays` .* ON DUPLICATE KEY UPDATE").
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectCommit()
+
+	repo := NewHolidayRepository(db)
+	assert.NoError(t, repo.Upsert(holidays))
+	assert.NoError(t, repo.Upsert(nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
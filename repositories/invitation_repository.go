@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	WithTx(tx *gorm.DB) InvitationRepository
+	Create(invitation *models.Invitation) error
+	FindByID(id uint) (*models.Invitation, error)
+	MarkAccepted(id uint, employeeID uint, acceptedAt time.Time) (bool, error)
+	Revoke(id uint, revokedAt time.Time) (bool, error)
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) WithTx(tx *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: tx}
+}
+
+func (r *invitationRepository) Create(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *invitationRepository) FindByID(id uint) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.First(&invitation, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// MarkAccepted records that the invitation was used to create the employee.
+// It reports false when the invitation was already accepted, revoked or has
+// expired, so that concurrent attempts cannot both use it.
+func (r *invitationRepository) MarkAccepted(id uint, employeeID uint, acceptedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", id, acceptedAt).
+		Updates(map[string]interface{}{
+			"accepted_at": acceptedAt,
+			"employee_id": employeeID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Revoke withdraws an invitation that has not been accepted yet. It reports
+// false when the invitation was already accepted or revoked.
+func (r *invitationRepository) Revoke(id uint, revokedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
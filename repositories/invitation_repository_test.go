@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvitationMarkAccepted(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		mockSetup    func(sqlmock.Sqlmock)
+		wantAccepted bool
+		wantError    bool
+	}{
+		{
+			name: "open invitation is accepted",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `invitations` SET `accepted_at`=\\?,`employee_id`=\\?,`updated_at`=\\? WHERE id = \\? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > \\?").
+					WithArgs(now, 5, sqlmock.AnyArg(), 3, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantAccepted: true,
+		},
+		{
+			name: "used invitation is not accepted again",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `invitations`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantAccepted: false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `invitations`").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewInvitationRepository(db)
+			accepted, err := repo.MarkAccepted(3, 5, now)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantAccepted, accepted)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestInvitationRevoke(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `invitations` SET `revoked_at`=\\?,`updated_at`=\\? WHERE id = \\? AND accepted_at IS NULL AND revoked_at IS NULL").
+		WithArgs(now, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewInvitationRepository(db)
+	revoked, err := repo.Revoke(3, now)
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
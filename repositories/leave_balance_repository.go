@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LeaveBalanceRepository interface {
+	WithTx(tx *gorm.DB) LeaveBalanceRepository
+	FindByEmployeeAndYear(employeeID uint, year int) ([]models.LeaveBalance, error)
+	FindByEmployeeTypeAndYear(employeeID uint, leaveType string, year int) (*models.LeaveBalance, error)
+	FindOrCreate(employeeID uint, leaveType string, year int, entitledDays float64) (*models.LeaveBalance, error)
+	UpdateEntitledDays(balance *models.LeaveBalance, entitledDays float64) (bool, error)
+	RecordTransaction(balance *models.LeaveBalance, transaction *models.LeaveBalanceTransaction) error
+	OutstandingDebit(balanceID uint, leaveRequestID uint) (float64, error)
+}
+
+type leaveBalanceRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaveBalanceRepository(db *gorm.DB) LeaveBalanceRepository {
+	return &leaveBalanceRepository{db: db}
+}
+
+func (r *leaveBalanceRepository) WithTx(tx *gorm.DB) LeaveBalanceRepository {
+	return &leaveBalanceRepository{db: tx}
+}
+
+func (r *leaveBalanceRepository) FindByEmployeeAndYear(employeeID uint, year int) ([]models.LeaveBalance, error) {
+	var balances []models.LeaveBalance
+	err := r.db.Where("employee_id = ? AND year = ?", employeeID, year).
+		Order("type ASC").
+		Find(&balances).Error
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+func (r *leaveBalanceRepository) FindByEmployeeTypeAndYear(employeeID uint, leaveType string, year int) (*models.LeaveBalance, error) {
+	var balance models.LeaveBalance
+	err := r.db.Where("employee_id = ? AND type = ? AND year = ?", employeeID, leaveType, year).
+		First(&balance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// FindOrCreate returns the balance row for the given employee, leave type and
+// year, creating it with entitledDays when it does not exist yet. The row is
+// locked for update so concurrent debits against it are serialised.
+func (r *leaveBalanceRepository) FindOrCreate(employeeID uint, leaveType string, year int, entitledDays float64) (*models.LeaveBalance, error) {
+	balance := models.LeaveBalance{
+		EmployeeID: employeeID,
+		Type:       leaveType,
+		Year:       year,
+	}
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("employee_id = ? AND type = ? AND year = ?", employeeID, leaveType, year).
+		Attrs(models.LeaveBalance{EntitledDays: entitledDays}).
+		FirstOrCreate(&balance).Error
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// UpdateEntitledDays sets the entitlement of the balance unless more days
+// than entitledDays have already been used, in which case it reports false.
+// The check is part of the update so a concurrent debit cannot slip past it.
+func (r *leaveBalanceRepository) UpdateEntitledDays(balance *models.LeaveBalance, entitledDays float64) (bool, error) {
+	result := r.db.Model(&models.LeaveBalance{}).
+		Where("id = ? AND used_days <= ?", balance.ID, entitledDays).
+		Update("entitled_days", entitledDays)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	balance.EntitledDays = entitledDays
+	return true, nil
+}
+
+// RecordTransaction appends an entry to the balance ledger and applies it to
+// the running used_days total of the balance.
+func (r *leaveBalanceRepository) RecordTransaction(balance *models.LeaveBalance, transaction *models.LeaveBalanceTransaction) error {
+	delta := transaction.Days
+	if transaction.Kind == "credit" {
+		delta = -delta
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		transaction.LeaveBalanceID = balance.ID
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.LeaveBalance{}).
+			Where("id = ?", balance.ID).
+			Update("used_days", gorm.Expr("used_days + ?", delta)).Error
+		if err != nil {
+			return err
+		}
+
+		balance.UsedDays += delta
+		return nil
+	})
+}
+
+// OutstandingDebit returns the days debited from the balance for a leave
+// request that have not been credited back yet.
+func (r *leaveBalanceRepository) OutstandingDebit(balanceID uint, leaveRequestID uint) (float64, error) {
+	var days float64
+	err := r.db.Model(&models.LeaveBalanceTransaction{}).
+		Select("COALESCE(SUM(CASE WHEN kind = 'debit' THEN days ELSE -days END), 0)").
+		Where("leave_balance_id = ? AND leave_request_id = ?", balanceID, leaveRequestID).
+		Scan(&days).Error
+	if err != nil {
+		return 0, err
+	}
+	return days, nil
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"database/sql"
+	"hr-leave-request/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaveBalanceFindByEmployeeAndYear(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "balances found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "employee_id", "type", "year", "entitled_days", "used_days", "created_at", "updated_at"}).
+					AddRow(1, 1, "sick", 2026, 10, 0, now, now).
+					AddRow(2, 1, "vacation", 2026, 12, 3, now, now)
+				mock.ExpectQuery("SELECT \\* FROM `leave_balances` WHERE").
+					WithArgs(1, 2026).
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `leave_balances` WHERE").
+					WithArgs(1, 2026).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewLeaveBalanceRepository(db)
+			results, err := repo.FindByEmployeeAndYear(1, 2026)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedCount, len(results))
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLeaveBalanceUpdateEntitledDays(t *testing.T) {
+	tests := []struct {
+		name         string
+		entitledDays float64
+		mockSetup    func(sqlmock.Sqlmock)
+		wantUpdated  bool
+		wantEntitled float64
+		wantError    bool
+	}{
+		{
+			name:         "entitlement updated",
+			entitledDays: 8,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `leave_balances` SET `entitled_days`=\\?,`updated_at`=\\? WHERE id = \\? AND used_days <= \\?").
+					WithArgs(float64(8), sqlmock.AnyArg(), 1, float64(8)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantUpdated:  true,
+			wantEntitled: 8,
+		},
+		{
+			name:         "more days used than the new entitlement",
+			entitledDays: 1,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `leave_balances` SET `entitled_days`=\\?").
+					WithArgs(float64(1), sqlmock.AnyArg(), 1, float64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantUpdated:  false,
+			wantEntitled: 12,
+		},
+		{
+			name:         "database error",
+			entitledDays: 8,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `leave_balances` SET `entitled_days`=\\?").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantEntitled: 12,
+			wantError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 2}
+			repo := NewLeaveBalanceRepository(db)
+			updated, err := repo.UpdateEntitledDays(balance, tt.entitledDays)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantUpdated, updated)
+			assert.Equal(t, tt.wantEntitled, balance.EntitledDays)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLeaveBalanceRecordTransaction(t *testing.T) {
+	leaveRequestID := uint(5)
+
+	tests := []struct {
+		name         string
+		transaction  *models.LeaveBalanceTransaction
+		mockSetup    func(sqlmock.Sqlmock)
+		wantUsedDays float64
+		wantError    bool
+	}{
+		{
+			name: "debit increases used days",
+			transaction: &models.LeaveBalanceTransaction{
+				LeaveRequestID: &leaveRequestID,
+				Kind:           "debit",
+				Days:           3,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `leave_balance_transactions`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `leave_balances` SET `used_days`=used_days \\+ \\?").
+					WithArgs(float64(3), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantUsedDays: 5,
+			wantError:    false,
+		},
+		{
+			name: "credit decreases used days",
+			transaction: &models.LeaveBalanceTransaction{
+				LeaveRequestID: &leaveRequestID,
+				Kind:           "credit",
+				Days:           2,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `leave_balance_transactions`").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE `leave_balances` SET `used_days`=used_days \\+ \\?").
+					WithArgs(float64(-2), sqlmock.AnyArg(), 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantUsedDays: 0,
+			wantError:    false,
+		},
+		{
+			name: "ledger insert fails",
+			transaction: &models.LeaveBalanceTransaction{
+				Kind: "debit",
+				Days: 1,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `leave_balance_transactions`").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantUsedDays: 2,
+			wantError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 2}
+			repo := NewLeaveBalanceRepository(db)
+			err := repo.RecordTransaction(balance, tt.transaction)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), tt.transaction.LeaveBalanceID)
+			}
+			assert.Equal(t, tt.wantUsedDays, balance.UsedDays)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLeaveBalanceOutstandingDebit(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(CASE WHEN kind = 'debit' THEN days ELSE -days END\\), 0\\) FROM `leave_balance_transactions` WHERE leave_balance_id = \\? AND leave_request_id = \\?").
+		WithArgs(1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"days"}).AddRow(1.5))
+
+	repo := NewLeaveBalanceRepository(db)
+	days, err := repo.OutstandingDebit(1, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, days)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
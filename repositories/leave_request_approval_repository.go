@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveRequestApprovalRepository interface {
+	WithTx(tx *gorm.DB) LeaveRequestApprovalRepository
+	CreateSteps(steps []models.LeaveRequestApproval) error
+	Update(step *models.LeaveRequestApproval) error
+	FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestApproval, error)
+	SupersedeOpenSteps(leaveRequestID uint) error
+}
+
+type leaveRequestApprovalRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaveRequestApprovalRepository(db *gorm.DB) LeaveRequestApprovalRepository {
+	return &leaveRequestApprovalRepository{db: db}
+}
+
+func (r *leaveRequestApprovalRepository) WithTx(tx *gorm.DB) LeaveRequestApprovalRepository {
+	return &leaveRequestApprovalRepository{db: tx}
+}
+
+func (r *leaveRequestApprovalRepository) CreateSteps(steps []models.LeaveRequestApproval) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	return r.db.Create(&steps).Error
+}
+
+func (r *leaveRequestApprovalRepository) Update(step *models.LeaveRequestApproval) error {
+	return r.db.Save(step).Error
+}
+
+// FindByLeaveRequestID returns the current approval chain of a leave request
+// in step order, leaving out superseded steps.
+func (r *leaveRequestApprovalRepository) FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestApproval, error) {
+	var steps []models.LeaveRequestApproval
+	err := r.db.Where("leave_request_id = ? AND status <> ?", leaveRequestID, "superseded").
+		Order("step ASC").
+		Find(&steps).Error
+	if err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// SupersedeOpenSteps retires the pending and approved steps of a leave request
+// so a fresh approval chain can be started.
+func (r *leaveRequestApprovalRepository) SupersedeOpenSteps(leaveRequestID uint) error {
+	return r.db.Model(&models.LeaveRequestApproval{}).
+		Where("leave_request_id = ? AND status IN ?", leaveRequestID, []string{"pending", "approved"}).
+		Update("status", "superseded").Error
+}
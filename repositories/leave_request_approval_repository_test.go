@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLeaveRequestApprovalsByLeaveRequestID(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "current chain in step order",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "leave_request_id", "step", "approver", "status", "created_at", "updated_at"}).
+					AddRow(3, 7, 1, "line_manager", "approved", now, now).
+					AddRow(4, 7, 2, "hr", "pending", now, now)
+				mock.ExpectQuery("SELECT \\* FROM `leave_request_approvals` WHERE leave_request_id = \\? AND status <> \\? ORDER BY step ASC").
+					WithArgs(7, "superseded").
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `leave_request_approvals`").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewLeaveRequestApprovalRepository(db)
+			steps, err := repo.FindByLeaveRequestID(7)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, steps, tt.expectedCount)
+				assert.Equal(t, "hr", steps[1].Approver)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSupersedeOpenSteps(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `leave_request_approvals` SET `status`=\\?,`updated_at`=\\? WHERE leave_request_id = \\? AND status IN \\(\\?,\\?\\)").
+		WithArgs("superseded", sqlmock.AnyArg(), 7, "pending", "approved").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	repo := NewLeaveRequestApprovalRepository(db)
+	err := repo.SupersedeOpenSteps(7)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveRequestEventRepository interface {
+	WithTx(tx *gorm.DB) LeaveRequestEventRepository
+	Create(event *models.LeaveRequestEvent) error
+	FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestEvent, error)
+}
+
+type leaveRequestEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaveRequestEventRepository(db *gorm.DB) LeaveRequestEventRepository {
+	return &leaveRequestEventRepository{db: db}
+}
+
+func (r *leaveRequestEventRepository) WithTx(tx *gorm.DB) LeaveRequestEventRepository {
+	return &leaveRequestEventRepository{db: tx}
+}
+
+func (r *leaveRequestEventRepository) Create(event *models.LeaveRequestEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindByLeaveRequestID returns the audit trail of a leave request, oldest first.
+func (r *leaveRequestEventRepository) FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestEvent, error) {
+	var events []models.LeaveRequestEvent
+	err := r.db.Where("leave_request_id = ?", leaveRequestID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
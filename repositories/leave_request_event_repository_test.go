@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindLeaveRequestEventsByLeaveRequestID(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "events ordered oldest first",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "leave_request_id", "actor_id", "action", "changes", "created_at"}).
+					AddRow(1, 7, 1, "created", `{}`, now).
+					AddRow(2, 7, 2, "approved", `{"status":{"from":"pending","to":"approved"}}`, now)
+				mock.ExpectQuery("SELECT \\* FROM `leave_request_events` WHERE leave_request_id = \\? ORDER BY created_at ASC, id ASC").
+					WithArgs(7).
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `leave_request_events`").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewLeaveRequestEventRepository(db)
+			events, err := repo.FindByLeaveRequestID(7)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, events, tt.expectedCount)
+				assert.Equal(t, "approved", events[1].Action)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
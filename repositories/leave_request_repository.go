@@ -8,12 +8,15 @@ import (
 )
 
 type LeaveRequestRepository interface {
+	WithTx(tx *gorm.DB) LeaveRequestRepository
 	Create(leaveRequest *models.LeaveRequest) error
 	FindByID(id uint) (*models.LeaveRequest, error)
-	FindAll(page, pageSize int, employeeID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error)
+	FindAll(page, pageSize int, employeeID, managerID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error)
+	FindByEmployeeAndStatus(employeeID uint, status string) ([]models.LeaveRequest, error)
 	Update(leaveRequest *models.LeaveRequest) error
 	Delete(id uint) error
 	HasOverlappingApprovedLeave(employeeID uint, startDate, endDate time.Time, excludeID *uint) (bool, error)
+	FindPendingApproval(approverID uint, approverRole string, includeUnmanaged bool, page, pageSize int) ([]models.LeaveRequest, int64, error)
 }
 
 type leaveRequestRepository struct {
@@ -24,6 +27,10 @@ func NewLeaveRequestRepository(db *gorm.DB) LeaveRequestRepository {
 	return &leaveRequestRepository{db: db}
 }
 
+func (r *leaveRequestRepository) WithTx(tx *gorm.DB) LeaveRequestRepository {
+	return &leaveRequestRepository{db: tx}
+}
+
 func (r *leaveRequestRepository) Create(leaveRequest *models.LeaveRequest) error {
 	return r.db.Create(leaveRequest).Error
 }
@@ -37,7 +44,10 @@ func (r *leaveRequestRepository) FindByID(id uint) (*models.LeaveRequest, error)
 	return &leaveRequest, nil
 }
 
-func (r *leaveRequestRepository) FindAll(page, pageSize int, employeeID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error) {
+// FindAll lists leave requests matching the filters. A non-nil managerID
+// restricts the results to that manager's own requests and those of their
+// direct reports.
+func (r *leaveRequestRepository) FindAll(page, pageSize int, employeeID, managerID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error) {
 	var leaveRequests []models.LeaveRequest
 	var total int64
 
@@ -47,6 +57,10 @@ func (r *leaveRequestRepository) FindAll(page, pageSize int, employeeID *uint, s
 	if employeeID != nil {
 		query = query.Where("employee_id = ?", *employeeID)
 	}
+	if managerID != nil {
+		query = query.Where("employee_id = ? OR employee_id IN (?)", *managerID,
+			r.db.Model(&models.Employee{}).Select("id").Where("manager_id = ?", *managerID))
+	}
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
@@ -79,6 +93,19 @@ func (r *leaveRequestRepository) FindAll(page, pageSize int, employeeID *uint, s
 	return leaveRequests, total, nil
 }
 
+// FindByEmployeeAndStatus returns all leave requests of an employee in the
+// given status, oldest first.
+func (r *leaveRequestRepository) FindByEmployeeAndStatus(employeeID uint, status string) ([]models.LeaveRequest, error) {
+	var leaveRequests []models.LeaveRequest
+	err := r.db.Where("employee_id = ? AND status = ?", employeeID, status).
+		Order("created_at ASC, id ASC").
+		Find(&leaveRequests).Error
+	if err != nil {
+		return nil, err
+	}
+	return leaveRequests, nil
+}
+
 func (r *leaveRequestRepository) Update(leaveRequest *models.LeaveRequest) error {
 	return r.db.Save(leaveRequest).Error
 }
@@ -89,21 +116,17 @@ func (r *leaveRequestRepository) Delete(id uint) error {
 
 // HasOverlappingApprovedLeave checks if there are any approved leave requests
 // that overlap with the given date range for the specified employee
+// Requests awaiting cancellation still count as approved
+// Periods that merely touch (e.g. a morning and an afternoon half day on the
+// same date) do not overlap
 // excludeID is used to exclude a specific leave request (useful for updates)
 func (r *leaveRequestRepository) HasOverlappingApprovedLeave(employeeID uint, startDate, endDate time.Time, excludeID *uint) (bool, error) {
 	var count int64
 
 	query := r.db.Model(&models.LeaveRequest{}).
 		Where("employee_id = ?", employeeID).
-		Where("status = ?", "approved").
-		Where(
-			"(start_date <= ? AND end_date >= ?) OR "+
-				"(start_date <= ? AND end_date >= ?) OR "+
-				"(start_date >= ? AND end_date <= ?)",
-			endDate, endDate, // New request ends during existing leave
-			startDate, startDate, // New request starts during existing leave
-			startDate, endDate, // Existing leave is completely within new request
-		)
+		Where("status IN ?", []string{"approved", "cancellation_requested"}).
+		Where("start_date < ? AND end_date > ?", endDate, startDate)
 
 	// Exclude a specific leave request if provided (for update operations)
 	if excludeID != nil {
@@ -116,3 +139,49 @@ func (r *leaveRequestRepository) HasOverlappingApprovedLeave(employeeID uint, st
 
 	return count > 0, nil
 }
+
+// FindPendingApproval returns the leave requests awaiting a decision from the
+// approver: pending requests whose current approval step names the approver's
+// role, or their line manager when the approver manages the employee, and the
+// cancellation requests of their direct reports. With includeUnmanaged, the
+// approver also covers the line manager of employees without one. Requests
+// submitted before approval chains existed have no steps and wait on the line
+// manager. The approver's own requests are never included.
+func (r *leaveRequestRepository) FindPendingApproval(approverID uint, approverRole string, includeUnmanaged bool, page, pageSize int) ([]models.LeaveRequest, int64, error) {
+	var leaveRequests []models.LeaveRequest
+	var total int64
+
+	managedByApprover := "employees.manager_id = ?"
+	if includeUnmanaged {
+		managedByApprover = "(employees.manager_id = ? OR employees.manager_id IS NULL)"
+	}
+
+	query := r.db.Model(&models.LeaveRequest{}).
+		Joins("JOIN employees ON employees.id = leave_requests.employee_id AND employees.deleted_at IS NULL").
+		Joins("LEFT JOIN leave_request_approvals ON leave_request_approvals.leave_request_id = leave_requests.id"+
+			" AND leave_request_approvals.status = 'pending'"+
+			" AND leave_request_approvals.step = (SELECT MIN(current_step.step) FROM leave_request_approvals current_step"+
+			" WHERE current_step.leave_request_id = leave_requests.id AND current_step.status = 'pending')").
+		Where("leave_requests.employee_id <> ?", approverID).
+		Where("(leave_requests.status = ? AND (leave_request_approvals.approver = ? OR ((leave_request_approvals.approver = ? OR leave_request_approvals.id IS NULL) AND "+managedByApprover+")))"+
+			" OR (leave_requests.status = ? AND "+managedByApprover+")",
+			"pending", approverRole, "line_manager", approverID, "cancellation_requested", approverID)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Earliest leave first, as those decisions are the most urgent
+	offset := (page - 1) * pageSize
+	err := query.Order("leave_requests.start_date ASC").
+		Limit(pageSize).
+		Offset(offset).
+		Preload("Employee").
+		Find(&leaveRequests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return leaveRequests, total, nil
+}
@@ -184,6 +184,7 @@ func TestLeaveRequestFindAll(t *testing.T) {
 		page          int
 		pageSize      int
 		employeeID    *uint
+		managerID     *uint
 		status        *string
 		leaveType     *string
 		startDate     *time.Time
@@ -262,6 +263,26 @@ func TestLeaveRequestFindAll(t *testing.T) {
 			expectedTotal: 1,
 			wantError:     false,
 		},
+		{
+			name:      "scoped to a manager and their reports",
+			page:      1,
+			pageSize:  10,
+			managerID: &employeeID,
+			sortBy:    "created_at",
+			sortDir:   "desc",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests` WHERE \\(employee_id = \\? OR employee_id IN \\(SELECT `id` FROM `employees` WHERE manager_id = \\? AND `employees`.`deleted_at` IS NULL\\)\\)").
+					WithArgs(employeeID, employeeID).
+					WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+
+				mock.ExpectQuery("SELECT \\* FROM `leave_requests` WHERE \\(employee_id = \\? OR employee_id IN").
+					WithArgs(employeeID, employeeID, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			expectedCount: 0,
+			expectedTotal: 0,
+			wantError:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -272,7 +293,7 @@ func TestLeaveRequestFindAll(t *testing.T) {
 			tt.mockSetup(mock)
 
 			repo := NewLeaveRequestRepository(db)
-			results, total, err := repo.FindAll(tt.page, tt.pageSize, tt.employeeID, tt.status, tt.leaveType, tt.startDate, tt.endDate, tt.sortBy, tt.sortDir)
+			results, total, err := repo.FindAll(tt.page, tt.pageSize, tt.employeeID, tt.managerID, tt.status, tt.leaveType, tt.startDate, tt.endDate, tt.sortBy, tt.sortDir)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -287,6 +308,27 @@ func TestLeaveRequestFindAll(t *testing.T) {
 	}
 }
 
+func TestLeaveRequestFindByEmployeeAndStatus(t *testing.T) {
+	db, mock, cleanup := setupLeaveRequestMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "employee_id", "status", "created_at"}).
+		AddRow(3, 7, "pending", now).
+		AddRow(5, 7, "pending", now)
+	mock.ExpectQuery("SELECT \\* FROM `leave_requests` WHERE \\(employee_id = \\? AND status = \\?\\) AND `leave_requests`.`deleted_at` IS NULL ORDER BY created_at ASC, id ASC").
+		WithArgs(7, "pending").
+		WillReturnRows(rows)
+
+	repo := NewLeaveRequestRepository(db)
+	results, err := repo.FindByEmployeeAndStatus(7, "pending")
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, uint(3), results[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestLeaveRequestUpdate(t *testing.T) {
 	now := time.Now()
 	reason := "Updated reason"
@@ -434,7 +476,7 @@ func TestHasOverlappingApprovedLeave(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(1)
 				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests`").
-					WithArgs(1, "approved", endDate, endDate, startDate, startDate, startDate, endDate).
+					WithArgs(1, "approved", "cancellation_requested", endDate, startDate).
 					WillReturnRows(countRows)
 			},
 			wantResult: true,
@@ -449,7 +491,7 @@ func TestHasOverlappingApprovedLeave(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(0)
 				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests`").
-					WithArgs(1, "approved", endDate, endDate, startDate, startDate, startDate, endDate).
+					WithArgs(1, "approved", "cancellation_requested", endDate, startDate).
 					WillReturnRows(countRows)
 			},
 			wantResult: false,
@@ -464,7 +506,7 @@ func TestHasOverlappingApprovedLeave(t *testing.T) {
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(0)
 				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests`").
-					WithArgs(1, "approved", endDate, endDate, startDate, startDate, startDate, endDate, excludeID).
+					WithArgs(1, "approved", "cancellation_requested", endDate, startDate, excludeID).
 					WillReturnRows(countRows)
 			},
 			wantResult: false,
@@ -506,3 +548,73 @@ func TestHasOverlappingApprovedLeave(t *testing.T) {
 		})
 	}
 }
+
+func TestFindPendingApproval(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name             string
+		approverRole     string
+		includeUnmanaged bool
+		mockSetup        func(sqlmock.Sqlmock)
+		expectedCount    int
+		wantError        bool
+	}{
+		{
+			name:         "current step of direct reports",
+			approverRole: "manager",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				countRows := sqlmock.NewRows([]string{"count(*)"}).AddRow(1)
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests` JOIN employees .* LEFT JOIN leave_request_approvals .* WHERE leave_requests.employee_id <> \\? AND \\(\\(leave_requests.status = \\? AND \\(leave_request_approvals.approver = \\? OR .*employees.manager_id = \\?\\)\\)\\) OR \\(leave_requests.status = \\? AND employees.manager_id = \\?\\)").
+					WithArgs(9, "pending", "manager", "line_manager", 9, "cancellation_requested", 9).
+					WillReturnRows(countRows)
+
+				rows := sqlmock.NewRows([]string{"id", "employee_id", "start_date", "end_date", "type", "status", "created_at", "updated_at"}).
+					AddRow(1, 1, now, now, "vacation", "pending", now, now)
+				mock.ExpectQuery("SELECT `leave_requests`.`id`.* FROM `leave_requests` JOIN employees .* ORDER BY leave_requests.start_date ASC LIMIT \\?").
+					WithArgs(9, "pending", "manager", "line_manager", 9, "cancellation_requested", 9, 10).
+					WillReturnRows(rows)
+
+				employeeRows := sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "John Doe", "john@example.com")
+				mock.ExpectQuery("SELECT \\* FROM `employees`").
+					WithArgs(1).
+					WillReturnRows(employeeRows)
+			},
+			expectedCount: 1,
+			wantError:     false,
+		},
+		{
+			name:             "hr includes employees without a manager",
+			approverRole:     "hr",
+			includeUnmanaged: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT count\\(\\*\\) FROM `leave_requests` JOIN employees .* OR \\(leave_requests.status = \\? AND \\(employees.manager_id = \\? OR employees.manager_id IS NULL\\)\\)").
+					WithArgs(9, "pending", "hr", "line_manager", 9, "cancellation_requested", 9).
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupLeaveRequestMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewLeaveRequestRepository(db)
+			results, total, err := repo.FindPendingApproval(9, tt.approverRole, tt.includeUnmanaged, 1, 10)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(tt.expectedCount), total)
+				assert.Len(t, results, tt.expectedCount)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+)
+
+type LoginLockoutRepository interface {
+	Create(lockout *models.LoginLockout) error
+}
+
+type loginLockoutRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginLockoutRepository(db *gorm.DB) LoginLockoutRepository {
+	return &loginLockoutRepository{db: db}
+}
+
+func (r *loginLockoutRepository) Create(lockout *models.LoginLockout) error {
+	return r.db.Create(lockout).Error
+}
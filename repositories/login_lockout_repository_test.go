@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLockoutCreate(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	email := "john@example.com"
+	employeeID := uint(5)
+	lockedUntil := time.Now().Add(15 * time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `login_lockouts`").
+		WithArgs("account", &email, "10.0.0.1", &employeeID, 5, lockedUntil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	repo := NewLoginLockoutRepository(db)
+	err := repo.Create(&models.LoginLockout{
+		Scope:       "account",
+		Email:       &email,
+		IPAddress:   "10.0.0.1",
+		EmployeeID:  &employeeID,
+		Attempts:    5,
+		LockedUntil: lockedUntil,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MFAEnrollmentRepository interface {
+	WithTx(tx *gorm.DB) MFAEnrollmentRepository
+	Create(enrollment *models.MFAEnrollment) error
+	FindByEmployee(employeeID uint) (*models.MFAEnrollment, error)
+	Confirm(id uint, step int64, confirmedAt time.Time) (bool, error)
+	UseStep(id uint, step int64) (bool, error)
+	DeleteByEmployee(employeeID uint) error
+}
+
+type mfaEnrollmentRepository struct {
+	db *gorm.DB
+}
+
+func NewMFAEnrollmentRepository(db *gorm.DB) MFAEnrollmentRepository {
+	return &mfaEnrollmentRepository{db: db}
+}
+
+func (r *mfaEnrollmentRepository) WithTx(tx *gorm.DB) MFAEnrollmentRepository {
+	return &mfaEnrollmentRepository{db: tx}
+}
+
+func (r *mfaEnrollmentRepository) Create(enrollment *models.MFAEnrollment) error {
+	return r.db.Create(enrollment).Error
+}
+
+func (r *mfaEnrollmentRepository) FindByEmployee(employeeID uint) (*models.MFAEnrollment, error) {
+	var enrollment models.MFAEnrollment
+	err := r.db.Where("employee_id = ?", employeeID).First(&enrollment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &enrollment, nil
+}
+
+// Confirm enables the enrollment with the time step of its first code. It
+// reports false when the enrollment was already confirmed.
+func (r *mfaEnrollmentRepository) Confirm(id uint, step int64, confirmedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.MFAEnrollment{}).
+		Where("id = ? AND confirmed_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"confirmed_at":   confirmedAt,
+			"last_used_step": step,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseStep records that the code of the time step was used. It reports false
+// when a code of that step or a later one was already used, so that
+// concurrent logins cannot replay the same code.
+func (r *mfaEnrollmentRepository) UseStep(id uint, step int64) (bool, error) {
+	result := r.db.Model(&models.MFAEnrollment{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *mfaEnrollmentRepository) DeleteByEmployee(employeeID uint) error {
+	return r.db.Where("employee_id = ?", employeeID).Delete(&models.MFAEnrollment{}).Error
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFAEnrollmentUseStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantUsed bool
+	}{
+		{name: "newer step is accepted", affected: 1, wantUsed: true},
+		{name: "replayed step is refused", affected: 0, wantUsed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE `mfa_enrollments` SET `last_used_step`=\\?,`updated_at`=\\? WHERE id = \\? AND last_used_step < \\?").
+				WithArgs(int64(100), sqlmock.AnyArg(), 1, int64(100)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			mock.ExpectCommit()
+
+			repo := NewMFAEnrollmentRepository(db)
+			used, err := repo.UseStep(1, 100)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUsed, used)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MFARecoveryCodeRepository interface {
+	WithTx(tx *gorm.DB) MFARecoveryCodeRepository
+	CreateBatch(codes []models.MFARecoveryCode) error
+	MarkUsed(employeeID uint, codeHash string, usedAt time.Time) (bool, error)
+	DeleteByEmployee(employeeID uint) error
+}
+
+type mfaRecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARecoveryCodeRepository(db *gorm.DB) MFARecoveryCodeRepository {
+	return &mfaRecoveryCodeRepository{db: db}
+}
+
+func (r *mfaRecoveryCodeRepository) WithTx(tx *gorm.DB) MFARecoveryCodeRepository {
+	return &mfaRecoveryCodeRepository{db: tx}
+}
+
+func (r *mfaRecoveryCodeRepository) CreateBatch(codes []models.MFARecoveryCode) error {
+	return r.db.Create(&codes).Error
+}
+
+// MarkUsed consumes the employee's recovery code with the given hash. It
+// reports false when there is no such unused code.
+func (r *mfaRecoveryCodeRepository) MarkUsed(employeeID uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.MFARecoveryCode{}).
+		Where("employee_id = ? AND code_hash = ? AND used_at IS NULL", employeeID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *mfaRecoveryCodeRepository) DeleteByEmployee(employeeID uint) error {
+	return r.db.Where("employee_id = ?", employeeID).Delete(&models.MFARecoveryCode{}).Error
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFARecoveryCodeMarkUsed(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `mfa_recovery_codes` SET `used_at`=\\? WHERE employee_id = \\? AND code_hash = \\? AND used_at IS NULL").
+		WithArgs(now, 1, "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewMFARecoveryCodeRepository(db)
+	used, err := repo.MarkUsed(1, "hash", now)
+
+	assert.NoError(t, err)
+	assert.True(t, used)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,14 +2,20 @@ package mocks
 
 import (
 	"hr-leave-request/models"
+	"hr-leave-request/repositories"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockEmployeeRepository struct {
 	mock.Mock
 }
 
+func (m *MockEmployeeRepository) WithTx(tx *gorm.DB) repositories.EmployeeRepository {
+	return m
+}
+
 func (m *MockEmployeeRepository) Create(employee *models.Employee) error {
 	args := m.Called(employee)
 	return args.Error(0)
@@ -31,8 +37,24 @@ func (m *MockEmployeeRepository) FindByEmail(email string) (*models.Employee, er
 	return args.Get(0).(*models.Employee), args.Error(1)
 }
 
-func (m *MockEmployeeRepository) FindAll(page, pageSize int, search, sortBy, sortDir string) ([]models.Employee, int64, error) {
-	args := m.Called(page, pageSize, search, sortBy, sortDir)
+func (m *MockEmployeeRepository) FindByIDWithDeleted(id uint) (*models.Employee, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Employee), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) FindByEmailWithDeleted(email string) (*models.Employee, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Employee), args.Error(1)
+}
+
+func (m *MockEmployeeRepository) FindAll(page, pageSize int, search, sortBy, sortDir string, includeDeleted bool, visibleTo *uint) ([]models.Employee, int64, error) {
+	args := m.Called(page, pageSize, search, sortBy, sortDir, includeDeleted, visibleTo)
 	return args.Get(0).([]models.Employee), args.Get(1).(int64), args.Error(2)
 }
 
@@ -41,7 +63,22 @@ func (m *MockEmployeeRepository) Update(employee *models.Employee) error {
 	return args.Error(0)
 }
 
+func (m *MockEmployeeRepository) UpdateManager(id uint, managerID *uint) error {
+	args := m.Called(id, managerID)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRepository) UpdateProfile(employee *models.Employee) error {
+	args := m.Called(employee)
+	return args.Error(0)
+}
+
 func (m *MockEmployeeRepository) Delete(id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
+
+func (m *MockEmployeeRepository) Restore(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
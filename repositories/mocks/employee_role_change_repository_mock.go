@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockEmployeeRoleChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmployeeRoleChangeRepository) WithTx(tx *gorm.DB) repositories.EmployeeRoleChangeRepository {
+	return m
+}
+
+func (m *MockEmployeeRoleChangeRepository) Create(change *models.EmployeeRoleChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockEmployeeRoleChangeRepository) FindByEmployeeID(employeeID uint) ([]models.EmployeeRoleChange, error) {
+	args := m.Called(employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EmployeeRoleChange), args.Error(1)
+}
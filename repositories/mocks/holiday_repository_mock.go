@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockHolidayRepository struct {
+	mock.Mock
+}
+
+func (m *MockHolidayRepository) Create(holiday *models.Holiday) error {
+	args := m.Called(holiday)
+	return args.Error(0)
+}
+
+func (m *MockHolidayRepository) FindByID(id uint) (*models.Holiday, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Holiday), args.Error(1)
+}
+
+func (m *MockHolidayRepository) FindByDate(date time.Time) (*models.Holiday, error) {
+	args := m.Called(date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Holiday), args.Error(1)
+}
+
+func (m *MockHolidayRepository) FindBetween(startDate, endDate time.Time) ([]models.Holiday, error) {
+	args := m.Called(startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Holiday), args.Error(1)
+}
+
+func (m *MockHolidayRepository) Update(holiday *models.Holiday) error {
+	args := m.Called(holiday)
+	return args.Error(0)
+}
+
+func (m *MockHolidayRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockHolidayRepository) Upsert(holidays []models.Holiday) error {
+	args := m.Called(holidays)
+	return args.Error(0)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) WithTx(tx *gorm.DB) repositories.InvitationRepository {
+	return m
+}
+
+func (m *MockInvitationRepository) Create(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) FindByID(id uint) (*models.Invitation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Invitation), args.Error(1)
+}
+
+func (m *MockInvitationRepository) MarkAccepted(id uint, employeeID uint, acceptedAt time.Time) (bool, error) {
+	args := m.Called(id, employeeID, acceptedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInvitationRepository) Revoke(id uint, revokedAt time.Time) (bool, error) {
+	args := m.Called(id, revokedAt)
+	return args.Bool(0), args.Error(1)
+}
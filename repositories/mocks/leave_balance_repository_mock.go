@@ -0,0 +1,56 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockLeaveBalanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockLeaveBalanceRepository) WithTx(tx *gorm.DB) repositories.LeaveBalanceRepository {
+	return m
+}
+
+func (m *MockLeaveBalanceRepository) FindByEmployeeAndYear(employeeID uint, year int) ([]models.LeaveBalance, error) {
+	args := m.Called(employeeID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveBalance), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepository) FindByEmployeeTypeAndYear(employeeID uint, leaveType string, year int) (*models.LeaveBalance, error) {
+	args := m.Called(employeeID, leaveType, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LeaveBalance), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepository) FindOrCreate(employeeID uint, leaveType string, year int, entitledDays float64) (*models.LeaveBalance, error) {
+	args := m.Called(employeeID, leaveType, year, entitledDays)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LeaveBalance), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepository) UpdateEntitledDays(balance *models.LeaveBalance, entitledDays float64) (bool, error) {
+	args := m.Called(balance, entitledDays)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLeaveBalanceRepository) RecordTransaction(balance *models.LeaveBalance, transaction *models.LeaveBalanceTransaction) error {
+	args := m.Called(balance, transaction)
+	return args.Error(0)
+}
+
+func (m *MockLeaveBalanceRepository) OutstandingDebit(balanceID uint, leaveRequestID uint) (float64, error) {
+	args := m.Called(balanceID, leaveRequestID)
+	return args.Get(0).(float64), args.Error(1)
+}
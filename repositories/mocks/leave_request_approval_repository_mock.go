@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockLeaveRequestApprovalRepository struct {
+	mock.Mock
+}
+
+func (m *MockLeaveRequestApprovalRepository) WithTx(tx *gorm.DB) repositories.LeaveRequestApprovalRepository {
+	return m
+}
+
+func (m *MockLeaveRequestApprovalRepository) CreateSteps(steps []models.LeaveRequestApproval) error {
+	args := m.Called(steps)
+	return args.Error(0)
+}
+
+func (m *MockLeaveRequestApprovalRepository) Update(step *models.LeaveRequestApproval) error {
+	args := m.Called(step)
+	return args.Error(0)
+}
+
+func (m *MockLeaveRequestApprovalRepository) FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestApproval, error) {
+	args := m.Called(leaveRequestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveRequestApproval), args.Error(1)
+}
+
+func (m *MockLeaveRequestApprovalRepository) SupersedeOpenSteps(leaveRequestID uint) error {
+	args := m.Called(leaveRequestID)
+	return args.Error(0)
+}
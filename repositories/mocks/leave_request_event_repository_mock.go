@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockLeaveRequestEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockLeaveRequestEventRepository) WithTx(tx *gorm.DB) repositories.LeaveRequestEventRepository {
+	return m
+}
+
+func (m *MockLeaveRequestEventRepository) Create(event *models.LeaveRequestEvent) error {
+	args := m.Called(event)
+	return args.Error(0)
+}
+
+func (m *MockLeaveRequestEventRepository) FindByLeaveRequestID(leaveRequestID uint) ([]models.LeaveRequestEvent, error) {
+	args := m.Called(leaveRequestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveRequestEvent), args.Error(1)
+}
@@ -2,15 +2,21 @@ package mocks
 
 import (
 	"hr-leave-request/models"
+	"hr-leave-request/repositories"
 	"time"
 
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 type MockLeaveRequestRepository struct {
 	mock.Mock
 }
 
+func (m *MockLeaveRequestRepository) WithTx(tx *gorm.DB) repositories.LeaveRequestRepository {
+	return m
+}
+
 func (m *MockLeaveRequestRepository) Create(leaveRequest *models.LeaveRequest) error {
 	args := m.Called(leaveRequest)
 	return args.Error(0)
@@ -24,8 +30,8 @@ func (m *MockLeaveRequestRepository) FindByID(id uint) (*models.LeaveRequest, er
 	return args.Get(0).(*models.LeaveRequest), args.Error(1)
 }
 
-func (m *MockLeaveRequestRepository) FindAll(page, pageSize int, employeeID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error) {
-	args := m.Called(page, pageSize, employeeID, status, leaveType, startDate, endDate, sortBy, sortDir)
+func (m *MockLeaveRequestRepository) FindAll(page, pageSize int, employeeID, managerID *uint, status, leaveType *string, startDate, endDate *time.Time, sortBy, sortDir string) ([]models.LeaveRequest, int64, error) {
+	args := m.Called(page, pageSize, employeeID, managerID, status, leaveType, startDate, endDate, sortBy, sortDir)
 	return args.Get(0).([]models.LeaveRequest), args.Get(1).(int64), args.Error(2)
 }
 
@@ -43,3 +49,13 @@ func (m *MockLeaveRequestRepository) HasOverlappingApprovedLeave(employeeID uint
 	args := m.Called(employeeID, startDate, endDate, excludeID)
 	return args.Bool(0), args.Error(1)
 }
+
+func (m *MockLeaveRequestRepository) FindByEmployeeAndStatus(employeeID uint, status string) ([]models.LeaveRequest, error) {
+	args := m.Called(employeeID, status)
+	return args.Get(0).([]models.LeaveRequest), args.Error(1)
+}
+
+func (m *MockLeaveRequestRepository) FindPendingApproval(approverID uint, approverRole string, includeUnmanaged bool, page, pageSize int) ([]models.LeaveRequest, int64, error) {
+	args := m.Called(approverID, approverRole, includeUnmanaged, page, pageSize)
+	return args.Get(0).([]models.LeaveRequest), args.Get(1).(int64), args.Error(2)
+}
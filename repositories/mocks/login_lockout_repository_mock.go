@@ -0,0 +1,16 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoginLockoutRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginLockoutRepository) Create(lockout *models.LoginLockout) error {
+	args := m.Called(lockout)
+	return args.Error(0)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockMFAEnrollmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFAEnrollmentRepository) WithTx(tx *gorm.DB) repositories.MFAEnrollmentRepository {
+	return m
+}
+
+func (m *MockMFAEnrollmentRepository) Create(enrollment *models.MFAEnrollment) error {
+	args := m.Called(enrollment)
+	return args.Error(0)
+}
+
+func (m *MockMFAEnrollmentRepository) FindByEmployee(employeeID uint) (*models.MFAEnrollment, error) {
+	args := m.Called(employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MFAEnrollment), args.Error(1)
+}
+
+func (m *MockMFAEnrollmentRepository) Confirm(id uint, step int64, confirmedAt time.Time) (bool, error) {
+	args := m.Called(id, step, confirmedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAEnrollmentRepository) UseStep(id uint, step int64) (bool, error) {
+	args := m.Called(id, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAEnrollmentRepository) DeleteByEmployee(employeeID uint) error {
+	args := m.Called(employeeID)
+	return args.Error(0)
+}
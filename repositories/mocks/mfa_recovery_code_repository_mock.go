@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockMFARecoveryCodeRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARecoveryCodeRepository) WithTx(tx *gorm.DB) repositories.MFARecoveryCodeRepository {
+	return m
+}
+
+func (m *MockMFARecoveryCodeRepository) CreateBatch(codes []models.MFARecoveryCode) error {
+	args := m.Called(codes)
+	return args.Error(0)
+}
+
+func (m *MockMFARecoveryCodeRepository) MarkUsed(employeeID uint, codeHash string, usedAt time.Time) (bool, error) {
+	args := m.Called(employeeID, codeHash, usedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARecoveryCodeRepository) DeleteByEmployee(employeeID uint) error {
+	args := m.Called(employeeID)
+	return args.Error(0)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) WithTx(tx *gorm.DB) repositories.RefreshTokenRepository {
+	return m
+}
+
+func (m *MockRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Rotate(id uint, replacedByID uint, revokedAt time.Time) (bool, error) {
+	args := m.Called(id, replacedByID, revokedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+	args := m.Called(familyID, revokedAt)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeByEmployee(employeeID uint, revokedAt time.Time) error {
+	args := m.Called(employeeID, revokedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,21 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) Create(token *models.RevokedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRevokedTokenRepository) IsRevoked(jti string) (bool, error) {
+	args := m.Called(jti)
+	return args.Bool(0), args.Error(1)
+}
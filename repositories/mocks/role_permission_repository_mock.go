@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"hr-leave-request/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRolePermissionRepository struct {
+	mock.Mock
+}
+
+func (m *MockRolePermissionRepository) FindByRole(role string) ([]models.RolePermission, error) {
+	args := m.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RolePermission), args.Error(1)
+}
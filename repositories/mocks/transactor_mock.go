@@ -0,0 +1,13 @@
+package mocks
+
+import (
+	"gorm.io/gorm"
+)
+
+// MockTransactor runs the unit of work directly without a database, relying on
+// the repository mocks ignoring the transaction handle passed to WithTx.
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	return fn(nil)
+}
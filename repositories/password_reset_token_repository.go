@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenRepository interface {
+	WithTx(tx *gorm.DB) PasswordResetTokenRepository
+	Create(token *models.PasswordResetToken) error
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint, usedAt time.Time) (bool, error)
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+func (r *passwordResetTokenRepository) WithTx(tx *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: tx}
+}
+
+func (r *passwordResetTokenRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetTokenRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the reset token. It reports false when the token was
+// already used or has expired, so that it cannot be used twice.
+func (r *passwordResetTokenRepository) MarkUsed(id uint, usedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, usedAt).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
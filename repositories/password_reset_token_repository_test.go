@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordResetTokenMarkUsed(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantUsed  bool
+		wantError bool
+	}{
+		{
+			name: "unused token is consumed",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `password_reset_tokens` SET `used_at`=\\? WHERE id = \\? AND used_at IS NULL AND expires_at > \\?").
+					WithArgs(now, 1, now).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantUsed: true,
+		},
+		{
+			name: "used or expired token is not consumed again",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `password_reset_tokens`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantUsed: false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `password_reset_tokens`").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewPasswordResetTokenRepository(db)
+			used, err := repo.MarkUsed(1, now)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantUsed, used)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
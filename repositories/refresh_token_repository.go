@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	WithTx(tx *gorm.DB) RefreshTokenRepository
+	Create(token *models.RefreshToken) error
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	Rotate(id uint, replacedByID uint, revokedAt time.Time) (bool, error)
+	RevokeFamily(familyID string, revokedAt time.Time) error
+	RevokeByEmployee(employeeID uint, revokedAt time.Time) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) WithTx(tx *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: tx}
+}
+
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *refreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate revokes the refresh token in favour of its replacement. It reports
+// false when the token was already revoked, so that two concurrent refreshes
+// cannot both succeed.
+func (r *refreshTokenRepository) Rotate(id uint, replacedByID uint, revokedAt time.Time) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":     revokedAt,
+			"replaced_by_id": replacedByID,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every refresh token descending from the same login.
+func (r *refreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RevokeByEmployee revokes every refresh token of the employee.
+func (r *refreshTokenRepository) RevokeByEmployee(employeeID uint, revokedAt time.Time) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("employee_id = ? AND revoked_at IS NULL", employeeID).
+		Update("revoked_at", revokedAt).Error
+}
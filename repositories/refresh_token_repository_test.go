@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRefreshTokenFindByHash(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		mockSetup func(sqlmock.Sqlmock)
+		wantError error
+	}{
+		{
+			name: "token found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "employee_id", "token_hash", "family_id", "expires_at", "created_at"}).
+					AddRow(1, 5, "hash", "family", now, now)
+				mock.ExpectQuery("SELECT \\* FROM `refresh_tokens` WHERE token_hash = \\? ORDER BY `refresh_tokens`.`id` LIMIT \\?").
+					WithArgs("hash", 1).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			name: "token not found",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `refresh_tokens`").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			wantError: gorm.ErrRecordNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewRefreshTokenRepository(db)
+			token, err := repo.FindByHash("hash")
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "family", token.FamilyID)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefreshTokenRotate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		mockSetup   func(sqlmock.Sqlmock)
+		wantRotated bool
+		wantError   bool
+	}{
+		{
+			name: "active token is rotated",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `refresh_tokens` SET `replaced_by_id`=\\?,`revoked_at`=\\? WHERE id = \\? AND revoked_at IS NULL").
+					WithArgs(2, now, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantRotated: true,
+		},
+		{
+			name: "revoked token is not rotated again",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `refresh_tokens`").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			wantRotated: false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `refresh_tokens`").
+					WillReturnError(sql.ErrConnDone)
+				mock.ExpectRollback()
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewRefreshTokenRepository(db)
+			rotated, err := repo.Rotate(1, 2, now)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantRotated, rotated)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRefreshTokenRevokeFamily(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `refresh_tokens` SET `revoked_at`=\\? WHERE family_id = \\? AND revoked_at IS NULL").
+		WithArgs(now, "family").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	repo := NewRefreshTokenRepository(db)
+	err := repo.RevokeFamily("family", now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokedTokenCreate(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `revoked_tokens` .* ON DUPLICATE KEY UPDATE `jti`=`jti`").
+		WithArgs("abc", 5, expiresAt, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewRevokedTokenRepository(db)
+	err := repo.Create(&models.RevokedToken{JTI: "abc", EmployeeID: 5, ExpiresAt: expiresAt})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokedTokenIsRevoked(t *testing.T) {
+	db, mock, cleanup := setupMockDB(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `revoked_tokens` WHERE jti = \\?").
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+
+	repo := NewRevokedTokenRepository(db)
+	revoked, err := repo.IsRevoked("abc")
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"hr-leave-request/models"
+
+	"gorm.io/gorm"
+)
+
+type RolePermissionRepository interface {
+	FindByRole(role string) ([]models.RolePermission, error)
+}
+
+type rolePermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewRolePermissionRepository(db *gorm.DB) RolePermissionRepository {
+	return &rolePermissionRepository{db: db}
+}
+
+// FindByRole returns the permissions granted to the role.
+func (r *rolePermissionRepository) FindByRole(role string) ([]models.RolePermission, error) {
+	var permissions []models.RolePermission
+	err := r.db.Where("role = ?", role).
+		Order("permission ASC").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRolePermissionsByRole(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func(sqlmock.Sqlmock)
+		expectedCount int
+		wantError     bool
+	}{
+		{
+			name: "permissions of the role",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "role", "permission", "created_at"}).
+					AddRow(1, "team_lead", "leave:approve:all", now).
+					AddRow(2, "team_lead", "leave:read:team", now)
+				mock.ExpectQuery("SELECT \\* FROM `role_permissions` WHERE role = \\? ORDER BY permission ASC").
+					WithArgs("team_lead").
+					WillReturnRows(rows)
+			},
+			expectedCount: 2,
+			wantError:     false,
+		},
+		{
+			name: "database error",
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("SELECT \\* FROM `role_permissions`").
+					WillReturnError(sql.ErrConnDone)
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, cleanup := setupMockDB(t)
+			defer cleanup()
+
+			tt.mockSetup(mock)
+
+			repo := NewRolePermissionRepository(db)
+			permissions, err := repo.FindByRole("team_lead")
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, permissions, tt.expectedCount)
+				assert.Equal(t, "leave:read:team", permissions[1].Permission)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+// Transactor runs a unit of work inside a single database transaction.
+// Repositories taking part in the transaction are bound to it through WithTx.
+type Transactor interface {
+	WithinTransaction(fn func(tx *gorm.DB) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	return t.db.Transaction(fn)
+}
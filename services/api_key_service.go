@@ -0,0 +1,225 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix makes keys recognisable, e.g. by secret scanners.
+	apiKeyPrefix = "hrk_"
+	// apiKeyDisplayLength is how much of the key is kept in clear to tell
+	// keys apart in listings.
+	apiKeyDisplayLength = 12
+	// apiKeyLastUsedInterval limits how often last_used_at is written for a
+	// busy key.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// apiKeyPermissions are the permissions that can be granted to API keys:
+// those the read routes open to services check. Everything else is left to
+// people.
+var apiKeyPermissions = map[string]bool{
+	PermissionLeaveReadAll: true,
+	PermissionEmployeeRead: true,
+}
+
+// APIKeyIdentity is the service a request was authenticated as with an API
+// key. It takes the place of the employee of an access token.
+type APIKeyIdentity struct {
+	ID          uint
+	Name        string
+	Permissions Permissions
+}
+
+type APIKeyService interface {
+	CreateAPIKey(creatorID uint, userRole string, req *dtos.CreateAPIKeyRequest) (*dtos.APIKeyResponse, error)
+	GetAPIKeys(userRole string) ([]dtos.APIKeyResponse, error)
+	RevokeAPIKey(id uint, userRole string) error
+	Authenticate(key string) (*APIKeyIdentity, error)
+}
+
+type apiKeyService struct {
+	repo        repositories.APIKeyRepository
+	permissions PermissionChecker
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepository, permissions PermissionChecker) APIKeyService {
+	return &apiKeyService{
+		repo:        repo,
+		permissions: permissions,
+	}
+}
+
+// CreateAPIKey issues a key with the requested permissions and returns it in
+// clear, for the only time. Creators can only grant permissions their own
+// role holds.
+func (s *apiKeyService) CreateAPIKey(creatorID uint, userRole string, req *dtos.CreateAPIKeyRequest) (*dtos.APIKeyResponse, error) {
+	creatorPermissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !creatorPermissions.Has(PermissionAPIKeyManage) {
+		return nil, ErrAPIKeyForbidden
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	granted := make(map[string]bool, len(req.Permissions))
+	var permissions []models.APIKeyPermission
+	for _, permission := range req.Permissions {
+		permission = strings.ToLower(strings.TrimSpace(permission))
+		if !apiKeyPermissions[permission] || !creatorPermissions.Has(permission) {
+			return nil, ErrInvalidAPIKeyPermission.Withf("cannot grant permission %s", permission)
+		}
+		if granted[permission] {
+			continue
+		}
+		granted[permission] = true
+		permissions = append(permissions, models.APIKeyPermission{Permission: permission})
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &models.APIKey{
+		Name:        strings.TrimSpace(req.Name),
+		Prefix:      key[:apiKeyDisplayLength],
+		KeyHash:     hashToken(key),
+		Permissions: permissions,
+		CreatedBy:   creatorID,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.repo.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	response := toAPIKeyResponse(apiKey)
+	response.Key = key
+	return response, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(userRole string) ([]dtos.APIKeyResponse, error) {
+	if err := s.checkManage(userRole); err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dtos.APIKeyResponse, len(apiKeys))
+	for i := range apiKeys {
+		responses[i] = *toAPIKeyResponse(&apiKeys[i])
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey disables a key straight away. Revoked keys stay listed for
+// the record.
+func (s *apiKeyService) RevokeAPIKey(id uint, userRole string) error {
+	if err := s.checkManage(userRole); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyRevoked
+	}
+
+	return nil
+}
+
+// Authenticate resolves a key to the service identity it was issued for.
+// Unknown, expired and revoked keys are all refused alike.
+func (s *apiKeyService) Authenticate(key string) (*APIKeyIdentity, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.FindByHash(hashToken(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedInterval {
+		// Usage tracking is informational and must not fail the request
+		if err := s.repo.TouchLastUsed(apiKey.ID, now); err != nil {
+			logrus.WithError(err).WithField("api_key_id", apiKey.ID).Warn("Failed to record API key use")
+		}
+	}
+
+	permissions := make(Permissions, len(apiKey.Permissions))
+	for _, permission := range apiKey.Permissions {
+		permissions[permission.Permission] = true
+	}
+
+	return &APIKeyIdentity{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Permissions: permissions,
+	}, nil
+}
+
+func (s *apiKeyService) checkManage(userRole string) error {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionAPIKeyManage)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrAPIKeyForbidden
+	}
+	return nil
+}
+
+func toAPIKeyResponse(apiKey *models.APIKey) *dtos.APIKeyResponse {
+	permissions := make([]string, len(apiKey.Permissions))
+	for i, permission := range apiKey.Permissions {
+		permissions[i] = permission.Permission
+	}
+	sort.Strings(permissions)
+
+	return &dtos.APIKeyResponse{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Prefix:      apiKey.Prefix,
+		Permissions: permissions,
+		CreatedBy:   apiKey.CreatedBy,
+		ExpiresAt:   apiKey.ExpiresAt,
+		LastUsedAt:  apiKey.LastUsedAt,
+		RevokedAt:   apiKey.RevokedAt,
+		CreatedAt:   apiKey.CreatedAt,
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		userRole    string
+		permissions []string
+		expiresAt   *time.Time
+		mockSetup   func(*mocks.MockAPIKeyRepository)
+		wantError   error
+	}{
+		{
+			name:        "hr creates a payroll key",
+			userRole:    "hr",
+			permissions: []string{"leave:read:all", "Employee:Read", "leave:read:all"},
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("Create", mock.MatchedBy(func(apiKey *models.APIKey) bool {
+					return apiKey.Name == "Payroll export" && apiKey.CreatedBy == 1 &&
+						len(apiKey.Permissions) == 2 && len(apiKey.KeyHash) == 64
+				})).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.APIKey).ID = 4
+				})
+			},
+		},
+		{
+			name:        "managers cannot create keys",
+			userRole:    "manager",
+			permissions: []string{"leave:read:team"},
+			mockSetup:   func(*mocks.MockAPIKeyRepository) {},
+			wantError:   ErrAPIKeyForbidden,
+		},
+		{
+			name:        "unknown permission",
+			userRole:    "hr",
+			permissions: []string{"payroll:run"},
+			mockSetup:   func(*mocks.MockAPIKeyRepository) {},
+			wantError:   ErrInvalidAPIKeyPermission,
+		},
+		{
+			name:        "keys cannot write",
+			userRole:    "hr",
+			permissions: []string{"leave:read:all", "leave:approve:all"},
+			mockSetup:   func(*mocks.MockAPIKeyRepository) {},
+			wantError:   ErrInvalidAPIKeyPermission,
+		},
+		{
+			name:        "keys cannot manage keys",
+			userRole:    "hr",
+			permissions: []string{"api_key:manage"},
+			mockSetup:   func(*mocks.MockAPIKeyRepository) {},
+			wantError:   ErrInvalidAPIKeyPermission,
+		},
+		{
+			name:        "expiry in the past",
+			userRole:    "hr",
+			permissions: []string{"leave:read:all"},
+			expiresAt:   &past,
+			mockSetup:   func(*mocks.MockAPIKeyRepository) {},
+			wantError:   ErrInvalidAPIKeyExpiry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAPIKeyRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewAPIKeyService(mockRepo, newTestPermissionChecker())
+			result, err := service.CreateAPIKey(1, tt.userRole, &dtos.CreateAPIKeyRequest{
+				Name:        "Payroll export",
+				Permissions: tt.permissions,
+				ExpiresAt:   tt.expiresAt,
+			})
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(4), result.ID)
+				assert.True(t, strings.HasPrefix(result.Key, apiKeyPrefix))
+				assert.Equal(t, result.Key[:apiKeyDisplayLength], result.Prefix)
+				assert.Equal(t, []string{"employee:read", "leave:read:all"}, result.Permissions)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	key := apiKeyPrefix + "secret"
+	recently := time.Now().Add(-10 * time.Second)
+	longAgo := time.Now().Add(-time.Hour)
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	permissions := []models.APIKeyPermission{{Permission: PermissionLeaveReadAll}}
+
+	tests := []struct {
+		name      string
+		key       string
+		mockSetup func(*mocks.MockAPIKeyRepository)
+		wantError error
+	}{
+		{
+			name: "active key records its use",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(&models.APIKey{ID: 4, Name: "Payroll export", Permissions: permissions, ExpiresAt: &future, LastUsedAt: &longAgo}, nil)
+				repo.On("TouchLastUsed", uint(4), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "recently used key is not written again",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(&models.APIKey{ID: 4, Name: "Payroll export", Permissions: permissions, LastUsedAt: &recently}, nil)
+			},
+		},
+		{
+			name: "failing to record the use does not fail the request",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(&models.APIKey{ID: 4, Name: "Payroll export", Permissions: permissions}, nil)
+				repo.On("TouchLastUsed", uint(4), mock.AnythingOfType("time.Time")).Return(errors.New("database is read-only"))
+			},
+		},
+		{
+			name:      "key without the prefix",
+			key:       "secret",
+			mockSetup: func(*mocks.MockAPIKeyRepository) {},
+			wantError: ErrInvalidAPIKey,
+		},
+		{
+			name: "unknown key",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrInvalidAPIKey,
+		},
+		{
+			name: "expired key",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(&models.APIKey{ID: 4, ExpiresAt: &past}, nil)
+			},
+			wantError: ErrInvalidAPIKey,
+		},
+		{
+			name: "revoked key",
+			key:  key,
+			mockSetup: func(repo *mocks.MockAPIKeyRepository) {
+				repo.On("FindByHash", hashToken(key)).Return(&models.APIKey{ID: 4, RevokedAt: &past}, nil)
+			},
+			wantError: ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockAPIKeyRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewAPIKeyService(mockRepo, newTestPermissionChecker())
+			identity, err := service.Authenticate(tt.key)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, identity)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(4), identity.ID)
+				assert.Equal(t, "Payroll export", identity.Name)
+				assert.True(t, identity.Permissions.Has(PermissionLeaveReadAll))
+				assert.False(t, identity.Permissions.Has(PermissionEmployeeRead))
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	mockRepo := new(mocks.MockAPIKeyRepository)
+	mockRepo.On("FindAll").Return([]models.APIKey{
+		{ID: 4, Name: "Payroll export", Prefix: "hrk_abcdefgh", KeyHash: "hash", Permissions: []models.APIKeyPermission{{Permission: PermissionLeaveReadAll}}},
+	}, nil)
+
+	service := NewAPIKeyService(mockRepo, newTestPermissionChecker())
+
+	_, err := service.GetAPIKeys("employee")
+	assert.ErrorIs(t, err, ErrAPIKeyForbidden)
+
+	apiKeys, err := service.GetAPIKeys("hr")
+	assert.NoError(t, err)
+	assert.Len(t, apiKeys, 1)
+	assert.Equal(t, "hrk_abcdefgh", apiKeys[0].Prefix)
+	assert.Equal(t, []string{PermissionLeaveReadAll}, apiKeys[0].Permissions)
+	assert.Empty(t, apiKeys[0].Key)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	mockRepo := new(mocks.MockAPIKeyRepository)
+	mockRepo.On("FindByID", uint(4)).Return(&models.APIKey{ID: 4}, nil)
+	mockRepo.On("FindByID", uint(5)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Revoke", uint(4), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockRepo.On("Revoke", uint(4), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+
+	service := NewAPIKeyService(mockRepo, newTestPermissionChecker())
+
+	assert.ErrorIs(t, service.RevokeAPIKey(4, "manager"), ErrAPIKeyForbidden)
+	assert.NoError(t, service.RevokeAPIKey(4, "hr"))
+	assert.ErrorIs(t, service.RevokeAPIKey(4, "hr"), ErrAPIKeyRevoked)
+	assert.ErrorIs(t, service.RevokeAPIKey(5, "hr"), ErrAPIKeyNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"slices"
+	"strings"
+)
+
+// lineManagerApprover names the approval step signed off by the requester's
+// line manager rather than by a role.
+const lineManagerApprover = "line_manager"
+
+// ApprovalPolicy builds the approval chain of a leave request from the steps
+// configured for its leave type, and knows who may sign off each step.
+type ApprovalPolicy interface {
+	StepsFor(leaveRequest *models.LeaveRequest) []models.LeaveRequestApproval
+	CanApprove(step *models.LeaveRequestApproval, leaveRequest *models.LeaveRequest, actorID uint, userRole string, permissions Permissions) bool
+}
+
+type approvalPolicy struct {
+	chains map[string][]config.ApprovalStepConfig
+}
+
+func NewApprovalPolicy(cfg *config.ApplicationConfig) (ApprovalPolicy, error) {
+	chains := make(map[string][]config.ApprovalStepConfig, len(cfg.Leave.ApprovalChains))
+	for leaveType, steps := range cfg.Leave.ApprovalChains {
+		if !slices.Contains(leaveTypes, leaveType) {
+			return nil, fmt.Errorf("invalid approval chain leave type %q", leaveType)
+		}
+		if len(steps) == 0 {
+			return nil, fmt.Errorf("approval chain for %s has no steps", leaveType)
+		}
+
+		chain := make([]config.ApprovalStepConfig, len(steps))
+		for i, step := range steps {
+			approver := strings.ToLower(strings.TrimSpace(step.Approver))
+			if approver == "" {
+				return nil, fmt.Errorf("invalid approver %q in approval chain for %s", step.Approver, leaveType)
+			}
+			if step.MinDays < 0 {
+				return nil, fmt.Errorf("invalid min days %v in approval chain for %s", step.MinDays, leaveType)
+			}
+			chain[i] = config.ApprovalStepConfig{Approver: approver, MinDays: step.MinDays}
+		}
+		chains[leaveType] = chain
+	}
+
+	return &approvalPolicy{chains: chains}, nil
+}
+
+// StepsFor returns the pending approval steps the leave request has to go
+// through, numbered from 1. Steps whose minimum duration the request does not
+// reach are left out; a request always needs at least its line manager's
+// approval.
+func (p *approvalPolicy) StepsFor(leaveRequest *models.LeaveRequest) []models.LeaveRequestApproval {
+	var steps []models.LeaveRequestApproval
+	for _, step := range p.chains[leaveRequest.Type] {
+		if leaveRequest.WorkingDays < step.MinDays {
+			continue
+		}
+		steps = append(steps, models.LeaveRequestApproval{
+			LeaveRequestID: leaveRequest.ID,
+			Step:           len(steps) + 1,
+			Approver:       step.Approver,
+			Status:         "pending",
+		})
+	}
+
+	if len(steps) == 0 {
+		steps = append(steps, models.LeaveRequestApproval{
+			LeaveRequestID: leaveRequest.ID,
+			Step:           1,
+			Approver:       lineManagerApprover,
+			Status:         "pending",
+		})
+	}
+
+	return steps
+}
+
+// CanApprove reports whether the actor may decide the approval step. Line
+// manager steps follow the same rules as any other decision on the request;
+// role steps need that role, and holders of leave:approve:all can override
+// any step. Nobody signs off their own leave.
+func (p *approvalPolicy) CanApprove(step *models.LeaveRequestApproval, leaveRequest *models.LeaveRequest, actorID uint, userRole string, permissions Permissions) bool {
+	if step.Approver == lineManagerApprover {
+		return canDecideLeaveRequest(leaveRequest, actorID, permissions)
+	}
+	if leaveRequest.EmployeeID == actorID {
+		return false
+	}
+	return permissions.Has(PermissionLeaveApproveAll) || strings.EqualFold(userRole, step.Approver)
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupApprovalChainConfig() *config.ApplicationConfig {
+	cfg := setupTestConfig()
+	cfg.Leave.ApprovalChains = map[string][]config.ApprovalStepConfig{
+		"vacation": {
+			{Approver: "line_manager"},
+			{Approver: "HR", MinDays: 5},
+		},
+	}
+	return cfg
+}
+
+func TestNewApprovalPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		chains    map[string][]config.ApprovalStepConfig
+		wantError bool
+	}{
+		{name: "no chains configured", chains: nil},
+		{name: "valid chain", chains: setupApprovalChainConfig().Leave.ApprovalChains},
+		{name: "unknown leave type", chains: map[string][]config.ApprovalStepConfig{"sabbatical": {{Approver: "hr"}}}, wantError: true},
+		{name: "empty chain", chains: map[string][]config.ApprovalStepConfig{"vacation": {}}, wantError: true},
+		{name: "any role can approve a step", chains: map[string][]config.ApprovalStepConfig{"vacation": {{Approver: "team_lead"}}}},
+		{name: "blank approver", chains: map[string][]config.ApprovalStepConfig{"vacation": {{Approver: " "}}}, wantError: true},
+		{name: "negative min days", chains: map[string][]config.ApprovalStepConfig{"vacation": {{Approver: "hr", MinDays: -1}}}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupTestConfig()
+			cfg.Leave.ApprovalChains = tt.chains
+
+			_, err := NewApprovalPolicy(cfg)
+
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApprovalPolicyStepsFor(t *testing.T) {
+	policy, err := NewApprovalPolicy(setupApprovalChainConfig())
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		leaveRequest  *models.LeaveRequest
+		wantApprovers []string
+	}{
+		{
+			name:          "long vacation goes through HR",
+			leaveRequest:  &models.LeaveRequest{ID: 1, Type: "vacation", WorkingDays: 5},
+			wantApprovers: []string{"line_manager", "hr"},
+		},
+		{
+			name:          "short vacation skips the HR step",
+			leaveRequest:  &models.LeaveRequest{ID: 1, Type: "vacation", WorkingDays: 2},
+			wantApprovers: []string{"line_manager"},
+		},
+		{
+			name:          "leave type without a chain needs the line manager",
+			leaveRequest:  &models.LeaveRequest{ID: 1, Type: "sick", WorkingDays: 10},
+			wantApprovers: []string{"line_manager"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := policy.StepsFor(tt.leaveRequest)
+
+			assert.Len(t, steps, len(tt.wantApprovers))
+			for i, step := range steps {
+				assert.Equal(t, i+1, step.Step)
+				assert.Equal(t, tt.wantApprovers[i], step.Approver)
+				assert.Equal(t, "pending", step.Status)
+				assert.Equal(t, tt.leaveRequest.ID, step.LeaveRequestID)
+			}
+		})
+	}
+}
+
+func TestApprovalPolicyCanApprove(t *testing.T) {
+	policy, err := NewApprovalPolicy(setupApprovalChainConfig())
+	assert.NoError(t, err)
+
+	managerID := uint(9)
+	leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Employee: &models.Employee{ID: 1, ManagerID: &managerID}}
+	lineManagerStep := &models.LeaveRequestApproval{Step: 1, Approver: "line_manager"}
+	hrStep := &models.LeaveRequestApproval{Step: 2, Approver: "hr"}
+	teamLeadStep := &models.LeaveRequestApproval{Step: 2, Approver: "team_lead"}
+	override := Permissions{PermissionLeaveApproveAll: true}
+
+	assert.True(t, policy.CanApprove(lineManagerStep, leaveRequest, 9, "manager", Permissions{}))
+	assert.False(t, policy.CanApprove(lineManagerStep, leaveRequest, 8, "manager", Permissions{}))
+	assert.True(t, policy.CanApprove(lineManagerStep, leaveRequest, 7, "hr", override))
+	assert.False(t, policy.CanApprove(hrStep, leaveRequest, 9, "manager", Permissions{}))
+	assert.True(t, policy.CanApprove(hrStep, leaveRequest, 7, "HR", Permissions{}))
+	assert.True(t, policy.CanApprove(hrStep, leaveRequest, 7, "auditor", override))
+	assert.False(t, policy.CanApprove(hrStep, leaveRequest, 1, "hr", override))
+	assert.True(t, policy.CanApprove(teamLeadStep, leaveRequest, 6, "team_lead", Permissions{}))
+}
@@ -8,63 +8,180 @@ import (
 	"hr-leave-request/repositories"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// dummyPasswordHash is checked against on logins for unknown emails. It has
+// the cost of real password hashes and matches no password anyone would use.
+var dummyPasswordHash = []byte("$2a$10$.SqQNBNZPQ7KsdMiYsb66OX/l4l.GztrTRRBmCAi1bMTnAxUgD/O6")
+
 type AuthService interface {
-	Login(req *dtos.LoginRequest) (*dtos.AuthResponse, error)
+	Login(req *dtos.LoginRequest, clientIP string) (*dtos.LoginResponse, error)
+	LoginMFA(req *dtos.MFALoginRequest, clientIP string) (*dtos.AuthResponse, error)
 	Register(req *dtos.RegisterRequest) (*dtos.AuthResponse, error)
+	AcceptInvitation(req *dtos.AcceptInvitationRequest) (*dtos.AuthResponse, error)
+	Refresh(req *dtos.RefreshTokenRequest) (*dtos.AuthResponse, error)
+	Logout(claims *AccessClaims, req *dtos.LogoutRequest) error
 }
 
 type authService struct {
-	repo repositories.EmployeeRepository
-	cfg  *config.ApplicationConfig
+	repo           repositories.EmployeeRepository
+	roleChangeRepo repositories.EmployeeRoleChangeRepository
+	invitationRepo repositories.InvitationRepository
+	tokens         TokenService
+	throttle       LoginThrottle
+	mfa            MFAService
+	keys           JWTKeys
+	transactor     repositories.Transactor
+	cfg            *config.ApplicationConfig
 }
 
-func NewAuthService(repo repositories.EmployeeRepository, cfg *config.ApplicationConfig) AuthService {
+func NewAuthService(repo repositories.EmployeeRepository, roleChangeRepo repositories.EmployeeRoleChangeRepository, invitationRepo repositories.InvitationRepository, tokens TokenService, throttle LoginThrottle, mfa MFAService, keys JWTKeys, transactor repositories.Transactor, cfg *config.ApplicationConfig) AuthService {
 	return &authService{
-		repo: repo,
-		cfg:  cfg,
+		repo:           repo,
+		roleChangeRepo: roleChangeRepo,
+		invitationRepo: invitationRepo,
+		tokens:         tokens,
+		throttle:       throttle,
+		mfa:            mfa,
+		keys:           keys,
+		transactor:     transactor,
+		cfg:            cfg,
 	}
 }
 
-func (s *authService) Login(req *dtos.LoginRequest) (*dtos.AuthResponse, error) {
+// Login authenticates an employee by email and password. Failed attempts are
+// counted per account and per client IP, and a locked account or IP is
+// refused before the password is checked.
+func (s *authService) Login(req *dtos.LoginRequest, clientIP string) (*dtos.LoginResponse, error) {
+	email := normalizeEmail(req.Email)
+	if err := s.throttle.Check(email, clientIP); err != nil {
+		return nil, err
+	}
+
 	// Find user by email
-	employee, err := s.repo.FindByEmail(req.Email)
+	employee, err := s.repo.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid email or password")
+			// Spend as long as checking a real password would, so response
+			// times do not reveal which emails have an account
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+			return nil, s.loginFailed(email, clientIP, nil, ErrInvalidCredentials)
 		}
 		return nil, err
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginFailed(email, clientIP, &employee.ID, ErrInvalidCredentials)
+	}
+
+	if err := s.throttle.RecordSuccess(email, clientIP); err != nil {
+		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(employee)
+	return completeLogin(employee, s.tokens, s.mfa, s.keys)
+}
+
+// completeLogin finishes a login whose first factor has been checked, by
+// password or single sign-on. Employees with two-factor authentication only
+// get a token to complete the login with LoginMFA.
+func completeLogin(employee *models.Employee, tokenService TokenService, mfa MFAService, keys JWTKeys) (*dtos.LoginResponse, error) {
+	mfaEnabled, err := mfa.IsEnabled(employee.ID)
 	if err != nil {
 		return nil, err
 	}
+	if mfaEnabled {
+		mfaToken, expiresAt, err := signMFAToken(keys, employee.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &dtos.LoginResponse{
+			MFARequired:       true,
+			MFAToken:          mfaToken,
+			MFATokenExpiresAt: &expiresAt,
+		}, nil
+	}
 
-	return &dtos.AuthResponse{
-		Token: token,
-		User:  *s.toEmployeeResponse(employee),
+	var role string
+	if employee.Role != nil {
+		role = *employee.Role
+	}
+	enrollmentRequired, err := mfa.IsRequired(role)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue access and refresh tokens
+	tokens, err := tokenService.IssueTokens(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.LoginResponse{
+		AuthResponse:          toAuthResponse(tokens, employee),
+		MFAEnrollmentRequired: enrollmentRequired,
 	}, nil
 }
 
+// LoginMFA completes a login with the second factor. Wrong codes count as
+// failed logins of the account.
+func (s *authService) LoginMFA(req *dtos.MFALoginRequest, clientIP string) (*dtos.AuthResponse, error) {
+	employeeID, err := parseMFAToken(s.keys, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.repo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	if err := s.throttle.Check(employee.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	err = s.mfa.Verify(employee.ID, &dtos.MFACodeRequest{Code: req.Code, RecoveryCode: req.RecoveryCode})
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			return nil, s.loginFailed(employee.Email, clientIP, &employee.ID, err)
+		}
+		return nil, err
+	}
+
+	if err := s.throttle.RecordSuccess(employee.Email, clientIP); err != nil {
+		return nil, err
+	}
+
+	// Issue access and refresh tokens
+	tokens, err := s.tokens.IssueTokens(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthResponse(tokens, employee), nil
+}
+
+// Register creates an employee account when self-registration is enabled.
+// Self-registered accounts always get the employee role; other roles are only
+// granted through invitations.
 func (s *authService) Register(req *dtos.RegisterRequest) (*dtos.AuthResponse, error) {
-	// Check if email already exists
-	existingEmployee, err := s.repo.FindByEmail(req.Email)
+	if !s.cfg.Auth.AllowSelfRegistration {
+		return nil, ErrRegistrationDisabled
+	}
+
+	// Check if email already exists, deactivated employees included
+	email := normalizeEmail(req.Email)
+	existingEmployee, err := s.repo.FindByEmailWithDeleted(email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 	if existingEmployee != nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrEmailAlreadyExists
 	}
 
 	// Hash password
@@ -73,49 +190,158 @@ func (s *authService) Register(req *dtos.RegisterRequest) (*dtos.AuthResponse, e
 		return nil, err
 	}
 
+	role := "employee"
 	employee := &models.Employee{
 		Name:     req.Name,
-		Email:    req.Email,
+		Email:    email,
 		Password: string(hashedPassword),
-		Role:     req.Role,
+		Role:     &role,
 	}
 
-	if err := s.repo.Create(employee); err != nil {
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(employee); err != nil {
+			return err
+		}
+		return s.roleChangeRepo.WithTx(tx).Create(&models.EmployeeRoleChange{
+			EmployeeID: employee.ID,
+			ToRole:     role,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := s.generateToken(employee)
+	// Issue access and refresh tokens
+	tokens, err := s.tokens.IssueTokens(employee)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dtos.AuthResponse{
-		Token: token,
-		User:  *s.toEmployeeResponse(employee),
-	}, nil
+	return toAuthResponse(tokens, employee), nil
 }
 
-func (s *authService) generateToken(employee *models.Employee) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": employee.ID,
-		"email":   employee.Email,
-		"role":    employee.Role,
-		"exp":     time.Now().Add(time.Hour * time.Duration(s.cfg.JWT.Expiration)).Unix(),
-		"iat":     time.Now().Unix(),
+// AcceptInvitation completes the registration of an invited employee, who
+// gets the email and role of the invitation. Each invitation can only be
+// used once.
+func (s *authService) AcceptInvitation(req *dtos.AcceptInvitationRequest) (*dtos.AuthResponse, error) {
+	invitationID, err := parseInvitationToken(s.keys, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationRepo.FindByID(invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !now.Before(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+
+	// Check if email already exists, deactivated employees included
+	email := normalizeEmail(invitation.Email)
+	existingEmployee, err := s.repo.FindByEmailWithDeleted(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existingEmployee != nil {
+		return nil, ErrEmailAlreadyExists
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	role := invitation.Role
+	employee := &models.Employee{
+		Name:     req.Name,
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     &role,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.JWT.Secret))
+	// Marking the invitation accepted in the same transaction keeps a second
+	// attempt with the same token from creating another account
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(employee); err != nil {
+			return err
+		}
+		accepted, err := s.invitationRepo.WithTx(tx).MarkAccepted(invitation.ID, employee.ID, now)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return ErrInvalidInvitation
+		}
+		// The role was granted by whoever sent the invitation
+		return s.roleChangeRepo.WithTx(tx).Create(&models.EmployeeRoleChange{
+			EmployeeID: employee.ID,
+			ActorID:    &invitation.InvitedBy,
+			ToRole:     role,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue access and refresh tokens
+	tokens, err := s.tokens.IssueTokens(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthResponse(tokens, employee), nil
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (s *authService) Refresh(req *dtos.RefreshTokenRequest) (*dtos.AuthResponse, error) {
+	tokens, employee, err := s.tokens.RefreshTokens(req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthResponse(tokens, employee), nil
 }
 
-func (s *authService) toEmployeeResponse(employee *models.Employee) *dtos.EmployeeResponse {
-	return &dtos.EmployeeResponse{
-		ID:        employee.ID,
-		Name:      employee.Name,
-		Email:     employee.Email,
-		Role:      employee.Role,
-		CreatedAt: employee.CreatedAt,
-		UpdatedAt: employee.UpdatedAt,
+// Logout revokes the access token used for the call and, when given, the
+// session of the refresh token.
+func (s *authService) Logout(claims *AccessClaims, req *dtos.LogoutRequest) error {
+	if req != nil && req.RefreshToken != "" {
+		if err := s.tokens.RevokeRefreshToken(claims.EmployeeID, req.RefreshToken); err != nil {
+			return err
+		}
+	}
+
+	return s.tokens.RevokeAccessToken(claims)
+}
+
+// loginFailed records the failed attempt and returns the error to report.
+func (s *authService) loginFailed(email, clientIP string, employeeID *uint, failure error) error {
+	if err := s.throttle.RecordFailure(email, clientIP, employeeID); err != nil {
+		return err
+	}
+	return failure
+}
+
+func toAuthResponse(tokens *TokenPair, employee *models.Employee) *dtos.AuthResponse {
+	return &dtos.AuthResponse{
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User: dtos.EmployeeResponse{
+			ID:        employee.ID,
+			Name:      employee.Name,
+			Email:     employee.Email,
+			Role:      employee.Role,
+			CreatedAt: employee.CreatedAt,
+			UpdatedAt: employee.UpdatedAt,
+		},
 	}
 }
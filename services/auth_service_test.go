@@ -19,8 +19,9 @@ import (
 func setupTestConfig() *config.ApplicationConfig {
 	return &config.ApplicationConfig{
 		JWT: config.JWTConfig{
-			Secret:     "test-secret-key",
-			Expiration: 24,
+			Secret:            "test-secret-key",
+			AccessExpiration:  15,
+			RefreshExpiration: 720,
 		},
 	}
 }
@@ -35,7 +36,7 @@ func TestLogin(t *testing.T) {
 		request   *dtos.LoginRequest
 		mockSetup func(*mocks.MockEmployeeRepository)
 		wantError bool
-		checkFunc func(*dtos.AuthResponse)
+		checkFunc func(*dtos.LoginResponse)
 	}{
 		{
 			name: "successful login",
@@ -56,8 +57,10 @@ func TestLogin(t *testing.T) {
 				repo.On("FindByEmail", "john@example.com").Return(employee, nil)
 			},
 			wantError: false,
-			checkFunc: func(resp *dtos.AuthResponse) {
+			checkFunc: func(resp *dtos.LoginResponse) {
 				assert.NotEmpty(t, resp.Token)
+				assert.NotEmpty(t, resp.RefreshToken)
+				assert.False(t, resp.MFARequired)
 				assert.Equal(t, "John Doe", resp.User.Name)
 				assert.Equal(t, "john@example.com", resp.User.Email)
 				assert.Equal(t, uint(1), resp.User.ID)
@@ -75,6 +78,18 @@ func TestLogin(t *testing.T) {
 				assert.Equal(t, "john@example.com", claims["email"])
 			},
 		},
+		{
+			name: "email is matched case-insensitively",
+			request: &dtos.LoginRequest{
+				Email:    " John@Example.com",
+				Password: password,
+			},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				employee := &models.Employee{ID: 1, Email: "john@example.com", Password: string(hashedPassword), Role: &role}
+				repo.On("FindByEmail", "john@example.com").Return(employee, nil)
+			},
+			wantError: false,
+		},
 		{
 			name: "user not found",
 			request: &dtos.LoginRequest{
@@ -126,8 +141,8 @@ func TestLogin(t *testing.T) {
 			cfg := setupTestConfig()
 			tt.mockSetup(mockRepo)
 
-			service := NewAuthService(mockRepo, cfg)
-			result, err := service.Login(tt.request)
+			service := NewAuthService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockInvitationRepository), newTestTokenService(mockRepo), newTestLoginThrottle(), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+			result, err := service.Login(tt.request, "10.0.0.1")
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -145,13 +160,22 @@ func TestLogin(t *testing.T) {
 	}
 }
 
-func TestRegister(t *testing.T) {
-	role := "employee"
+// TestDummyPasswordHash checks that logins for unknown emails pay for a full
+// bcrypt comparison at the cost real password hashes are made with.
+func TestDummyPasswordHash(t *testing.T) {
+	cost, err := bcrypt.Cost(dummyPasswordHash)
+	assert.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+
+	err = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte("password123"))
+	assert.ErrorIs(t, err, bcrypt.ErrMismatchedHashAndPassword)
+}
 
+func TestRegister(t *testing.T) {
 	tests := []struct {
 		name      string
 		request   *dtos.RegisterRequest
-		mockSetup func(*mocks.MockEmployeeRepository)
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockEmployeeRoleChangeRepository)
 		wantError bool
 		checkFunc func(*dtos.AuthResponse)
 	}{
@@ -159,13 +183,12 @@ func TestRegister(t *testing.T) {
 			name: "successful registration",
 			request: &dtos.RegisterRequest{
 				Name:     "John Doe",
-				Email:    "john@example.com",
+				Email:    " John@Example.com",
 				Password: "password123",
-				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
 				// Email doesn't exist
-				repo.On("FindByEmail", "john@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("FindByEmailWithDeleted", "john@example.com").Return(nil, gorm.ErrRecordNotFound)
 
 				// Create employee
 				repo.On("Create", mock.MatchedBy(func(emp *models.Employee) bool {
@@ -177,12 +200,17 @@ func TestRegister(t *testing.T) {
 					emp.CreatedAt = time.Now()
 					emp.UpdatedAt = time.Now()
 				})
+
+				// Nobody granted the role of a self-registered account
+				roleChangeRepo.On("Create", &models.EmployeeRoleChange{EmployeeID: 1, ToRole: "employee"}).Return(nil)
 			},
 			wantError: false,
 			checkFunc: func(resp *dtos.AuthResponse) {
 				assert.NotEmpty(t, resp.Token)
 				assert.Equal(t, "John Doe", resp.User.Name)
 				assert.Equal(t, "john@example.com", resp.User.Email)
+				// Self-registered accounts never pick their own role
+				assert.Equal(t, "employee", *resp.User.Role)
 
 				// Verify token is valid
 				token, err := jwt.Parse(resp.Token, func(token *jwt.Token) (interface{}, error) {
@@ -198,15 +226,14 @@ func TestRegister(t *testing.T) {
 				Name:     "Jane Doe",
 				Email:    "existing@example.com",
 				Password: "password123",
-				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
 				existingEmployee := &models.Employee{
 					ID:    1,
 					Name:  "Existing User",
 					Email: "existing@example.com",
 				}
-				repo.On("FindByEmail", "existing@example.com").Return(existingEmployee, nil)
+				repo.On("FindByEmailWithDeleted", "existing@example.com").Return(existingEmployee, nil)
 			},
 			wantError: true,
 			checkFunc: nil,
@@ -217,10 +244,9 @@ func TestRegister(t *testing.T) {
 				Name:     "Test User",
 				Email:    "test@example.com",
 				Password: "password123",
-				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
-				repo.On("FindByEmail", "test@example.com").Return(nil, errors.New("database error"))
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByEmailWithDeleted", "test@example.com").Return(nil, errors.New("database error"))
 			},
 			wantError: true,
 			checkFunc: nil,
@@ -231,10 +257,9 @@ func TestRegister(t *testing.T) {
 				Name:     "Test User",
 				Email:    "test@example.com",
 				Password: "password123",
-				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
-				repo.On("FindByEmail", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByEmailWithDeleted", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
 				repo.On("Create", mock.MatchedBy(func(emp *models.Employee) bool {
 					return emp.Email == "test@example.com"
 				})).Return(errors.New("database error"))
@@ -247,10 +272,12 @@ func TestRegister(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(mocks.MockEmployeeRepository)
+			mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
 			cfg := setupTestConfig()
-			tt.mockSetup(mockRepo)
+			cfg.Auth.AllowSelfRegistration = true
+			tt.mockSetup(mockRepo, mockRoleChangeRepo)
 
-			service := NewAuthService(mockRepo, cfg)
+			service := NewAuthService(mockRepo, mockRoleChangeRepo, new(mocks.MockInvitationRepository), newTestTokenService(mockRepo), newTestLoginThrottle(), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
 			result, err := service.Register(tt.request)
 
 			if tt.wantError {
@@ -271,95 +298,216 @@ func TestRegister(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockRoleChangeRepo.AssertExpectations(t)
 		})
 	}
 }
 
-func TestGenerateToken(t *testing.T) {
-	role := "employee"
+func TestRegisterDisabled(t *testing.T) {
+	mockRepo := new(mocks.MockEmployeeRepository)
+	service := NewAuthService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockInvitationRepository), newTestTokenService(mockRepo), newTestLoginThrottle(), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+
+	result, err := service.Register(&dtos.RegisterRequest{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "password123",
+	})
+
+	assert.ErrorIs(t, err, ErrRegistrationDisabled)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAcceptInvitation(t *testing.T) {
 	cfg := setupTestConfig()
+	invitation := &models.Invitation{
+		ID:        3,
+		Email:     "jane@example.com",
+		Role:      "manager",
+		InvitedBy: 1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	token, err := signInvitationToken(newTestJWTKeys(), invitation)
+	assert.NoError(t, err)
+	expiredToken, err := signInvitationToken(newTestJWTKeys(), &models.Invitation{ID: 3, Email: "jane@example.com", ExpiresAt: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	session, err := newTestTokenService(new(mocks.MockEmployeeRepository)).IssueTokens(&models.Employee{ID: 1, Email: "john@example.com"})
+	assert.NoError(t, err)
+	accessToken := session.AccessToken
+	acceptedAt := time.Now()
 
 	tests := []struct {
 		name      string
-		employee  *models.Employee
-		checkFunc func(string)
+		token     string
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockInvitationRepository, *mocks.MockEmployeeRoleChangeRepository)
+		wantError error
 	}{
 		{
-			name: "generate valid token",
-			employee: &models.Employee{
-				ID:    1,
-				Name:  "John Doe",
-				Email: "john@example.com",
-				Role:  &role,
-			},
-			checkFunc: func(tokenString string) {
-				assert.NotEmpty(t, tokenString)
-
-				// Parse and verify token
-				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-					// Verify signing method
-					_, ok := token.Method.(*jwt.SigningMethodHMAC)
-					assert.True(t, ok)
-					return []byte(cfg.JWT.Secret), nil
+			name:  "invitee registers with the invited role",
+			token: token,
+			mockSetup: func(repo *mocks.MockEmployeeRepository, invitationRepo *mocks.MockInvitationRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				invitationRepo.On("FindByID", uint(3)).Return(invitation, nil)
+				repo.On("FindByEmailWithDeleted", "jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Create", mock.MatchedBy(func(emp *models.Employee) bool {
+					return emp.Email == "jane@example.com" && *emp.Role == "manager"
+				})).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.Employee).ID = 5
 				})
-
-				assert.NoError(t, err)
-				assert.True(t, token.Valid)
-
-				// Verify claims
-				claims, ok := token.Claims.(jwt.MapClaims)
-				assert.True(t, ok)
-				assert.Equal(t, float64(1), claims["user_id"])
-				assert.Equal(t, "john@example.com", claims["email"])
-				assert.Equal(t, role, claims["role"])
-
-				// Verify expiration is set
-				exp, ok := claims["exp"].(float64)
-				assert.True(t, ok)
-				assert.Greater(t, exp, float64(time.Now().Unix()))
-
-				// Verify issued at is set
-				iat, ok := claims["iat"].(float64)
-				assert.True(t, ok)
-				assert.LessOrEqual(t, iat, float64(time.Now().Unix()))
+				invitationRepo.On("MarkAccepted", uint(3), uint(5), mock.AnythingOfType("time.Time")).Return(true, nil)
+				// The inviter is recorded as granting the role
+				inviterID := uint(1)
+				roleChangeRepo.On("Create", &models.EmployeeRoleChange{EmployeeID: 5, ActorID: &inviterID, ToRole: "manager"}).Return(nil)
 			},
 		},
 		{
-			name: "generate token with nil role",
-			employee: &models.Employee{
-				ID:    2,
-				Name:  "Jane Doe",
-				Email: "jane@example.com",
-				Role:  nil,
+			name:  "token used concurrently",
+			token: token,
+			mockSetup: func(repo *mocks.MockEmployeeRepository, invitationRepo *mocks.MockInvitationRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				invitationRepo.On("FindByID", uint(3)).Return(invitation, nil)
+				repo.On("FindByEmailWithDeleted", "jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Create", mock.Anything).Return(nil)
+				invitationRepo.On("MarkAccepted", uint(3), uint(0), mock.AnythingOfType("time.Time")).Return(false, nil)
 			},
-			checkFunc: func(tokenString string) {
-				assert.NotEmpty(t, tokenString)
-
-				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-					return []byte(cfg.JWT.Secret), nil
-				})
-
-				assert.NoError(t, err)
-				assert.True(t, token.Valid)
-
-				claims, ok := token.Claims.(jwt.MapClaims)
-				assert.True(t, ok)
-				assert.Nil(t, claims["role"])
+			wantError: ErrInvalidInvitation,
+		},
+		{
+			name:  "invitation already accepted",
+			token: token,
+			mockSetup: func(repo *mocks.MockEmployeeRepository, invitationRepo *mocks.MockInvitationRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				accepted := *invitation
+				accepted.AcceptedAt = &acceptedAt
+				invitationRepo.On("FindByID", uint(3)).Return(&accepted, nil)
+			},
+			wantError: ErrInvalidInvitation,
+		},
+		{
+			name:  "expired token",
+			token: expiredToken,
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockInvitationRepository, *mocks.MockEmployeeRoleChangeRepository) {
 			},
+			wantError: ErrInvalidInvitation,
+		},
+		{
+			name:  "access token is not an invitation",
+			token: accessToken,
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockInvitationRepository, *mocks.MockEmployeeRoleChangeRepository) {
+			},
+			wantError: ErrInvalidInvitation,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(mocks.MockEmployeeRepository)
-			service := NewAuthService(mockRepo, cfg).(*authService)
+			mockInvitationRepo := new(mocks.MockInvitationRepository)
+			mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+			tt.mockSetup(mockRepo, mockInvitationRepo, mockRoleChangeRepo)
 
-			token, err := service.generateToken(tt.employee)
+			service := NewAuthService(mockRepo, mockRoleChangeRepo, mockInvitationRepo, newTestTokenService(mockRepo), newTestLoginThrottle(), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+			result, err := service.AcceptInvitation(&dtos.AcceptInvitationRequest{
+				Token:    tt.token,
+				Name:     "Jane Doe",
+				Password: "password123",
+			})
 
-			assert.NoError(t, err)
-			if tt.checkFunc != nil {
-				tt.checkFunc(token)
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.Token)
+				assert.Equal(t, "jane@example.com", result.User.Email)
+				assert.Equal(t, "manager", *result.User.Role)
 			}
+
+			mockRepo.AssertExpectations(t)
+			mockInvitationRepo.AssertExpectations(t)
+			mockRoleChangeRepo.AssertExpectations(t)
 		})
 	}
 }
+
+func TestLogout(t *testing.T) {
+	claims := &AccessClaims{TokenID: "abc", EmployeeID: 1, ExpiresAt: time.Now().Add(10 * time.Minute)}
+
+	mockRefreshRepo := new(mocks.MockRefreshTokenRepository)
+	mockRefreshRepo.On("FindByHash", hashToken("refresh-token")).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family"}, nil)
+	mockRefreshRepo.On("RevokeFamily", "family", mock.AnythingOfType("time.Time")).Return(nil)
+	mockRevokedRepo := new(mocks.MockRevokedTokenRepository)
+	mockRevokedRepo.On("Create", &models.RevokedToken{JTI: "abc", EmployeeID: 1, ExpiresAt: claims.ExpiresAt}).Return(nil).Twice()
+
+	mockRepo := new(mocks.MockEmployeeRepository)
+	tokens := NewTokenService(mockRepo, mockRefreshRepo, mockRevokedRepo, newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+	service := NewAuthService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockInvitationRepository), tokens, newTestLoginThrottle(), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+
+	assert.NoError(t, service.Logout(claims, &dtos.LogoutRequest{RefreshToken: "refresh-token"}))
+	assert.NoError(t, service.Logout(claims, &dtos.LogoutRequest{}))
+
+	mockRefreshRepo.AssertNumberOfCalls(t, "RevokeFamily", 1)
+	mockRevokedRepo.AssertExpectations(t)
+}
+
+func TestLoginLockout(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmail", "john@example.com").Return(&models.Employee{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+	lockoutRepo := new(mocks.MockLoginLockoutRepository)
+	lockoutRepo.On("Create", mock.AnythingOfType("*models.LoginLockout")).Return(nil).Once()
+
+	cfg := throttleTestConfig()
+	throttle := NewLoginThrottle(NewMemoryLoginAttemptStore(), lockoutRepo, cfg)
+	service := NewAuthService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockInvitationRepository), newTestTokenService(mockRepo), throttle, newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+	for i := 0; i < 3; i++ {
+		_, err := service.Login(&dtos.LoginRequest{Email: "john@example.com", Password: "wrong"}, "10.0.0.1")
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// Even the right password is refused while the account is locked
+	_, err := service.Login(&dtos.LoginRequest{Email: "john@example.com", Password: "password123"}, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+
+	mockRepo.AssertNumberOfCalls(t, "FindByEmail", 3)
+	lockoutRepo.AssertExpectations(t)
+}
+
+func TestLoginWithMFA(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	confirmedAt := time.Now()
+	employee := &models.Employee{ID: 1, Name: "John Doe", Email: "john@example.com", Password: string(hashedPassword)}
+
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmail", "john@example.com").Return(employee, nil)
+	mockRepo.On("FindByID", uint(1)).Return(employee, nil)
+	mockEnrollmentRepo := new(mocks.MockMFAEnrollmentRepository)
+	mockEnrollmentRepo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+	mockEnrollmentRepo.On("UseStep", uint(2), mock.Anything).Return(true, nil).Once()
+
+	cfg := setupTestConfig()
+	mfa := NewMFAService(mockRepo, mockEnrollmentRepo, new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, cfg)
+	service := NewAuthService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockInvitationRepository), newTestTokenService(mockRepo), newTestLoginThrottle(), mfa, newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+	// The password alone only yields a token for the second step
+	loginResp, err := service.Login(&dtos.LoginRequest{Email: "john@example.com", Password: "password123"}, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.True(t, loginResp.MFARequired)
+	assert.NotEmpty(t, loginResp.MFAToken)
+	assert.Nil(t, loginResp.AuthResponse)
+
+	// The pending token is not an access token
+	_, err = newTestTokenService(mockRepo).ValidateAccessToken(loginResp.MFAToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = service.LoginMFA(&dtos.MFALoginRequest{MFAToken: loginResp.MFAToken, Code: "000000"}, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+	authResp, err := service.LoginMFA(&dtos.MFALoginRequest{MFAToken: loginResp.MFAToken, Code: currentTOTPCode(t, rfcTOTPSecret)}, "10.0.0.1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, authResp.Token)
+	assert.Equal(t, uint(1), authResp.User.ID)
+
+	_, err = service.LoginMFA(&dtos.MFALoginRequest{MFAToken: "invalid", Code: "123456"}, "10.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidMFAToken)
+
+	mockEnrollmentRepo.AssertExpectations(t)
+}
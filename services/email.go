@@ -0,0 +1,9 @@
+package services
+
+import "strings"
+
+// normalizeEmail returns the form emails are stored and looked up in, so
+// addresses differing only in case or surrounding spaces match.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
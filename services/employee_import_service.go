@@ -0,0 +1,405 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hr-leave-request/apperrors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// maxEmployeeImportRows caps the rows of one import, which is committed in a
+// single transaction.
+const maxEmployeeImportRows = 1000
+
+// employeeImportColumns are the columns an import file may have. The header
+// names them in any order; only name and email are required.
+var employeeImportColumns = map[string]bool{
+	"name":          true,
+	"email":         true,
+	"role":          true,
+	"manager_email": true,
+	"start_date":    true,
+}
+
+// EmployeeImportService onboards employees in bulk from a CSV file.
+type EmployeeImportService interface {
+	ImportEmployees(importerID uint, userRole string, file io.Reader, dryRun bool) (*dtos.ImportEmployeesResponse, error)
+}
+
+type employeeImportService struct {
+	repo           repositories.EmployeeRepository
+	roleChangeRepo repositories.EmployeeRoleChangeRepository
+	resetTokenRepo repositories.PasswordResetTokenRepository
+	notifier       Notifier
+	permissions    PermissionChecker
+	transactor     repositories.Transactor
+	cfg            *config.ApplicationConfig
+}
+
+func NewEmployeeImportService(repo repositories.EmployeeRepository, roleChangeRepo repositories.EmployeeRoleChangeRepository, resetTokenRepo repositories.PasswordResetTokenRepository, notifier Notifier, permissions PermissionChecker, transactor repositories.Transactor, cfg *config.ApplicationConfig) EmployeeImportService {
+	return &employeeImportService{
+		repo:           repo,
+		roleChangeRepo: roleChangeRepo,
+		resetTokenRepo: resetTokenRepo,
+		notifier:       notifier,
+		permissions:    permissions,
+		transactor:     transactor,
+		cfg:            cfg,
+	}
+}
+
+// employeeImportRow is a row of an import file once read.
+type employeeImportRow struct {
+	line         int
+	name         string
+	email        string
+	role         string
+	managerEmail string
+	// rawStartDate is kept to report a start date that did not parse
+	rawStartDate string
+	startDate    *time.Time
+}
+
+// ImportEmployees validates every row of the file and, unless it is a dry
+// run, creates all employees in one transaction: a single invalid row
+// imports nothing. Imported employees get no password; each is sent a
+// password reset token to set one with instead.
+func (s *employeeImportService) ImportEmployees(importerID uint, userRole string, file io.Reader, dryRun bool) (*dtos.ImportEmployeesResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !permissions.Has(PermissionEmployeeCreate) {
+		return nil, ErrEmployeeCreateForbidden
+	}
+
+	rows, err := parseEmployeeImport(file)
+	if err != nil {
+		return nil, err
+	}
+
+	rowErrors, managerIDs, err := s.validate(rows, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ImportEmployeesResponse{
+		DryRun: dryRun,
+		Rows:   len(rows),
+		Errors: rowErrors,
+	}
+	if dryRun {
+		return response, nil
+	}
+	if len(rowErrors) > 0 {
+		return nil, ErrInvalidEmployeeImport.WithFields(importFieldErrors(rowErrors)...)
+	}
+
+	employees, tokens, expiresAt, err := s.create(importerID, rows, managerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range employees {
+		// The employees exist by now; one can still ask for a new link
+		// through the forgotten password flow
+		if err := s.notifier.SendPasswordReset(&employees[i], tokens[i], expiresAt); err != nil {
+			logrus.WithError(err).WithField("employee_id", employees[i].ID).Warn("Failed to send onboarding password reset")
+		}
+	}
+
+	response.Imported = len(employees)
+	response.Employees = make([]dtos.EmployeeResponse, len(employees))
+	for i := range employees {
+		response.Employees[i] = *toEmployeeResponse(&employees[i])
+	}
+	return response, nil
+}
+
+// validate checks the rows against each other and the existing employees.
+// It returns the problems found and the IDs of the existing managers named
+// in the file, by email address.
+func (s *employeeImportService) validate(rows []employeeImportRow, permissions Permissions) ([]dtos.ImportRowError, map[string]uint, error) {
+	rowErrors := []dtos.ImportRowError{}
+	fail := func(row employeeImportRow, field, message string) {
+		rowErrors = append(rowErrors, dtos.ImportRowError{Row: row.line, Field: field, Message: message})
+	}
+
+	// Employees of the file can manage each other, whatever the row order
+	managersInFile := make(map[string]string, len(rows))
+	firstLine := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if _, ok := firstLine[row.email]; !ok && row.email != "" {
+			firstLine[row.email] = row.line
+			managersInFile[row.email] = row.managerEmail
+		}
+	}
+
+	managerIDs := make(map[string]uint)
+	knownRoles := make(map[string]bool)
+	for _, row := range rows {
+		if n := len([]rune(row.name)); n < 3 || n > 100 {
+			fail(row, "name", "must be between 3 and 100 characters")
+		}
+
+		if address, err := mail.ParseAddress(row.email); err != nil || address.Address != row.email || len(row.email) > 100 {
+			fail(row, "email", "must be a valid email address")
+		} else if line := firstLine[row.email]; line != row.line {
+			fail(row, "email", fmt.Sprintf("is already used on row %d", line))
+		} else {
+			existing, err := s.repo.FindByEmailWithDeleted(row.email)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, err
+			}
+			if existing != nil {
+				fail(row, "email", "already exists")
+			}
+		}
+
+		known, checked := knownRoles[row.role]
+		if !checked {
+			var err error
+			if known, err = s.permissions.IsKnownRole(row.role); err != nil {
+				return nil, nil, err
+			}
+			knownRoles[row.role] = known
+		}
+		if !known {
+			fail(row, "role", "unknown role")
+		} else if row.role != "employee" && !permissions.Has(PermissionEmployeeRoleAssign) {
+			fail(row, "role", "not permitted to assign roles")
+		}
+
+		if row.managerEmail != "" {
+			switch {
+			case row.managerEmail == row.email:
+				fail(row, "manager_email", "employee cannot be their own manager")
+			case firstLine[row.managerEmail] != 0:
+				if leadsBackTo(row.email, row.managerEmail, managersInFile) {
+					fail(row, "manager_email", "would create a reporting cycle")
+				}
+			default:
+				if _, ok := managerIDs[row.managerEmail]; ok {
+					break
+				}
+				manager, err := s.repo.FindByEmail(row.managerEmail)
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, nil, err
+				}
+				if manager == nil {
+					fail(row, "manager_email", "manager not found")
+				} else {
+					managerIDs[row.managerEmail] = manager.ID
+				}
+			}
+		}
+
+		if row.rawStartDate != "" && row.startDate == nil {
+			fail(row, "start_date", "must be a date formatted as YYYY-MM-DD")
+		}
+	}
+
+	return rowErrors, managerIDs, nil
+}
+
+// create adds the employees in one transaction, with their initial role in
+// the role history and a password reset token each. Managers from the file
+// are assigned once everyone has an ID.
+func (s *employeeImportService) create(importerID uint, rows []employeeImportRow, managerIDs map[string]uint) ([]models.Employee, []string, time.Time, error) {
+	// The password is never handed out, so one unusable hash serves every
+	// account and spares hashing a password per row
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	// The token stands in for an invitation, so it lasts as long as one
+	expiration := s.cfg.Auth.InvitationExpiration
+	if expiration <= 0 {
+		expiration = 72
+	}
+	expiresAt := time.Now().Add(time.Hour * time.Duration(expiration))
+
+	employees := make([]models.Employee, len(rows))
+	tokens := make([]string, len(rows))
+	for i, row := range rows {
+		role := row.role
+		employees[i] = models.Employee{
+			Name:      row.name,
+			Email:     row.email,
+			Password:  string(hashedPassword),
+			Role:      &role,
+			StartDate: row.startDate,
+		}
+		if managerID, ok := managerIDs[row.managerEmail]; ok {
+			employees[i].ManagerID = &managerID
+		}
+		if tokens[i], err = randomToken(32); err != nil {
+			return nil, nil, time.Time{}, err
+		}
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		roleChangeRepo := s.roleChangeRepo.WithTx(tx)
+		resetTokenRepo := s.resetTokenRepo.WithTx(tx)
+
+		ids := make(map[string]uint, len(employees))
+		for i := range employees {
+			employee := &employees[i]
+			if err := repo.Create(employee); err != nil {
+				return err
+			}
+			ids[employee.Email] = employee.ID
+
+			if err := roleChangeRepo.Create(&models.EmployeeRoleChange{
+				EmployeeID: employee.ID,
+				ActorID:    &importerID,
+				ToRole:     *employee.Role,
+			}); err != nil {
+				return err
+			}
+			if err := resetTokenRepo.Create(&models.PasswordResetToken{
+				EmployeeID: employee.ID,
+				TokenHash:  hashToken(tokens[i]),
+				ExpiresAt:  expiresAt,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for i, row := range rows {
+			managerID, ok := ids[row.managerEmail]
+			if !ok {
+				continue
+			}
+			if err := repo.UpdateManager(employees[i].ID, &managerID); err != nil {
+				return err
+			}
+			employees[i].ManagerID = &managerID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	return employees, tokens, expiresAt, nil
+}
+
+// parseEmployeeImport reads the rows of an import file. A file that cannot be
+// read as a whole is refused; problems with single values are left to
+// validate.
+func parseEmployeeImport(file io.Reader) ([]employeeImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidEmployeeImportFile.Withf("missing header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if !employeeImportColumns[name] {
+			return nil, ErrInvalidEmployeeImportFile.Withf("unknown column %q", name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, ErrInvalidEmployeeImportFile.Withf("duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"name", "email"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrInvalidEmployeeImportFile.Withf("missing column %q", name)
+		}
+	}
+
+	var rows []employeeImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidEmployeeImportFile.Wrap(err)
+		}
+		if len(rows) == maxEmployeeImportRows {
+			return nil, ErrInvalidEmployeeImportFile.Withf("more than %d rows", maxEmployeeImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := employeeImportRow{
+			line:         line,
+			name:         value("name"),
+			email:        normalizeEmail(value("email")),
+			role:         strings.ToLower(defaultString(value("role"), "employee")),
+			managerEmail: normalizeEmail(value("manager_email")),
+			rawStartDate: value("start_date"),
+		}
+		if row.rawStartDate != "" {
+			if date, err := time.Parse(time.DateOnly, row.rawStartDate); err == nil {
+				row.startDate = &date
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, ErrInvalidEmployeeImportFile.Withf("no employees to import")
+	}
+
+	return rows, nil
+}
+
+// leadsBackTo reports whether following managers through the file from
+// managerEmail reaches email again.
+func leadsBackTo(email, managerEmail string, managersInFile map[string]string) bool {
+	visited := make(map[string]bool)
+	for current := managerEmail; current != "" && !visited[current]; current = managersInFile[current] {
+		if current == email {
+			return true
+		}
+		visited[current] = true
+	}
+	return false
+}
+
+// importFieldErrors turns row errors into field errors named after the row,
+// such as rows[3].email.
+func importFieldErrors(rowErrors []dtos.ImportRowError) []apperrors.FieldError {
+	fields := make([]apperrors.FieldError, len(rowErrors))
+	for i, rowErr := range rowErrors {
+		fields[i] = apperrors.FieldError{
+			Field:   fmt.Sprintf("rows[%d].%s", rowErr.Row, rowErr.Field),
+			Message: rowErr.Message,
+		}
+	}
+	return fields
+}
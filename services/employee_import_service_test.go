@@ -0,0 +1,222 @@
+package services
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestEmployeeImportService(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, notifier Notifier) EmployeeImportService {
+	return NewEmployeeImportService(repo, roleChangeRepo, resetRepo, notifier, newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+}
+
+func TestImportEmployeesDryRun(t *testing.T) {
+	managerRole := "manager"
+
+	tests := []struct {
+		name       string
+		userRole   string
+		file       string
+		mockSetup  func(*mocks.MockEmployeeRepository)
+		wantError  error
+		wantErrors []dtos.ImportRowError
+	}{
+		{
+			name:     "valid file",
+			userRole: "hr",
+			file: "Name,Email,Role,Manager Email,Start Date\n" +
+				"Ada Lovelace,ada@example.com,manager,boss@example.com,2026-11-02\n" +
+				"Alan Turing,Alan@Example.com,,ada@example.com,\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmailWithDeleted", "ada@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("FindByEmailWithDeleted", "alan@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("FindByEmail", "boss@example.com").Return(&models.Employee{ID: 5, Role: &managerRole}, nil)
+			},
+			wantErrors: []dtos.ImportRowError{},
+		},
+		{
+			name:     "invalid rows are reported",
+			userRole: "hr",
+			file: "name,email,role,manager_email,start_date\n" +
+				"Al,not-an-email,ceo,,02/11/2026\n" +
+				"Grace Hopper,taken@example.com,,nobody@example.com,\n" +
+				"Grace Hopper,grace@example.com,,grace@example.com,\n" +
+				"Grace Again,grace@example.com,,,\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmailWithDeleted", "taken@example.com").Return(&models.Employee{ID: 3}, nil)
+				repo.On("FindByEmailWithDeleted", "grace@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("FindByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrors: []dtos.ImportRowError{
+				{Row: 2, Field: "name", Message: "must be between 3 and 100 characters"},
+				{Row: 2, Field: "email", Message: "must be a valid email address"},
+				{Row: 2, Field: "role", Message: "unknown role"},
+				{Row: 2, Field: "start_date", Message: "must be a date formatted as YYYY-MM-DD"},
+				{Row: 3, Field: "email", Message: "already exists"},
+				{Row: 3, Field: "manager_email", Message: "manager not found"},
+				{Row: 4, Field: "manager_email", Message: "employee cannot be their own manager"},
+				{Row: 5, Field: "email", Message: "is already used on row 4"},
+			},
+		},
+		{
+			name:     "reporting cycle within the file",
+			userRole: "hr",
+			file: "name,email,manager_email\n" +
+				"Ada Lovelace,ada@example.com,alan@example.com\n" +
+				"Alan Turing,alan@example.com,ada@example.com\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmailWithDeleted", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantErrors: []dtos.ImportRowError{
+				{Row: 2, Field: "manager_email", Message: "would create a reporting cycle"},
+				{Row: 3, Field: "manager_email", Message: "would create a reporting cycle"},
+			},
+		},
+		{
+			name:      "employees cannot import",
+			userRole:  "employee",
+			file:      "name,email\nAda Lovelace,ada@example.com\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrEmployeeCreateForbidden,
+		},
+		{
+			name:      "unknown column",
+			userRole:  "hr",
+			file:      "name,email,password\nAda Lovelace,ada@example.com,secret\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidEmployeeImportFile,
+		},
+		{
+			name:      "missing email column",
+			userRole:  "hr",
+			file:      "name\nAda Lovelace\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidEmployeeImportFile,
+		},
+		{
+			name:      "no rows",
+			userRole:  "hr",
+			file:      "name,email\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidEmployeeImportFile,
+		},
+		{
+			name:      "ragged rows",
+			userRole:  "hr",
+			file:      "name,email\nAda Lovelace\n",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidEmployeeImportFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+
+			// A dry run must not write anything
+			service := newTestEmployeeImportService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockPasswordResetTokenRepository), new(mockNotifier))
+			result, err := service.ImportEmployees(9, tt.userRole, strings.NewReader(tt.file), true)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, result.DryRun)
+				assert.Equal(t, 0, result.Imported)
+				assert.Equal(t, tt.wantErrors, result.Errors)
+				assert.Empty(t, result.Employees)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportEmployees(t *testing.T) {
+	managerRole := "manager"
+	file := "\ufeffname,email,role,manager_email,start_date\n" +
+		"Alan Turing,alan@example.com,,ada@example.com,2026-11-02\n" +
+		"Ada Lovelace,ada@example.com,manager,boss@example.com,\n"
+
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmailWithDeleted", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByEmail", "boss@example.com").Return(&models.Employee{ID: 5, Role: &managerRole}, nil)
+	nextID := uint(10)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Employee")).Run(func(args mock.Arguments) {
+		employee := args.Get(0).(*models.Employee)
+		assert.NotEmpty(t, employee.Password)
+		employee.ID = nextID
+		nextID++
+	}).Return(nil)
+	// Alan reports to Ada, who is created after him
+	mockRepo.On("UpdateManager", uint(10), mock.MatchedBy(func(managerID *uint) bool {
+		return *managerID == 11
+	})).Return(nil)
+
+	mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+	mockRoleChangeRepo.On("Create", mock.MatchedBy(func(change *models.EmployeeRoleChange) bool {
+		return change.EmployeeID == 10 && *change.ActorID == 9 && change.ToRole == "employee"
+	})).Return(nil)
+	mockRoleChangeRepo.On("Create", mock.MatchedBy(func(change *models.EmployeeRoleChange) bool {
+		return change.EmployeeID == 11 && *change.ActorID == 9 && change.ToRole == "manager"
+	})).Return(nil)
+
+	var tokenHashes []string
+	mockResetRepo := new(mocks.MockPasswordResetTokenRepository)
+	mockResetRepo.On("Create", mock.AnythingOfType("*models.PasswordResetToken")).Run(func(args mock.Arguments) {
+		tokenHashes = append(tokenHashes, args.Get(0).(*models.PasswordResetToken).TokenHash)
+	}).Return(nil)
+
+	var tokens []string
+	notifier := new(mockNotifier)
+	notifier.On("SendPasswordReset", mock.AnythingOfType("*models.Employee"), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Run(func(args mock.Arguments) {
+		tokens = append(tokens, args.String(1))
+	}).Return(nil)
+
+	service := newTestEmployeeImportService(mockRepo, mockRoleChangeRepo, mockResetRepo, notifier)
+	result, err := service.ImportEmployees(9, "hr", strings.NewReader(file), false)
+	require.NoError(t, err)
+
+	assert.False(t, result.DryRun)
+	assert.Equal(t, 2, result.Rows)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, result.Employees, 2)
+	assert.Equal(t, uint(11), *result.Employees[0].ManagerID)
+	assert.Equal(t, "2026-11-02", result.Employees[0].StartDate.Format("2006-01-02"))
+	assert.Equal(t, uint(5), *result.Employees[1].ManagerID)
+
+	// Employees are sent the tokens whose hashes were stored
+	require.Len(t, tokens, 2)
+	assert.Equal(t, []string{hashToken(tokens[0]), hashToken(tokens[1])}, tokenHashes)
+
+	mockRepo.AssertExpectations(t)
+	mockRoleChangeRepo.AssertExpectations(t)
+	mockResetRepo.AssertExpectations(t)
+}
+
+func TestImportEmployeesWithInvalidRows(t *testing.T) {
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmailWithDeleted", "ada@example.com").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindByEmailWithDeleted", "taken@example.com").Return(&models.Employee{ID: 3}, nil)
+
+	// Nothing is created when a single row is invalid
+	service := newTestEmployeeImportService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockPasswordResetTokenRepository), new(mockNotifier))
+	result, err := service.ImportEmployees(9, "hr", strings.NewReader("name,email\nAda Lovelace,ada@example.com\nTaken Name,taken@example.com\n"), false)
+
+	assert.ErrorIs(t, err, ErrInvalidEmployeeImport)
+	assert.Nil(t, result)
+	appErr, ok := apperrors.As(err)
+	require.True(t, ok)
+	assert.Equal(t, []apperrors.FieldError{{Field: "rows[3].email", Message: "already exists"}}, appErr.Fields)
+	mockRepo.AssertExpectations(t)
+}
@@ -6,33 +6,87 @@ import (
 	"hr-leave-request/models"
 	"hr-leave-request/repositories"
 	"math"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type EmployeeService interface {
-	CreateEmployee(req *dtos.CreateEmployeeRequest) (*dtos.EmployeeResponse, error)
-	GetEmployeeByID(id uint) (*dtos.EmployeeResponse, error)
-	GetEmployees(req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error)
+	CreateEmployee(creatorID uint, userRole string, req *dtos.CreateEmployeeRequest) (*dtos.EmployeeResponse, error)
+	GetEmployeeByID(id uint, viewerID uint, userRole string) (*dtos.EmployeeResponse, error)
+	GetEmployees(viewerID uint, userRole string, req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error)
+	GetEmployeeByIDForAPIKey(id uint, apiKey *APIKeyIdentity) (*dtos.EmployeeResponse, error)
+	GetEmployeesForAPIKey(apiKey *APIKeyIdentity, req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error)
+	GetRoleChanges(id uint, userRole string) ([]dtos.EmployeeRoleChangeResponse, error)
+	AssignManager(id uint, userRole string, req *dtos.AssignManagerRequest) (*dtos.EmployeeResponse, error)
+	UpdateEmployee(id uint, actorID uint, userRole string, req *dtos.UpdateEmployeeRequest) (*dtos.EmployeeResponse, error)
+	DeactivateEmployee(id uint, actorID uint, userRole string) (*dtos.EmployeeResponse, error)
+	RestoreEmployee(id uint, userRole string) (*dtos.EmployeeResponse, error)
+	GetProfile(employeeID uint) (*dtos.ProfileResponse, error)
+	UpdateProfile(employeeID uint, req *dtos.UpdateProfileRequest) (*dtos.ProfileResponse, error)
 }
 
 type employeeService struct {
-	repo repositories.EmployeeRepository
+	repo             repositories.EmployeeRepository
+	roleChangeRepo   repositories.EmployeeRoleChangeRepository
+	leaveRequestRepo repositories.LeaveRequestRepository
+	eventRepo        repositories.LeaveRequestEventRepository
+	tokens           TokenService
+	permissions      PermissionChecker
+	transactor       repositories.Transactor
 }
 
-func NewEmployeeService(repo repositories.EmployeeRepository) EmployeeService {
-	return &employeeService{repo: repo}
+func NewEmployeeService(repo repositories.EmployeeRepository, roleChangeRepo repositories.EmployeeRoleChangeRepository, leaveRequestRepo repositories.LeaveRequestRepository, eventRepo repositories.LeaveRequestEventRepository, tokens TokenService, permissions PermissionChecker, transactor repositories.Transactor) EmployeeService {
+	return &employeeService{
+		repo:             repo,
+		roleChangeRepo:   roleChangeRepo,
+		leaveRequestRepo: leaveRequestRepo,
+		eventRepo:        eventRepo,
+		tokens:           tokens,
+		permissions:      permissions,
+		transactor:       transactor,
+	}
 }
 
-func (s *employeeService) CreateEmployee(req *dtos.CreateEmployeeRequest) (*dtos.EmployeeResponse, error) {
-	// Check if email already exists
-	existingEmployee, err := s.repo.FindByEmail(req.Email)
+// CreateEmployee adds an employee with the given role, employee by default.
+// Any other role also needs employee:role:assign.
+func (s *employeeService) CreateEmployee(creatorID uint, userRole string, req *dtos.CreateEmployeeRequest) (*dtos.EmployeeResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !permissions.Has(PermissionEmployeeCreate) {
+		return nil, ErrEmployeeCreateForbidden
+	}
+
+	role := "employee"
+	if req.Role != nil {
+		role = strings.ToLower(strings.TrimSpace(*req.Role))
+	}
+	if err := ensureKnownRole(s.permissions, role); err != nil {
+		return nil, err
+	}
+	if role != "employee" && !permissions.Has(PermissionEmployeeRoleAssign) {
+		return nil, ErrRoleAssignmentForbidden
+	}
+
+	// Check if email already exists, deactivated employees included
+	email := normalizeEmail(req.Email)
+	existingEmployee, err := s.repo.FindByEmailWithDeleted(email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	}
 	if existingEmployee != nil {
-		return nil, errors.New("email already exists")
+		return nil, ErrEmailAlreadyExists
+	}
+
+	// Validate manager exists
+	if req.ManagerID != nil {
+		if _, err := s.findManager(*req.ManagerID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Hash password
@@ -42,32 +96,103 @@ func (s *employeeService) CreateEmployee(req *dtos.CreateEmployeeRequest) (*dtos
 	}
 
 	employee := &models.Employee{
-		Name:     req.Name,
-		Email:    req.Email,
-		Password: string(hashedPassword),
-		Role:     req.Role,
+		Name:      req.Name,
+		Email:     email,
+		Password:  string(hashedPassword),
+		Role:      &role,
+		ManagerID: req.ManagerID,
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(employee); err != nil {
+			return err
+		}
+		return s.roleChangeRepo.WithTx(tx).Create(&models.EmployeeRoleChange{
+			EmployeeID: employee.ID,
+			ActorID:    &creatorID,
+			ToRole:     role,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toEmployeeResponse(employee), nil
+}
+
+// GetEmployeeByID returns an employee the viewer may see; others are reported
+// as not found.
+func (s *employeeService) GetEmployeeByID(id uint, viewerID uint, userRole string) (*dtos.EmployeeResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
 	}
 
-	if err := s.repo.Create(employee); err != nil {
+	if !permissions.Has(PermissionEmployeeRead) && !s.isVisible(employee, viewerID) {
+		return nil, ErrEmployeeNotFound
+	}
+
+	return toEmployeeResponse(employee), nil
+}
+
+// GetEmployees lists the employees the viewer may see. The whole directory
+// needs employee:read; other employees see themselves, their manager and
+// their direct reports.
+func (s *employeeService) GetEmployees(viewerID uint, userRole string, req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
 		return nil, err
 	}
 
-	return s.toEmployeeResponse(employee), nil
+	return s.listEmployees(viewerID, permissions, req)
 }
 
-func (s *employeeService) GetEmployeeByID(id uint) (*dtos.EmployeeResponse, error) {
+// GetEmployeeByIDForAPIKey reads an employee for a service API key, which
+// needs employee:read as it has no employee of its own.
+func (s *employeeService) GetEmployeeByIDForAPIKey(id uint, apiKey *APIKeyIdentity) (*dtos.EmployeeResponse, error) {
+	if !apiKey.Permissions.Has(PermissionEmployeeRead) {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionEmployeeRead)
+	}
+
 	employee, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("employee not found")
+			return nil, ErrEmployeeNotFound
 		}
 		return nil, err
 	}
 
-	return s.toEmployeeResponse(employee), nil
+	return toEmployeeResponse(employee), nil
 }
 
-func (s *employeeService) GetEmployees(req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error) {
+// GetEmployeesForAPIKey lists employees for a service API key, which needs
+// employee:read as it has no employee of its own.
+func (s *employeeService) GetEmployeesForAPIKey(apiKey *APIKeyIdentity, req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error) {
+	if !apiKey.Permissions.Has(PermissionEmployeeRead) {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionEmployeeRead)
+	}
+
+	return s.listEmployees(0, apiKey.Permissions, req)
+}
+
+func (s *employeeService) listEmployees(viewerID uint, permissions Permissions, req *dtos.GetEmployeesRequest) (*dtos.GetEmployeesResponse, error) {
+	var visibleTo *uint
+	if !permissions.Has(PermissionEmployeeRead) {
+		// Deactivated employees are only listed for the whole directory
+		if req.IncludeDeleted {
+			return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionEmployeeRead)
+		}
+		visibleTo = &viewerID
+	}
+
 	// Set default values
 	if req.Page < 1 {
 		req.Page = 1
@@ -85,14 +210,14 @@ func (s *employeeService) GetEmployees(req *dtos.GetEmployeesRequest) (*dtos.Get
 		req.SortBy = "created_at"
 	}
 
-	employees, total, err := s.repo.FindAll(req.Page, req.PageSize, req.Search, req.SortBy, req.SortDir)
+	employees, total, err := s.repo.FindAll(req.Page, req.PageSize, req.Search, req.SortBy, req.SortDir, req.IncludeDeleted, visibleTo)
 	if err != nil {
 		return nil, err
 	}
 
 	employeeResponses := make([]dtos.EmployeeResponse, len(employees))
 	for i, emp := range employees {
-		employeeResponses[i] = *s.toEmployeeResponse(&emp)
+		employeeResponses[i] = *toEmployeeResponse(&emp)
 	}
 
 	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
@@ -108,13 +233,363 @@ func (s *employeeService) GetEmployees(req *dtos.GetEmployeesRequest) (*dtos.Get
 	}, nil
 }
 
-func (s *employeeService) toEmployeeResponse(employee *models.Employee) *dtos.EmployeeResponse {
+// AssignManager sets or clears the line manager who approves the employee's
+// leave requests.
+func (s *employeeService) AssignManager(id uint, userRole string, req *dtos.AssignManagerRequest) (*dtos.EmployeeResponse, error) {
+	// Changing reporting lines needs employee:update
+	allowed, err := s.permissions.HasPermission(userRole, PermissionEmployeeUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrManagerAssignmentForbidden
+	}
+
+	employee, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	if req.ManagerID != nil {
+		if *req.ManagerID == id {
+			return nil, ErrSelfManager
+		}
+
+		// Walk up the new manager's reporting line to make sure it does not
+		// lead back to the employee
+		visited := make(map[uint]bool)
+		for managerID := req.ManagerID; managerID != nil && !visited[*managerID]; {
+			visited[*managerID] = true
+			manager, err := s.findManager(*managerID)
+			if err != nil {
+				return nil, err
+			}
+			if manager.ManagerID != nil && *manager.ManagerID == id {
+				return nil, ErrReportingCycle
+			}
+			managerID = manager.ManagerID
+		}
+	}
+
+	if err := s.repo.UpdateManager(id, req.ManagerID); err != nil {
+		return nil, err
+	}
+	employee.ManagerID = req.ManagerID
+
+	return toEmployeeResponse(employee), nil
+}
+
+// GetRoleChanges returns the role history of an employee, oldest first.
+func (s *employeeService) GetRoleChanges(id uint, userRole string) ([]dtos.EmployeeRoleChangeResponse, error) {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionEmployeeRead)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionEmployeeRead)
+	}
+
+	if _, err := s.findWithDeleted(id); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.roleChangeRepo.FindByEmployeeID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dtos.EmployeeRoleChangeResponse, len(changes))
+	for i, change := range changes {
+		responses[i] = dtos.EmployeeRoleChangeResponse{
+			ID:        change.ID,
+			ActorID:   change.ActorID,
+			FromRole:  change.FromRole,
+			ToRole:    change.ToRole,
+			CreatedAt: change.CreatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// UpdateEmployee changes the name, email address or role of an employee.
+// Changing the role also needs employee:role:assign and is recorded in the
+// role history.
+func (s *employeeService) UpdateEmployee(id uint, actorID uint, userRole string, req *dtos.UpdateEmployeeRequest) (*dtos.EmployeeResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !permissions.Has(PermissionEmployeeUpdate) {
+		return nil, ErrEmployeeUpdateForbidden
+	}
+
+	employee, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	if req.Email != nil {
+		email := normalizeEmail(*req.Email)
+		if email != normalizeEmail(employee.Email) {
+			existingEmployee, err := s.repo.FindByEmailWithDeleted(email)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if existingEmployee != nil && existingEmployee.ID != id {
+				return nil, ErrEmailAlreadyExists
+			}
+		}
+		employee.Email = email
+	}
+	if req.Name != nil {
+		employee.Name = strings.TrimSpace(*req.Name)
+	}
+	var roleChange *models.EmployeeRoleChange
+	if req.Role != nil {
+		role := strings.ToLower(strings.TrimSpace(*req.Role))
+		if employee.Role == nil || *employee.Role != role {
+			if err := ensureKnownRole(s.permissions, role); err != nil {
+				return nil, err
+			}
+			if !permissions.Has(PermissionEmployeeRoleAssign) {
+				return nil, ErrRoleAssignmentForbidden
+			}
+			roleChange = &models.EmployeeRoleChange{
+				EmployeeID: id,
+				ActorID:    &actorID,
+				FromRole:   employee.Role,
+				ToRole:     role,
+			}
+			employee.Role = &role
+		}
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Update(employee); err != nil {
+			return err
+		}
+		if roleChange == nil {
+			return nil
+		}
+		return s.roleChangeRepo.WithTx(tx).Create(roleChange)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toEmployeeResponse(employee), nil
+}
+
+// DeactivateEmployee offboards an employee: the record is soft deleted, so
+// they can no longer sign in, their pending leave requests are cancelled and
+// their sessions are ended. Their history is kept and RestoreEmployee undoes
+// the deactivation.
+func (s *employeeService) DeactivateEmployee(id uint, actorID uint, userRole string) (*dtos.EmployeeResponse, error) {
+	if err := s.checkUpdate(userRole); err != nil {
+		return nil, err
+	}
+	if id == actorID {
+		return nil, ErrSelfDeactivation
+	}
+
+	employee, err := s.findWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if employee.DeletedAt.Valid {
+		return nil, ErrEmployeeDeactivated
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		leaveRequestRepo := s.leaveRequestRepo.WithTx(tx)
+		eventRepo := s.eventRepo.WithTx(tx)
+
+		pending, err := leaveRequestRepo.FindByEmployeeAndStatus(id, "pending")
+		if err != nil {
+			return err
+		}
+		for i := range pending {
+			leaveRequest := &pending[i]
+			previous := *leaveRequest
+			if err := transitionLeaveRequest(leaveRequest, "cancelled"); err != nil {
+				return err
+			}
+			if err := leaveRequestRepo.Update(leaveRequest); err != nil {
+				return err
+			}
+			if err := recordLeaveRequestEvent(eventRepo, leaveRequest.ID, actorID, statusAction("cancelled"), &previous, leaveRequest); err != nil {
+				return err
+			}
+		}
+		return s.repo.WithTx(tx).Delete(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokens.RevokeEmployeeTokens(id); err != nil {
+		return nil, err
+	}
+
+	employee, err = s.findWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	return toEmployeeResponse(employee), nil
+}
+
+// RestoreEmployee reactivates a deactivated employee. Leave requests
+// cancelled on deactivation stay cancelled.
+func (s *employeeService) RestoreEmployee(id uint, userRole string) (*dtos.EmployeeResponse, error) {
+	if err := s.checkUpdate(userRole); err != nil {
+		return nil, err
+	}
+
+	employee, err := s.findWithDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if !employee.DeletedAt.Valid {
+		return nil, ErrEmployeeNotDeactivated
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	employee, err = s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toEmployeeResponse(employee), nil
+}
+
+// GetProfile returns the signed-in employee's own record.
+func (s *employeeService) GetProfile(employeeID uint) (*dtos.ProfileResponse, error) {
+	employee, err := s.repo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	return s.toProfileResponse(employee), nil
+}
+
+// UpdateProfile lets employees change their name and preferences. Email
+// address, role and manager stay with HR.
+func (s *employeeService) UpdateProfile(employeeID uint, req *dtos.UpdateProfileRequest) (*dtos.ProfileResponse, error) {
+	employee, err := s.repo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	if req.Name != nil {
+		employee.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Preferences != nil {
+		if req.Preferences.Locale != nil {
+			employee.Locale = optionalString(*req.Preferences.Locale)
+		}
+		if req.Preferences.Timezone != nil {
+			employee.Timezone = optionalString(*req.Preferences.Timezone)
+		}
+	}
+
+	if err := s.repo.UpdateProfile(employee); err != nil {
+		return nil, err
+	}
+
+	return s.toProfileResponse(employee), nil
+}
+
+func (s *employeeService) checkUpdate(userRole string) error {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionEmployeeUpdate)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrEmployeeUpdateForbidden
+	}
+	return nil
+}
+
+// isVisible reports whether the employee is the viewer, their manager or one
+// of their direct reports.
+func (s *employeeService) isVisible(employee *models.Employee, viewerID uint) bool {
+	if employee.ID == viewerID || (employee.ManagerID != nil && *employee.ManagerID == viewerID) {
+		return true
+	}
+	viewer, err := s.repo.FindByID(viewerID)
+	return err == nil && viewer.ManagerID != nil && *viewer.ManagerID == employee.ID
+}
+
+func (s *employeeService) findWithDeleted(id uint) (*models.Employee, error) {
+	employee, err := s.repo.FindByIDWithDeleted(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+	return employee, nil
+}
+
+func (s *employeeService) findManager(id uint) (*models.Employee, error) {
+	manager, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrManagerNotFound
+		}
+		return nil, err
+	}
+	return manager, nil
+}
+
+func (s *employeeService) toProfileResponse(employee *models.Employee) *dtos.ProfileResponse {
+	return &dtos.ProfileResponse{
+		EmployeeResponse: *toEmployeeResponse(employee),
+		Preferences: dtos.EmployeePreferences{
+			Locale:   employee.Locale,
+			Timezone: employee.Timezone,
+		},
+	}
+}
+
+func toEmployeeResponse(employee *models.Employee) *dtos.EmployeeResponse {
+	var deactivatedAt *time.Time
+	if employee.DeletedAt.Valid {
+		deactivatedAt = &employee.DeletedAt.Time
+	}
+
 	return &dtos.EmployeeResponse{
-		ID:        employee.ID,
-		Name:      employee.Name,
-		Email:     employee.Email,
-		Role:      employee.Role,
-		CreatedAt: employee.CreatedAt,
-		UpdatedAt: employee.UpdatedAt,
+		ID:            employee.ID,
+		Name:          employee.Name,
+		Email:         employee.Email,
+		Role:          employee.Role,
+		ManagerID:     employee.ManagerID,
+		StartDate:     employee.StartDate,
+		CreatedAt:     employee.CreatedAt,
+		UpdatedAt:     employee.UpdatedAt,
+		DeactivatedAt: deactivatedAt,
+	}
+}
+
+// optionalString trims value and returns nil when nothing is left.
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
 	}
+	return &value
 }
@@ -14,27 +14,40 @@ import (
 	"gorm.io/gorm"
 )
 
+// newTestEmployeeService wires an employee service whose token service ends
+// sessions through refreshRepo.
+func newTestEmployeeService(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository, leaveRequestRepo *mocks.MockLeaveRequestRepository, refreshRepo *mocks.MockRefreshTokenRepository) EmployeeService {
+	eventRepo := new(mocks.MockLeaveRequestEventRepository)
+	eventRepo.On("Create", mock.AnythingOfType("*models.LeaveRequestEvent")).Return(nil).Maybe()
+	tokens := NewTokenService(repo, refreshRepo, new(mocks.MockRevokedTokenRepository), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+	return NewEmployeeService(repo, roleChangeRepo, leaveRequestRepo, eventRepo, tokens, newTestPermissionChecker(), &mocks.MockTransactor{})
+}
+
 func TestCreateEmployee(t *testing.T) {
 	role := "employee"
+	managerRole := "manager"
+	unknownRole := "ceo"
 
 	tests := []struct {
 		name      string
+		userRole  string
 		request   *dtos.CreateEmployeeRequest
-		mockSetup func(*mocks.MockEmployeeRepository)
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockEmployeeRoleChangeRepository)
 		wantError bool
 		checkFunc func(*dtos.EmployeeResponse)
 	}{
 		{
-			name: "successful creation",
+			name:     "successful creation",
+			userRole: "hr",
 			request: &dtos.CreateEmployeeRequest{
 				Name:     "John Doe",
-				Email:    "john@example.com",
+				Email:    "John@Example.com",
 				Password: "password123",
 				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
 				// Check email doesn't exist
-				repo.On("FindByEmail", "john@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("FindByEmailWithDeleted", "john@example.com").Return(nil, gorm.ErrRecordNotFound)
 
 				// Create employee
 				repo.On("Create", mock.AnythingOfType("*models.Employee")).
@@ -45,6 +58,11 @@ func TestCreateEmployee(t *testing.T) {
 						emp.CreatedAt = time.Now()
 						emp.UpdatedAt = time.Now()
 					})
+
+				// The initial role is recorded
+				roleChangeRepo.On("Create", mock.MatchedBy(func(change *models.EmployeeRoleChange) bool {
+					return change.EmployeeID == 1 && *change.ActorID == 9 && change.FromRole == nil && change.ToRole == "employee"
+				})).Return(nil)
 			},
 			wantError: false,
 			checkFunc: func(resp *dtos.EmployeeResponse) {
@@ -54,49 +72,106 @@ func TestCreateEmployee(t *testing.T) {
 			},
 		},
 		{
-			name: "email already exists",
+			name:     "email already exists",
+			userRole: "hr",
 			request: &dtos.CreateEmployeeRequest{
 				Name:     "Jane Doe",
 				Email:    "existing@example.com",
 				Password: "password123",
 				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
 				existingEmployee := &models.Employee{
 					ID:    1,
 					Name:  "Existing User",
 					Email: "existing@example.com",
 				}
-				repo.On("FindByEmail", "existing@example.com").Return(existingEmployee, nil)
+				repo.On("FindByEmailWithDeleted", "existing@example.com").Return(existingEmployee, nil)
 			},
 			wantError: true,
 			checkFunc: nil,
 		},
 		{
-			name: "repository error on create",
+			name:     "repository error on create",
+			userRole: "hr",
 			request: &dtos.CreateEmployeeRequest{
 				Name:     "Test User",
 				Email:    "test@example.com",
 				Password: "password123",
 				Role:     &role,
 			},
-			mockSetup: func(repo *mocks.MockEmployeeRepository) {
-				repo.On("FindByEmail", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByEmailWithDeleted", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
 				repo.On("Create", mock.AnythingOfType("*models.Employee")).
 					Return(errors.New("database error"))
 			},
 			wantError: true,
 			checkFunc: nil,
 		},
+		{
+			name:     "role defaults to employee",
+			userRole: "hr",
+			request: &dtos.CreateEmployeeRequest{
+				Name:     "Test User",
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByEmailWithDeleted", "test@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Create", mock.MatchedBy(func(emp *models.Employee) bool {
+					return *emp.Role == "employee"
+				})).Return(nil)
+				roleChangeRepo.On("Create", mock.AnythingOfType("*models.EmployeeRoleChange")).Return(nil)
+			},
+			checkFunc: func(resp *dtos.EmployeeResponse) {
+				assert.Equal(t, "employee", *resp.Role)
+			},
+		},
+		{
+			name:     "employees cannot create employees",
+			userRole: "employee",
+			request: &dtos.CreateEmployeeRequest{
+				Name:     "Test User",
+				Email:    "test@example.com",
+				Password: "password123",
+			},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {},
+			wantError: true,
+		},
+		{
+			name:     "granting a role needs employee:role:assign",
+			userRole: "recruiter",
+			request: &dtos.CreateEmployeeRequest{
+				Name:     "Test User",
+				Email:    "test@example.com",
+				Password: "password123",
+				Role:     &managerRole,
+			},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {},
+			wantError: true,
+		},
+		{
+			name:     "unknown role",
+			userRole: "hr",
+			request: &dtos.CreateEmployeeRequest{
+				Name:     "Test User",
+				Email:    "test@example.com",
+				Password: "password123",
+				Role:     &unknownRole,
+			},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(mocks.MockEmployeeRepository)
-			tt.mockSetup(mockRepo)
+			mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+			tt.mockSetup(mockRepo, mockRoleChangeRepo)
 
-			service := NewEmployeeService(mockRepo)
-			result, err := service.CreateEmployee(tt.request)
+			service := newTestEmployeeService(mockRepo, mockRoleChangeRepo, new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.CreateEmployee(9, tt.userRole, tt.request)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -116,6 +191,7 @@ func TestCreateEmployee(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockRoleChangeRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -124,16 +200,22 @@ func TestGetEmployeeByID(t *testing.T) {
 	role := "employee"
 	now := time.Now()
 
+	managerID := uint(5)
+
 	tests := []struct {
 		name      string
 		id        uint
+		viewerID  uint
+		userRole  string
 		mockSetup func(*mocks.MockEmployeeRepository)
 		wantError bool
 		checkFunc func(*dtos.EmployeeResponse)
 	}{
 		{
-			name: "employee found",
-			id:   1,
+			name:     "employee found",
+			viewerID: 9,
+			userRole: "hr",
+			id:       1,
 			mockSetup: func(repo *mocks.MockEmployeeRepository) {
 				employee := &models.Employee{
 					ID:        1,
@@ -154,8 +236,10 @@ func TestGetEmployeeByID(t *testing.T) {
 			},
 		},
 		{
-			name: "employee not found",
-			id:   999,
+			name:     "employee not found",
+			viewerID: 9,
+			userRole: "hr",
+			id:       999,
 			mockSetup: func(repo *mocks.MockEmployeeRepository) {
 				repo.On("FindByID", uint(999)).Return(nil, gorm.ErrRecordNotFound)
 			},
@@ -163,14 +247,55 @@ func TestGetEmployeeByID(t *testing.T) {
 			checkFunc: nil,
 		},
 		{
-			name: "repository error",
-			id:   2,
+			name:     "repository error",
+			viewerID: 9,
+			userRole: "hr",
+			id:       2,
 			mockSetup: func(repo *mocks.MockEmployeeRepository) {
 				repo.On("FindByID", uint(2)).Return(nil, errors.New("database error"))
 			},
 			wantError: true,
 			checkFunc: nil,
 		},
+		{
+			name:     "employees see themselves",
+			id:       1,
+			viewerID: 1,
+			userRole: "employee",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Role: &role}, nil)
+			},
+		},
+		{
+			name:     "managers see their direct reports",
+			id:       1,
+			viewerID: 5,
+			userRole: "manager",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Role: &role, ManagerID: &managerID}, nil)
+			},
+		},
+		{
+			name:     "employees see their manager",
+			id:       5,
+			viewerID: 1,
+			userRole: "employee",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(5)).Return(&models.Employee{ID: 5, Role: &role}, nil)
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Role: &role, ManagerID: &managerID}, nil)
+			},
+		},
+		{
+			name:     "other employees are not found",
+			id:       2,
+			viewerID: 1,
+			userRole: "employee",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(2)).Return(&models.Employee{ID: 2, Role: &role, ManagerID: &managerID}, nil)
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Role: &role, ManagerID: &managerID}, nil)
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,8 +303,8 @@ func TestGetEmployeeByID(t *testing.T) {
 			mockRepo := new(mocks.MockEmployeeRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewEmployeeService(mockRepo)
-			result, err := service.GetEmployeeByID(tt.id)
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.GetEmployeeByID(tt.id, tt.viewerID, tt.userRole)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -203,13 +328,15 @@ func TestGetEmployees(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		userRole  string
 		request   *dtos.GetEmployeesRequest
 		mockSetup func(*mocks.MockEmployeeRepository)
 		wantError bool
 		checkFunc func(*dtos.GetEmployeesResponse)
 	}{
 		{
-			name: "get employees with default values",
+			name:     "get employees with default values",
+			userRole: "hr",
 			request: &dtos.GetEmployeesRequest{
 				Page:     0,
 				PageSize: 0,
@@ -219,7 +346,7 @@ func TestGetEmployees(t *testing.T) {
 					{ID: 1, Name: "John", Email: "john@example.com", Password: "hash", Role: &role, CreatedAt: now, UpdatedAt: now},
 					{ID: 2, Name: "Jane", Email: "jane@example.com", Password: "hash", Role: &role, CreatedAt: now, UpdatedAt: now},
 				}
-				repo.On("FindAll", 1, 10, "", "created_at", "desc").Return(employees, int64(2), nil)
+				repo.On("FindAll", 1, 10, "", "created_at", "desc", false, (*uint)(nil)).Return(employees, int64(2), nil)
 			},
 			wantError: false,
 			checkFunc: func(resp *dtos.GetEmployeesResponse) {
@@ -231,7 +358,8 @@ func TestGetEmployees(t *testing.T) {
 			},
 		},
 		{
-			name: "get employees with custom pagination",
+			name:     "get employees with custom pagination",
+			userRole: "hr",
 			request: &dtos.GetEmployeesRequest{
 				Page:     2,
 				PageSize: 5,
@@ -243,7 +371,7 @@ func TestGetEmployees(t *testing.T) {
 				employees := []models.Employee{
 					{ID: 6, Name: "Johnny", Email: "johnny@example.com", Password: "hash", Role: &role, CreatedAt: now, UpdatedAt: now},
 				}
-				repo.On("FindAll", 2, 5, "John", "name", "asc").Return(employees, int64(6), nil)
+				repo.On("FindAll", 2, 5, "John", "name", "asc", false, (*uint)(nil)).Return(employees, int64(6), nil)
 			},
 			wantError: false,
 			checkFunc: func(resp *dtos.GetEmployeesResponse) {
@@ -255,14 +383,15 @@ func TestGetEmployees(t *testing.T) {
 			},
 		},
 		{
-			name: "enforce max page size",
+			name:     "enforce max page size",
+			userRole: "hr",
 			request: &dtos.GetEmployeesRequest{
 				Page:     1,
 				PageSize: 150,
 			},
 			mockSetup: func(repo *mocks.MockEmployeeRepository) {
 				employees := []models.Employee{}
-				repo.On("FindAll", 1, 100, "", "created_at", "desc").Return(employees, int64(0), nil)
+				repo.On("FindAll", 1, 100, "", "created_at", "desc", false, (*uint)(nil)).Return(employees, int64(0), nil)
 			},
 			wantError: false,
 			checkFunc: func(resp *dtos.GetEmployeesResponse) {
@@ -270,18 +399,42 @@ func TestGetEmployees(t *testing.T) {
 			},
 		},
 		{
-			name: "repository error",
+			name:     "repository error",
+			userRole: "hr",
 			request: &dtos.GetEmployeesRequest{
 				Page:     1,
 				PageSize: 10,
 			},
 			mockSetup: func(repo *mocks.MockEmployeeRepository) {
-				repo.On("FindAll", 1, 10, "", "created_at", "desc").
+				repo.On("FindAll", 1, 10, "", "created_at", "desc", false, (*uint)(nil)).
 					Return([]models.Employee{}, int64(0), errors.New("database error"))
 			},
 			wantError: true,
 			checkFunc: nil,
 		},
+		{
+			name:     "employees list their own circle",
+			userRole: "employee",
+			request:  &dtos.GetEmployeesRequest{},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				employees := []models.Employee{
+					{ID: 9, Name: "Viewer", Email: "viewer@example.com", Role: &role, CreatedAt: now, UpdatedAt: now},
+				}
+				repo.On("FindAll", 1, 10, "", "created_at", "desc", false, mock.MatchedBy(func(visibleTo *uint) bool {
+					return visibleTo != nil && *visibleTo == 9
+				})).Return(employees, int64(1), nil)
+			},
+			checkFunc: func(resp *dtos.GetEmployeesResponse) {
+				assert.Equal(t, 1, len(resp.Data))
+			},
+		},
+		{
+			name:      "deactivated employees need employee:read",
+			userRole:  "employee",
+			request:   &dtos.GetEmployeesRequest{IncludeDeleted: true},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -289,8 +442,8 @@ func TestGetEmployees(t *testing.T) {
 			mockRepo := new(mocks.MockEmployeeRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewEmployeeService(mockRepo)
-			result, err := service.GetEmployees(tt.request)
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.GetEmployees(9, tt.userRole, tt.request)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -307,3 +460,485 @@ func TestGetEmployees(t *testing.T) {
 		})
 	}
 }
+
+func TestAssignManager(t *testing.T) {
+	managerID := uint(2)
+	selfID := uint(1)
+
+	tests := []struct {
+		name      string
+		userRole  string
+		request   *dtos.AssignManagerRequest
+		mockSetup func(*mocks.MockEmployeeRepository)
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:     "assign manager",
+			userRole: "hr",
+			request:  &dtos.AssignManagerRequest{ManagerID: &managerID},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Name: "John Doe"}, nil)
+				repo.On("FindByID", uint(2)).Return(&models.Employee{ID: 2, Name: "Jane Manager"}, nil)
+				repo.On("UpdateManager", uint(1), &managerID).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "clear manager",
+			userRole: "hr",
+			request:  &dtos.AssignManagerRequest{},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, ManagerID: &managerID}, nil)
+				repo.On("UpdateManager", uint(1), (*uint)(nil)).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:      "only hr can assign",
+			userRole:  "manager",
+			request:   &dtos.AssignManagerRequest{ManagerID: &managerID},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: true,
+			errorMsg:  "not permitted to assign managers",
+		},
+		{
+			name:     "own manager",
+			userRole: "hr",
+			request:  &dtos.AssignManagerRequest{ManagerID: &selfID},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+			},
+			wantError: true,
+			errorMsg:  "employee cannot be their own manager",
+		},
+		{
+			name:     "reporting cycle",
+			userRole: "hr",
+			request:  &dtos.AssignManagerRequest{ManagerID: &managerID},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				thirdID := uint(3)
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindByID", uint(2)).Return(&models.Employee{ID: 2, ManagerID: &thirdID}, nil)
+				repo.On("FindByID", uint(3)).Return(&models.Employee{ID: 3, ManagerID: &selfID}, nil)
+			},
+			wantError: true,
+			errorMsg:  "manager assignment would create a reporting cycle",
+		},
+		{
+			name:     "manager not found",
+			userRole: "hr",
+			request:  &dtos.AssignManagerRequest{ManagerID: &managerID},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindByID", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: true,
+			errorMsg:  "manager not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.AssignManager(1, tt.userRole, tt.request)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.errorMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.request.ManagerID, result.ManagerID)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateEmployee(t *testing.T) {
+	name := "  Jonathan Doe "
+	email := "Jonathan@Example.com"
+	role := "manager"
+	unknownRole := "CEO"
+
+	tests := []struct {
+		name      string
+		userRole  string
+		request   *dtos.UpdateEmployeeRequest
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockEmployeeRoleChangeRepository)
+		wantError error
+		checkFunc func(*dtos.EmployeeResponse)
+	}{
+		{
+			name:     "update name, email and role",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Name: &name, Email: &email, Role: &role},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Name: "Jon Doe", Email: "jon@example.com"}, nil)
+				repo.On("FindByEmailWithDeleted", "jonathan@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Update", mock.MatchedBy(func(emp *models.Employee) bool {
+					return emp.Name == "Jonathan Doe" && emp.Email == "jonathan@example.com" && *emp.Role == "manager"
+				})).Return(nil)
+				roleChangeRepo.On("Create", mock.MatchedBy(func(change *models.EmployeeRoleChange) bool {
+					return change.EmployeeID == 1 && *change.ActorID == 9 && change.FromRole == nil && change.ToRole == "manager"
+				})).Return(nil)
+			},
+			checkFunc: func(resp *dtos.EmployeeResponse) {
+				assert.Equal(t, "Jonathan Doe", resp.Name)
+				assert.Equal(t, "jonathan@example.com", resp.Email)
+				assert.Equal(t, "manager", *resp.Role)
+			},
+		},
+		{
+			name:     "unchanged email is not checked",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Email: &email},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "jonathan@example.com"}, nil)
+				repo.On("Update", mock.AnythingOfType("*models.Employee")).Return(nil)
+			},
+		},
+		{
+			name:     "unchanged role is not recorded",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Role: &role},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Role: &role}, nil)
+				repo.On("Update", mock.AnythingOfType("*models.Employee")).Return(nil)
+			},
+		},
+		{
+			name:     "email taken by a deactivated employee",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Email: &email},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "jon@example.com"}, nil)
+				repo.On("FindByEmailWithDeleted", "jonathan@example.com").
+					Return(&models.Employee{ID: 2, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, nil)
+			},
+			wantError: ErrEmailAlreadyExists,
+		},
+		{
+			name:      "employees cannot update employees",
+			userRole:  "employee",
+			request:   &dtos.UpdateEmployeeRequest{Name: &name},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {},
+			wantError: ErrEmployeeUpdateForbidden,
+		},
+		{
+			name:     "unknown role",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Role: &unknownRole},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+			},
+			wantError: ErrUnknownRole,
+		},
+		{
+			name:     "deactivated employee is not found",
+			userRole: "hr",
+			request:  &dtos.UpdateEmployeeRequest{Name: &name},
+			mockSetup: func(repo *mocks.MockEmployeeRepository, roleChangeRepo *mocks.MockEmployeeRoleChangeRepository) {
+				repo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrEmployeeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+			tt.mockSetup(mockRepo, mockRoleChangeRepo)
+
+			service := newTestEmployeeService(mockRepo, mockRoleChangeRepo, new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.UpdateEmployee(1, 9, tt.userRole, tt.request)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				if tt.checkFunc != nil {
+					tt.checkFunc(result)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockRoleChangeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeactivateEmployee(t *testing.T) {
+	deactivatedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		actorID   uint
+		userRole  string
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockLeaveRequestRepository, *mocks.MockRefreshTokenRepository)
+		wantError error
+	}{
+		{
+			name:     "deactivate and cancel pending leave",
+			actorID:  9,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, leaveRepo *mocks.MockLeaveRequestRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByIDWithDeleted", uint(1)).Return(&models.Employee{ID: 1}, nil).Once()
+				leaveRepo.On("FindByEmployeeAndStatus", uint(1), "pending").Return([]models.LeaveRequest{
+					{ID: 3, EmployeeID: 1, Status: "pending"},
+					{ID: 4, EmployeeID: 1, Status: "pending"},
+				}, nil)
+				leaveRepo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "cancelled"
+				})).Return(nil).Twice()
+				repo.On("Delete", uint(1)).Return(nil)
+				refreshRepo.On("RevokeByEmployee", uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+				repo.On("FindByIDWithDeleted", uint(1)).
+					Return(&models.Employee{ID: 1, DeletedAt: gorm.DeletedAt{Time: deactivatedAt, Valid: true}}, nil).Once()
+			},
+		},
+		{
+			name:     "already deactivated",
+			actorID:  9,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, leaveRepo *mocks.MockLeaveRequestRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByIDWithDeleted", uint(1)).
+					Return(&models.Employee{ID: 1, DeletedAt: gorm.DeletedAt{Time: deactivatedAt, Valid: true}}, nil)
+			},
+			wantError: ErrEmployeeDeactivated,
+		},
+		{
+			name:     "cannot deactivate yourself",
+			actorID:  1,
+			userRole: "hr",
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockLeaveRequestRepository, *mocks.MockRefreshTokenRepository) {
+			},
+			wantError: ErrSelfDeactivation,
+		},
+		{
+			name:     "managers cannot deactivate",
+			actorID:  9,
+			userRole: "manager",
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockLeaveRequestRepository, *mocks.MockRefreshTokenRepository) {
+			},
+			wantError: ErrEmployeeUpdateForbidden,
+		},
+		{
+			name:     "employee not found",
+			actorID:  9,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, leaveRepo *mocks.MockLeaveRequestRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByIDWithDeleted", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrEmployeeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			mockLeaveRepo := new(mocks.MockLeaveRequestRepository)
+			mockRefreshRepo := new(mocks.MockRefreshTokenRepository)
+			tt.mockSetup(mockRepo, mockLeaveRepo, mockRefreshRepo)
+
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), mockLeaveRepo, mockRefreshRepo)
+			result, err := service.DeactivateEmployee(1, tt.actorID, tt.userRole)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result.DeactivatedAt)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockLeaveRepo.AssertExpectations(t)
+			mockRefreshRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestoreEmployee(t *testing.T) {
+	tests := []struct {
+		name      string
+		userRole  string
+		mockSetup func(*mocks.MockEmployeeRepository)
+		wantError error
+	}{
+		{
+			name:     "restore deactivated employee",
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByIDWithDeleted", uint(1)).
+					Return(&models.Employee{ID: 1, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, nil)
+				repo.On("Restore", uint(1)).Return(nil)
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+			},
+		},
+		{
+			name:     "active employee",
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByIDWithDeleted", uint(1)).Return(&models.Employee{ID: 1}, nil)
+			},
+			wantError: ErrEmployeeNotDeactivated,
+		},
+		{
+			name:      "employees cannot restore",
+			userRole:  "employee",
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {},
+			wantError: ErrEmployeeUpdateForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.RestoreEmployee(1, tt.userRole)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Nil(t, result.DeactivatedAt)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRoleChanges(t *testing.T) {
+	employeeRole := "employee"
+	actorID := uint(9)
+	changes := []models.EmployeeRoleChange{
+		{ID: 1, EmployeeID: 1, ToRole: "employee"},
+		{ID: 2, EmployeeID: 1, ActorID: &actorID, FromRole: &employeeRole, ToRole: "manager"},
+	}
+
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByIDWithDeleted", uint(1)).Return(&models.Employee{ID: 1}, nil)
+	mockRepo.On("FindByIDWithDeleted", uint(2)).Return(nil, gorm.ErrRecordNotFound)
+	mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+	mockRoleChangeRepo.On("FindByEmployeeID", uint(1)).Return(changes, nil)
+	service := newTestEmployeeService(mockRepo, mockRoleChangeRepo, new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+
+	result, err := service.GetRoleChanges(1, "hr")
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Nil(t, result[0].FromRole)
+	assert.Nil(t, result[0].ActorID)
+	assert.Equal(t, uint(9), *result[1].ActorID)
+	assert.Equal(t, "employee", *result[1].FromRole)
+	assert.Equal(t, "manager", result[1].ToRole)
+
+	_, err = service.GetRoleChanges(2, "hr")
+	assert.ErrorIs(t, err, ErrEmployeeNotFound)
+
+	_, err = service.GetRoleChanges(1, "employee")
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestGetEmployeesForAPIKey(t *testing.T) {
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindAll", 1, 10, "", "created_at", "desc", false, (*uint)(nil)).Return([]models.Employee{{ID: 1}}, int64(1), nil)
+	service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+
+	reader := &APIKeyIdentity{ID: 1, Permissions: Permissions{PermissionEmployeeRead: true}}
+	result, err := service.GetEmployeesForAPIKey(reader, &dtos.GetEmployeesRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+
+	other := &APIKeyIdentity{ID: 2, Permissions: Permissions{PermissionLeaveReadAll: true}}
+	_, err = service.GetEmployeesForAPIKey(other, &dtos.GetEmployeesRequest{})
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = service.GetEmployeeByIDForAPIKey(1, other)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestUpdateProfile(t *testing.T) {
+	name := " Jane Doe "
+	locale := "de-DE"
+	timezone := "Europe/Berlin"
+	empty := ""
+	oldLocale := "en-GB"
+
+	tests := []struct {
+		name      string
+		request   *dtos.UpdateProfileRequest
+		mockSetup func(*mocks.MockEmployeeRepository)
+		wantError error
+		checkFunc func(*dtos.ProfileResponse)
+	}{
+		{
+			name:    "update name and preferences",
+			request: &dtos.UpdateProfileRequest{Name: &name, Preferences: &dtos.EmployeePreferences{Locale: &locale, Timezone: &timezone}},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Name: "Jane", Email: "jane@example.com"}, nil)
+				repo.On("UpdateProfile", mock.MatchedBy(func(emp *models.Employee) bool {
+					return emp.Name == "Jane Doe" && *emp.Locale == "de-DE" && *emp.Timezone == "Europe/Berlin"
+				})).Return(nil)
+			},
+			checkFunc: func(resp *dtos.ProfileResponse) {
+				assert.Equal(t, "Jane Doe", resp.Name)
+				assert.Equal(t, "jane@example.com", resp.Email)
+				assert.Equal(t, "de-DE", *resp.Preferences.Locale)
+				assert.Equal(t, "Europe/Berlin", *resp.Preferences.Timezone)
+			},
+		},
+		{
+			name:    "empty preference is cleared and omitted ones kept",
+			request: &dtos.UpdateProfileRequest{Preferences: &dtos.EmployeePreferences{Timezone: &empty}},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Name: "Jane", Locale: &oldLocale, Timezone: &timezone}, nil)
+				repo.On("UpdateProfile", mock.MatchedBy(func(emp *models.Employee) bool {
+					return emp.Name == "Jane" && *emp.Locale == "en-GB" && emp.Timezone == nil
+				})).Return(nil)
+			},
+			checkFunc: func(resp *dtos.ProfileResponse) {
+				assert.Equal(t, "en-GB", *resp.Preferences.Locale)
+				assert.Nil(t, resp.Preferences.Timezone)
+			},
+		},
+		{
+			name:    "deactivated employee",
+			request: &dtos.UpdateProfileRequest{Name: &name},
+			mockSetup: func(repo *mocks.MockEmployeeRepository) {
+				repo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrEmployeeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := newTestEmployeeService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), new(mocks.MockLeaveRequestRepository), new(mocks.MockRefreshTokenRepository))
+			result, err := service.UpdateProfile(1, tt.request)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				tt.checkFunc(result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
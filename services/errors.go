@@ -0,0 +1,90 @@
+package services
+
+import "hr-leave-request/apperrors"
+
+// Domain errors returned by the services. Handlers pass them on unchanged and
+// the central error handler renders their code and message.
+var (
+	ErrInvalidCredentials   = apperrors.Unauthorized("invalid_credentials", "invalid email or password")
+	ErrTooManyLoginAttempts = apperrors.RateLimited("too_many_login_attempts", "too many failed login attempts, try again later")
+	ErrPermissionDenied     = apperrors.Forbidden("permission_denied", "missing permission for this action")
+	ErrInvalidToken         = apperrors.Unauthorized("invalid_token", "invalid or expired token")
+	ErrTokenRevoked         = apperrors.Unauthorized("token_revoked", "token has been revoked")
+	ErrInvalidRefreshToken  = apperrors.Unauthorized("invalid_refresh_token", "invalid or expired refresh token")
+
+	ErrInvalidResetToken      = apperrors.Validation("invalid_reset_token", "password reset token is invalid, expired or already used")
+	ErrInvalidCurrentPassword = apperrors.Validation("invalid_current_password", "current password is incorrect")
+
+	ErrInvalidMFAToken       = apperrors.Unauthorized("invalid_mfa_token", "invalid or expired two-factor login token")
+	ErrInvalidMFACode        = apperrors.Validation("invalid_mfa_code", "invalid two-factor authentication code")
+	ErrMFAAlreadyEnabled     = apperrors.Conflict("mfa_already_enabled", "two-factor authentication is already enabled")
+	ErrMFANotEnrolled        = apperrors.Conflict("mfa_not_enrolled", "two-factor authentication is not set up")
+	ErrMFARequired           = apperrors.Forbidden("mfa_required", "two-factor authentication is required for your role")
+	ErrMFAEnrollmentRequired = apperrors.Forbidden("mfa_enrollment_required", "set up two-factor authentication to continue")
+
+	ErrOIDCDisabled        = apperrors.NotFound("oidc_disabled", "single sign-on is not enabled")
+	ErrInvalidOIDCState    = apperrors.Unauthorized("invalid_oidc_state", "single sign-on session is invalid or expired, sign in again")
+	ErrOIDCLoginFailed     = apperrors.Unauthorized("oidc_login_failed", "single sign-on login failed")
+	ErrOIDCAccountNotFound = apperrors.Forbidden("oidc_account_not_found", "no employee account for this identity, ask HR for access")
+
+	ErrInvalidAPIKey           = apperrors.Unauthorized("invalid_api_key", "invalid, expired or revoked API key")
+	ErrAPIKeyNotFound          = apperrors.NotFound("api_key_not_found", "API key not found")
+	ErrAPIKeyRevoked           = apperrors.Conflict("api_key_revoked", "API key has already been revoked")
+	ErrAPIKeyForbidden         = apperrors.Forbidden("api_key_forbidden", "not permitted to manage API keys")
+	ErrInvalidAPIKeyPermission = apperrors.Validation("invalid_api_key_permission", "API keys can only be granted read permissions that you hold")
+	ErrInvalidAPIKeyExpiry     = apperrors.Validation("invalid_api_key_expiry", "API key expiry must be in the future")
+
+	ErrRegistrationDisabled = apperrors.Forbidden("registration_disabled", "self-registration is disabled, ask HR for an invitation")
+	ErrInvalidInvitation    = apperrors.Validation("invalid_invitation", "invitation is invalid, expired or already used")
+	ErrInvitationNotFound   = apperrors.NotFound("invitation_not_found", "invitation not found")
+	ErrInvitationClosed     = apperrors.Conflict("invitation_closed", "invitation has already been accepted or revoked")
+	ErrInvitationForbidden  = apperrors.Forbidden("invitation_forbidden", "not permitted to invite employees")
+
+	ErrEmployeeNotFound           = apperrors.NotFound("employee_not_found", "employee not found")
+	ErrEmailAlreadyExists         = apperrors.Conflict("email_already_exists", "email already exists")
+	ErrManagerNotFound            = apperrors.Validation("manager_not_found", "manager not found")
+	ErrSelfManager                = apperrors.Validation("self_manager", "employee cannot be their own manager")
+	ErrReportingCycle             = apperrors.Conflict("reporting_cycle", "manager assignment would create a reporting cycle")
+	ErrManagerAssignmentForbidden = apperrors.Forbidden("manager_assignment_forbidden", "not permitted to assign managers")
+	ErrEmployeeCreateForbidden    = apperrors.Forbidden("employee_create_forbidden", "not permitted to create employees")
+	ErrEmployeeUpdateForbidden    = apperrors.Forbidden("employee_update_forbidden", "not permitted to update employees")
+	ErrRoleAssignmentForbidden    = apperrors.Forbidden("role_assignment_forbidden", "not permitted to assign roles")
+	ErrUnknownRole                = apperrors.Validation("unknown_role", "unknown role")
+	ErrEmployeeDeactivated        = apperrors.Conflict("employee_deactivated", "employee is already deactivated")
+	ErrEmployeeNotDeactivated     = apperrors.Conflict("employee_not_deactivated", "employee is not deactivated")
+	ErrSelfDeactivation           = apperrors.Validation("self_deactivation", "you cannot deactivate your own account")
+	ErrInvalidEmployeeImportFile  = apperrors.Validation("invalid_employee_import_file", "invalid employee import file")
+	ErrInvalidEmployeeImport      = apperrors.Validation("invalid_employee_import", "employee import has invalid rows")
+
+	ErrHolidayNotFound            = apperrors.NotFound("holiday_not_found", "holiday not found")
+	ErrHolidayAlreadyExists       = apperrors.Conflict("holiday_already_exists", "holiday already exists for this date")
+	ErrInvalidHolidayDate         = apperrors.Validation("invalid_holiday_date", "invalid holiday date")
+	ErrInvalidCalendarFile        = apperrors.Validation("invalid_calendar_file", "invalid calendar file")
+	ErrHolidayManagementForbidden = apperrors.Forbidden("holiday_management_forbidden", "not permitted to manage holidays")
+
+	ErrInsufficientBalance        = apperrors.Validation("insufficient_leave_balance", "insufficient leave balance for this leave type")
+	ErrEntitlementBelowUsed       = apperrors.Validation("entitlement_below_used", "entitlement cannot be less than the days already used")
+	ErrBalanceManagementForbidden = apperrors.Forbidden("balance_management_forbidden", "not permitted to manage leave balances")
+
+	ErrLeaveRequestNotFound    = apperrors.NotFound("leave_request_not_found", "leave request not found")
+	ErrInvalidDateRange        = apperrors.Validation("invalid_date_range", "start date cannot be after end date")
+	ErrLeaveRequestInPast      = apperrors.Validation("leave_request_in_past", "cannot create leave request for past dates")
+	ErrOverlappingLeave        = apperrors.Validation("overlapping_leave", "overlapping approved leave request exists for this date range")
+	ErrNoWorkingDays           = apperrors.Validation("no_working_days", "leave request falls entirely on weekends or holidays")
+	ErrInvalidGranularity      = apperrors.Validation("invalid_leave_granularity", "invalid leave granularity")
+	ErrHalfDayMultipleDays     = apperrors.Validation("half_day_spans_multiple_days", "half-day leave must start and end on the same day")
+	ErrHourlyMultipleDays      = apperrors.Validation("hourly_leave_spans_multiple_days", "hourly leave must start and end on the same day")
+	ErrHourlyEndsBeforeStart   = apperrors.Validation("hourly_leave_ends_before_start", "hourly leave must end after it starts")
+	ErrHourlyExceedsWorkingDay = apperrors.Validation("hourly_leave_exceeds_working_day", "hourly leave cannot exceed a working day")
+	ErrRejectionReasonRequired = apperrors.Validation("rejection_reason_required", "rejection reason is required")
+	ErrLeaveRequestNotEditable = apperrors.Conflict("leave_request_not_editable", "only draft or pending leave requests can be updated")
+
+	ErrUpdateForbidden       = apperrors.Forbidden("update_forbidden", "unauthorized to update this leave request")
+	ErrDeleteForbidden       = apperrors.Forbidden("delete_forbidden", "unauthorized to delete this leave request")
+	ErrCancelForbidden       = apperrors.Forbidden("cancel_forbidden", "unauthorized to cancel this leave request")
+	ErrSubmitForbidden       = apperrors.Forbidden("submit_forbidden", "only the requester can submit this leave request")
+	ErrWithdrawForbidden     = apperrors.Forbidden("withdraw_forbidden", "only the requester can withdraw this leave request")
+	ErrApproveForbidden      = apperrors.Forbidden("approve_forbidden", "only the employee's manager or HR can approve leave requests")
+	ErrRejectForbidden       = apperrors.Forbidden("reject_forbidden", "only the employee's manager or HR can reject leave requests")
+	ErrApprovalStepForbidden = apperrors.Forbidden("approval_step_forbidden", "not authorized to decide the current approval step")
+)
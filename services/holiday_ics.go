@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bufio"
+	"hr-leave-request/models"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxHolidayEventDays bounds how many days a single VEVENT may expand to, so a
+// malformed calendar cannot flood the holidays table.
+const maxHolidayEventDays = 31
+
+type icsProperty struct {
+	params map[string]string
+	value  string
+}
+
+// parseICSHolidays reads an iCalendar (RFC 5545) document and returns one
+// holiday for every day covered by each of its VEVENTs. When several events
+// fall on the same date the last one wins.
+func parseICSHolidays(r io.Reader) ([]models.Holiday, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]int)
+	var holidays []models.Holiday
+	var event map[string]icsProperty
+
+	for _, line := range lines {
+		name, property, ok := parseICSLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(property.value, "VEVENT"):
+			event = make(map[string]icsProperty)
+		case name == "END" && strings.EqualFold(property.value, "VEVENT"):
+			if event == nil {
+				return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: unexpected END:VEVENT")
+			}
+			eventHolidays, err := icsEventHolidays(event)
+			if err != nil {
+				return nil, err
+			}
+			for _, holiday := range eventHolidays {
+				key := holiday.Date.Format(time.DateOnly)
+				if i, exists := byDate[key]; exists {
+					holidays[i] = holiday
+					continue
+				}
+				byDate[key] = len(holidays)
+				holidays = append(holidays, holiday)
+			}
+			event = nil
+		case event != nil:
+			event[name] = property
+		}
+	}
+
+	if event != nil {
+		return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: unterminated VEVENT")
+	}
+	if len(holidays) == 0 {
+		return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: no holidays found")
+	}
+
+	return holidays, nil
+}
+
+// unfoldICSLines splits the document into logical lines, joining continuation
+// lines that start with a space or a tab onto the previous line.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: %v", err).Wrap(err)
+	}
+	return lines, nil
+}
+
+// parseICSLine splits a content line such as "DTSTART;VALUE=DATE:20261225"
+// into its upper-cased name, parameters and value.
+func parseICSLine(line string) (string, icsProperty, bool) {
+	inQuotes := false
+	colon := -1
+	for i, ch := range line {
+		if ch == '"' {
+			inQuotes = !inQuotes
+		}
+		if ch == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), icsProperty{params: params, value: line[colon+1:]}, true
+}
+
+func icsEventHolidays(event map[string]icsProperty) ([]models.Holiday, error) {
+	start, ok := event["DTSTART"]
+	if !ok {
+		return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: VEVENT without DTSTART")
+	}
+
+	startDate, allDay, err := parseICSDate(start)
+	if err != nil {
+		return nil, err
+	}
+
+	// DTEND is exclusive for all-day events; timed events cover every date
+	// they touch, unless they end exactly at midnight.
+	lastDate := startDate
+	if end, ok := event["DTEND"]; ok {
+		endDate, _, err := parseICSDate(end)
+		if err != nil {
+			return nil, err
+		}
+		endIsMidnight := len(end.value) >= 15 && end.value[9:15] == "000000"
+		if allDay || endIsMidnight {
+			endDate = endDate.AddDate(0, 0, -1)
+		}
+		if endDate.After(lastDate) {
+			lastDate = endDate
+		}
+	}
+
+	name := unescapeICSText(event["SUMMARY"].value)
+	if name == "" {
+		name = "Holiday"
+	}
+	if runes := []rune(name); len(runes) > 150 {
+		name = string(runes[:150])
+	}
+
+	var uid *string
+	if property, ok := event["UID"]; ok && property.value != "" {
+		value := property.value
+		uid = &value
+	}
+
+	var holidays []models.Holiday
+	for day := startDate; !day.After(lastDate); day = day.AddDate(0, 0, 1) {
+		if len(holidays) == maxHolidayEventDays {
+			return nil, ErrInvalidCalendarFile.Withf("invalid calendar file: holiday %q spans more than %d days", name, maxHolidayEventDays)
+		}
+		holidays = append(holidays, models.Holiday{
+			Date:   day,
+			Name:   name,
+			Source: "ics",
+			UID:    uid,
+		})
+	}
+
+	return holidays, nil
+}
+
+// parseICSDate returns the calendar date of a DATE or DATE-TIME property and
+// whether it was an all-day DATE value.
+func parseICSDate(property icsProperty) (time.Time, bool, error) {
+	value := property.value
+	if len(value) < 8 {
+		return time.Time{}, false, ErrInvalidCalendarFile.Withf("invalid calendar file: invalid date %q", value)
+	}
+
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, false, ErrInvalidCalendarFile.Withf("invalid calendar file: invalid date %q", value)
+	}
+
+	allDay := property.params["VALUE"] == "DATE" || len(value) == 8
+	return date, allDay, nil
+}
+
+func unescapeICSText(text string) string {
+	replacer := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(text))
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type HolidayService interface {
+	CreateHoliday(userRole string, req *dtos.CreateHolidayRequest) (*dtos.HolidayResponse, error)
+	GetHolidayByID(id uint) (*dtos.HolidayResponse, error)
+	GetHolidays(req *dtos.GetHolidaysRequest) ([]dtos.HolidayResponse, error)
+	UpdateHoliday(id uint, userRole string, req *dtos.UpdateHolidayRequest) (*dtos.HolidayResponse, error)
+	DeleteHoliday(id uint, userRole string) error
+	ImportHolidays(userRole string, calendar io.Reader) (*dtos.ImportHolidaysResponse, error)
+}
+
+type holidayService struct {
+	repo        repositories.HolidayRepository
+	permissions PermissionChecker
+}
+
+func NewHolidayService(repo repositories.HolidayRepository, permissions PermissionChecker) HolidayService {
+	return &holidayService{
+		repo:        repo,
+		permissions: permissions,
+	}
+}
+
+func (s *holidayService) CreateHoliday(userRole string, req *dtos.CreateHolidayRequest) (*dtos.HolidayResponse, error) {
+	if err := s.ensureCanManage(userRole); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return nil, ErrInvalidHolidayDate
+	}
+
+	if err := s.ensureDateAvailable(date, nil); err != nil {
+		return nil, err
+	}
+
+	holiday := &models.Holiday{
+		Date:   date,
+		Name:   req.Name,
+		Source: "manual",
+	}
+
+	if err := s.repo.Create(holiday); err != nil {
+		return nil, err
+	}
+
+	return s.toHolidayResponse(holiday), nil
+}
+
+func (s *holidayService) GetHolidayByID(id uint) (*dtos.HolidayResponse, error) {
+	holiday, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+
+	return s.toHolidayResponse(holiday), nil
+}
+
+func (s *holidayService) GetHolidays(req *dtos.GetHolidaysRequest) ([]dtos.HolidayResponse, error) {
+	// Default to the current year
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+
+	startDate := time.Date(req.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(req.Year, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	holidays, err := s.repo.FindBetween(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	holidayResponses := make([]dtos.HolidayResponse, len(holidays))
+	for i, holiday := range holidays {
+		holidayResponses[i] = *s.toHolidayResponse(&holiday)
+	}
+
+	return holidayResponses, nil
+}
+
+func (s *holidayService) UpdateHoliday(id uint, userRole string, req *dtos.UpdateHolidayRequest) (*dtos.HolidayResponse, error) {
+	if err := s.ensureCanManage(userRole); err != nil {
+		return nil, err
+	}
+
+	holiday, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+
+	if req.Date != nil {
+		date, err := time.Parse(time.DateOnly, *req.Date)
+		if err != nil {
+			return nil, ErrInvalidHolidayDate
+		}
+		if err := s.ensureDateAvailable(date, &id); err != nil {
+			return nil, err
+		}
+		holiday.Date = date
+	}
+	if req.Name != nil {
+		holiday.Name = *req.Name
+	}
+
+	if err := s.repo.Update(holiday); err != nil {
+		return nil, err
+	}
+
+	return s.toHolidayResponse(holiday), nil
+}
+
+func (s *holidayService) DeleteHoliday(id uint, userRole string) error {
+	if err := s.ensureCanManage(userRole); err != nil {
+		return err
+	}
+
+	_, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrHolidayNotFound
+		}
+		return err
+	}
+
+	return s.repo.Delete(id)
+}
+
+func (s *holidayService) ImportHolidays(userRole string, calendar io.Reader) (*dtos.ImportHolidaysResponse, error) {
+	if err := s.ensureCanManage(userRole); err != nil {
+		return nil, err
+	}
+
+	holidays, err := parseICSHolidays(calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Upsert(holidays); err != nil {
+		return nil, err
+	}
+
+	firstDate, lastDate := holidays[0].Date, holidays[0].Date
+	for _, holiday := range holidays {
+		if holiday.Date.Before(firstDate) {
+			firstDate = holiday.Date
+		}
+		if holiday.Date.After(lastDate) {
+			lastDate = holiday.Date
+		}
+	}
+
+	return &dtos.ImportHolidaysResponse{
+		Imported:  len(holidays),
+		FirstDate: firstDate.Format(time.DateOnly),
+		LastDate:  lastDate.Format(time.DateOnly),
+	}, nil
+}
+
+// ensureCanManage checks that the role holds holiday:manage.
+func (s *holidayService) ensureCanManage(userRole string) error {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionHolidayManage)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrHolidayManagementForbidden
+	}
+	return nil
+}
+
+// ensureDateAvailable rejects a date that already has a holiday other than
+// the one identified by excludeID.
+func (s *holidayService) ensureDateAvailable(date time.Time, excludeID *uint) error {
+	existing, err := s.repo.FindByDate(date)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if existing != nil && (excludeID == nil || existing.ID != *excludeID) {
+		return ErrHolidayAlreadyExists
+	}
+	return nil
+}
+
+func (s *holidayService) toHolidayResponse(holiday *models.Holiday) *dtos.HolidayResponse {
+	return &dtos.HolidayResponse{
+		ID:        holiday.ID,
+		Date:      holiday.Date.Format(time.DateOnly),
+		Name:      holiday.Name,
+		Source:    holiday.Source,
+		CreatedAt: holiday.CreatedAt,
+		UpdatedAt: holiday.UpdatedAt,
+	}
+}
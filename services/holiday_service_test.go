@@ -0,0 +1,184 @@
+package services
+
+import (
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+const testHolidayCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Example//Holidays//EN\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:new-year-2027@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20270101\r\n" +
+	"DTEND;VALUE=DATE:20270102\r\n" +
+	"SUMMARY:New Year\\'s Day\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:christmas-2026@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20261225\r\n" +
+	"DTEND;VALUE=DATE:20261227\r\n" +
+	"SUMMARY:Christmas\\, Boxing\r\n" +
+	"  Day\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICSHolidays(t *testing.T) {
+	tests := []struct {
+		name      string
+		calendar  string
+		wantError bool
+		checkFunc func([]models.Holiday)
+	}{
+		{
+			name:      "all-day events expand per day",
+			calendar:  testHolidayCalendar,
+			wantError: false,
+			checkFunc: func(holidays []models.Holiday) {
+				assert.Len(t, holidays, 3)
+				assert.Equal(t, "2027-01-01", holidays[0].Date.Format(time.DateOnly))
+				assert.Equal(t, "2026-12-25", holidays[1].Date.Format(time.DateOnly))
+				assert.Equal(t, "2026-12-26", holidays[2].Date.Format(time.DateOnly))
+				assert.Equal(t, "Christmas, Boxing Day", holidays[1].Name)
+				assert.Equal(t, "ics", holidays[1].Source)
+				assert.Equal(t, "christmas-2026@example.com", *holidays[1].UID)
+			},
+		},
+		{
+			name: "timed event without end",
+			calendar: "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260501T000000Z\n" +
+				"SUMMARY:Labour Day\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantError: false,
+			checkFunc: func(holidays []models.Holiday) {
+				assert.Len(t, holidays, 1)
+				assert.Equal(t, "2026-05-01", holidays[0].Date.Format(time.DateOnly))
+				assert.Nil(t, holidays[0].UID)
+			},
+		},
+		{
+			name:      "no events",
+			calendar:  "BEGIN:VCALENDAR\nVERSION:2.0\nEND:VCALENDAR\n",
+			wantError: true,
+		},
+		{
+			name:      "event without start",
+			calendar:  "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:Broken\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantError: true,
+		},
+		{
+			name:      "unterminated event",
+			calendar:  "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20260101\n",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holidays, err := parseICSHolidays(strings.NewReader(tt.calendar))
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.True(t, strings.HasPrefix(err.Error(), "invalid calendar file"))
+			} else {
+				assert.NoError(t, err)
+				if tt.checkFunc != nil {
+					tt.checkFunc(holidays)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateHoliday(t *testing.T) {
+	tests := []struct {
+		name      string
+		userRole  string
+		request   *dtos.CreateHolidayRequest
+		mockSetup func(*mocks.MockHolidayRepository)
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:     "successful creation",
+			userRole: "hr",
+			request:  &dtos.CreateHolidayRequest{Date: "2026-08-17", Name: "Independence Day"},
+			mockSetup: func(repo *mocks.MockHolidayRepository) {
+				repo.On("FindByDate", time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC)).Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Create", mock.AnythingOfType("*models.Holiday")).
+					Return(nil).
+					Run(func(args mock.Arguments) {
+						args.Get(0).(*models.Holiday).ID = 1
+					})
+			},
+			wantError: false,
+		},
+		{
+			name:      "only hr can create",
+			userRole:  "employee",
+			request:   &dtos.CreateHolidayRequest{Date: "2026-08-17", Name: "Independence Day"},
+			mockSetup: func(repo *mocks.MockHolidayRepository) {},
+			wantError: true,
+			errorMsg:  "not permitted to manage holidays",
+		},
+		{
+			name:     "date already taken",
+			userRole: "hr",
+			request:  &dtos.CreateHolidayRequest{Date: "2026-08-17", Name: "Independence Day"},
+			mockSetup: func(repo *mocks.MockHolidayRepository) {
+				existing := &models.Holiday{ID: 3, Name: "Existing"}
+				repo.On("FindByDate", time.Date(2026, 8, 17, 0, 0, 0, 0, time.UTC)).Return(existing, nil)
+			},
+			wantError: true,
+			errorMsg:  "holiday already exists for this date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockHolidayRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewHolidayService(mockRepo, newTestPermissionChecker())
+			result, err := service.CreateHoliday(tt.userRole, tt.request)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				if tt.errorMsg != "" {
+					assert.Equal(t, tt.errorMsg, err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), result.ID)
+				assert.Equal(t, "2026-08-17", result.Date)
+				assert.Equal(t, "manual", result.Source)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportHolidays(t *testing.T) {
+	mockRepo := new(mocks.MockHolidayRepository)
+	mockRepo.On("Upsert", mock.MatchedBy(func(holidays []models.Holiday) bool {
+		return len(holidays) == 3
+	})).Return(nil)
+
+	service := NewHolidayService(mockRepo, newTestPermissionChecker())
+	result, err := service.ImportHolidays("hr", strings.NewReader(testHolidayCalendar))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	assert.Equal(t, "2026-12-25", result.FirstDate)
+	assert.Equal(t, "2027-01-01", result.LastDate)
+	mockRepo.AssertExpectations(t)
+}
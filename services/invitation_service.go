@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// invitationTokenPurpose sets invitation tokens apart from the access tokens
+// signed with the same keys.
+const invitationTokenPurpose = "invitation"
+
+type InvitationService interface {
+	CreateInvitation(inviterID uint, userRole string, req *dtos.CreateInvitationRequest) (*dtos.InvitationResponse, error)
+	RevokeInvitation(id uint, userRole string) error
+}
+
+type invitationService struct {
+	repo         repositories.InvitationRepository
+	employeeRepo repositories.EmployeeRepository
+	permissions  PermissionChecker
+	keys         JWTKeys
+	cfg          *config.ApplicationConfig
+}
+
+func NewInvitationService(repo repositories.InvitationRepository, employeeRepo repositories.EmployeeRepository, permissions PermissionChecker, keys JWTKeys, cfg *config.ApplicationConfig) InvitationService {
+	return &invitationService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+		permissions:  permissions,
+		keys:         keys,
+		cfg:          cfg,
+	}
+}
+
+// CreateInvitation invites a new employee with the given role and returns the
+// signed token the invitee registers with. As for CreateEmployee, any role
+// other than employee also needs employee:role:assign.
+func (s *invitationService) CreateInvitation(inviterID uint, userRole string, req *dtos.CreateInvitationRequest) (*dtos.InvitationResponse, error) {
+	// Inviting needs the same permissions as creating an employee
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !permissions.Has(PermissionEmployeeCreate) {
+		return nil, ErrInvitationForbidden
+	}
+
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if err := ensureKnownRole(s.permissions, role); err != nil {
+		return nil, err
+	}
+	if role != "employee" && !permissions.Has(PermissionEmployeeRoleAssign) {
+		return nil, ErrRoleAssignmentForbidden
+	}
+
+	// Check if email already exists, deactivated employees included
+	email := normalizeEmail(req.Email)
+	existingEmployee, err := s.employeeRepo.FindByEmailWithDeleted(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if existingEmployee != nil {
+		return nil, ErrEmailAlreadyExists
+	}
+
+	expiration := s.cfg.Auth.InvitationExpiration
+	if expiration <= 0 {
+		expiration = 72
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		Role:      role,
+		InvitedBy: inviterID,
+		ExpiresAt: time.Now().Add(time.Hour * time.Duration(expiration)),
+	}
+
+	if err := s.repo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	token, err := signInvitationToken(s.keys, invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toInvitationResponse(invitation)
+	response.Token = token
+	return response, nil
+}
+
+// RevokeInvitation withdraws an invitation that has not been accepted yet.
+func (s *invitationService) RevokeInvitation(id uint, userRole string) error {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionEmployeeCreate)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrInvitationForbidden
+	}
+
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvitationNotFound
+		}
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationClosed
+	}
+
+	return nil
+}
+
+func toInvitationResponse(invitation *models.Invitation) *dtos.InvitationResponse {
+	return &dtos.InvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		InvitedBy:  invitation.InvitedBy,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+// signInvitationToken signs a token identifying the invitation that expires
+// with it. Whether it was already used is tracked on the invitation itself.
+func signInvitationToken(keys JWTKeys, invitation *models.Invitation) (string, error) {
+	claims := jwt.MapClaims{
+		"purpose":       invitationTokenPurpose,
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"exp":           invitation.ExpiresAt.Unix(),
+		"iat":           time.Now().Unix(),
+	}
+
+	return keys.Sign(claims)
+}
+
+// parseInvitationToken verifies an invitation token and returns the ID of its
+// invitation.
+func parseInvitationToken(keys JWTKeys, tokenString string) (uint, error) {
+	claims, err := keys.Parse(tokenString)
+	if err != nil || claims["purpose"] != invitationTokenPurpose {
+		return 0, ErrInvalidInvitation
+	}
+	invitationID, ok := claims["invitation_id"].(float64)
+	if !ok {
+		return 0, ErrInvalidInvitation
+	}
+
+	return uint(invitationID), nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateInvitation(t *testing.T) {
+	tests := []struct {
+		name      string
+		userRole  string
+		role      string
+		mockSetup func(*mocks.MockInvitationRepository, *mocks.MockEmployeeRepository)
+		wantError error
+	}{
+		{
+			name:     "hr invites a manager",
+			userRole: "hr",
+			role:     "Manager",
+			mockSetup: func(repo *mocks.MockInvitationRepository, employeeRepo *mocks.MockEmployeeRepository) {
+				employeeRepo.On("FindByEmailWithDeleted", "jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+				repo.On("Create", mock.MatchedBy(func(invitation *models.Invitation) bool {
+					return invitation.Email == "jane@example.com" && invitation.Role == "manager" && invitation.InvitedBy == 1
+				})).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.Invitation).ID = 3
+				})
+			},
+		},
+		{
+			name:      "employees cannot invite",
+			userRole:  "employee",
+			role:      "employee",
+			mockSetup: func(*mocks.MockInvitationRepository, *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvitationForbidden,
+		},
+		{
+			name:      "unknown role",
+			userRole:  "hr",
+			role:      "ceo",
+			mockSetup: func(*mocks.MockInvitationRepository, *mocks.MockEmployeeRepository) {},
+			wantError: ErrUnknownRole,
+		},
+		{
+			name:     "email already registered",
+			userRole: "hr",
+			role:     "employee",
+			mockSetup: func(repo *mocks.MockInvitationRepository, employeeRepo *mocks.MockEmployeeRepository) {
+				employeeRepo.On("FindByEmailWithDeleted", "jane@example.com").Return(&models.Employee{ID: 2, Email: "jane@example.com"}, nil)
+			},
+			wantError: ErrEmailAlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockInvitationRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo, mockEmpRepo)
+			cfg := setupTestConfig()
+
+			service := NewInvitationService(mockRepo, mockEmpRepo, newTestPermissionChecker(), newTestJWTKeys(), cfg)
+			result, err := service.CreateInvitation(1, tt.userRole, &dtos.CreateInvitationRequest{
+				Email: "Jane@Example.com",
+				Role:  tt.role,
+			})
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "manager", result.Role)
+				assert.WithinDuration(t, time.Now().Add(72*time.Hour), result.ExpiresAt, time.Minute)
+
+				// The token leads back to the invitation
+				invitationID, err := parseInvitationToken(newTestJWTKeys(), result.Token)
+				assert.NoError(t, err)
+				assert.Equal(t, uint(3), invitationID)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockEmpRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateInvitationRoleAssignment(t *testing.T) {
+	// Recruiters may invite employees but not grant them other roles
+	permissionRepo := new(mocks.MockRolePermissionRepository)
+	permissionRepo.On("FindByRole", "recruiter").Return([]models.RolePermission{
+		{Role: "recruiter", Permission: PermissionEmployeeCreate},
+	}, nil)
+	permissionRepo.On("FindByRole", "manager").Return([]models.RolePermission{
+		{Role: "manager", Permission: PermissionLeaveReadTeam},
+	}, nil)
+
+	mockRepo := new(mocks.MockInvitationRepository)
+	mockRepo.On("Create", mock.MatchedBy(func(invitation *models.Invitation) bool {
+		return invitation.Role == "employee"
+	})).Return(nil)
+	mockEmpRepo := new(mocks.MockEmployeeRepository)
+	mockEmpRepo.On("FindByEmailWithDeleted", "jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	service := NewInvitationService(mockRepo, mockEmpRepo, NewPermissionChecker(permissionRepo), newTestJWTKeys(), setupTestConfig())
+
+	_, err := service.CreateInvitation(1, "recruiter", &dtos.CreateInvitationRequest{Email: "jane@example.com", Role: "manager"})
+	assert.ErrorIs(t, err, ErrRoleAssignmentForbidden)
+
+	result, err := service.CreateInvitation(1, "recruiter", &dtos.CreateInvitationRequest{Email: "jane@example.com", Role: "employee"})
+	assert.NoError(t, err)
+	assert.Equal(t, "employee", result.Role)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevokeInvitation(t *testing.T) {
+	mockRepo := new(mocks.MockInvitationRepository)
+	mockRepo.On("FindByID", uint(3)).Return(&models.Invitation{ID: 3}, nil)
+	mockRepo.On("FindByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Revoke", uint(3), mock.AnythingOfType("time.Time")).Return(true, nil).Once()
+	mockRepo.On("Revoke", uint(3), mock.AnythingOfType("time.Time")).Return(false, nil).Once()
+
+	service := NewInvitationService(mockRepo, new(mocks.MockEmployeeRepository), newTestPermissionChecker(), newTestJWTKeys(), setupTestConfig())
+
+	assert.ErrorIs(t, service.RevokeInvitation(3, "manager"), ErrInvitationForbidden)
+	assert.NoError(t, service.RevokeInvitation(3, "hr"))
+	assert.ErrorIs(t, service.RevokeInvitation(3, "hr"), ErrInvitationClosed)
+	assert.ErrorIs(t, service.RevokeInvitation(4, "hr"), ErrInvitationNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKeys signs and verifies every JWT the application issues. With HS256 it
+// uses the shared secret; with RS256 or ES256 it signs with one private key
+// and verifies with any configured public key, picked by the kid header, so
+// keys can be rotated without logging everyone out.
+type JWTKeys interface {
+	Sign(claims jwt.MapClaims) (string, error)
+	Parse(tokenString string) (jwt.MapClaims, error)
+	JWKS() *dtos.JWKSResponse
+}
+
+type jwtKey struct {
+	id         string
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+type jwtKeys struct {
+	method  jwt.SigningMethod
+	secret  []byte
+	signing *jwtKey
+	keys    []*jwtKey
+	byID    map[string]*jwtKey
+}
+
+func NewJWTKeys(cfg *config.ApplicationConfig) (JWTKeys, error) {
+	algorithm := strings.ToUpper(strings.TrimSpace(cfg.JWT.Algorithm))
+	switch algorithm {
+	case "", "HS256":
+		if cfg.JWT.Secret == "" {
+			return nil, fmt.Errorf("jwt secret is required for HS256")
+		}
+		return &jwtKeys{method: jwt.SigningMethodHS256, secret: []byte(cfg.JWT.Secret)}, nil
+	case "RS256":
+		return loadJWTKeys(jwt.SigningMethodRS256, cfg.JWT)
+	case "ES256":
+		return loadJWTKeys(jwt.SigningMethodES256, cfg.JWT)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.JWT.Algorithm)
+	}
+}
+
+func loadJWTKeys(method jwt.SigningMethod, cfg config.JWTConfig) (*jwtKeys, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, fmt.Errorf("jwt keys are required for %s", method.Alg())
+	}
+
+	keys := &jwtKeys{method: method, byID: make(map[string]*jwtKey, len(cfg.Keys))}
+	for _, keyCfg := range cfg.Keys {
+		if keyCfg.ID == "" {
+			return nil, fmt.Errorf("jwt key without id")
+		}
+		if _, exists := keys.byID[keyCfg.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id %q", keyCfg.ID)
+		}
+
+		key, err := loadJWTKey(method, keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", keyCfg.ID, err)
+		}
+		keys.keys = append(keys.keys, key)
+		keys.byID[key.id] = key
+	}
+
+	for _, key := range keys.keys {
+		if key.privateKey == nil {
+			continue
+		}
+		if cfg.SigningKeyID == "" || cfg.SigningKeyID == key.id {
+			keys.signing = key
+			break
+		}
+	}
+	if keys.signing == nil {
+		if cfg.SigningKeyID != "" {
+			return nil, fmt.Errorf("jwt signing key %q not found or has no private key", cfg.SigningKeyID)
+		}
+		return nil, fmt.Errorf("no jwt key with a private key to sign with")
+	}
+
+	return keys, nil
+}
+
+func loadJWTKey(method jwt.SigningMethod, cfg config.JWTKeyConfig) (*jwtKey, error) {
+	key := &jwtKey{id: cfg.ID}
+
+	if cfg.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case jwt.SigningMethodRS256:
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.privateKey, key.publicKey = privateKey, &privateKey.PublicKey
+		case jwt.SigningMethodES256:
+			privateKey, err := jwt.ParseECPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			key.privateKey, key.publicKey = privateKey, &privateKey.PublicKey
+		}
+	} else if cfg.PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		switch method {
+		case jwt.SigningMethodRS256:
+			key.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		case jwt.SigningMethodES256:
+			key.publicKey, err = jwt.ParseECPublicKeyFromPEM(pem)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, fmt.Errorf("private_key_file or public_key_file is required")
+	}
+
+	if ecKey, ok := key.publicKey.(*ecdsa.PublicKey); ok && ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ES256 needs a P-256 key")
+	}
+	return key, nil
+}
+
+// Sign signs the claims with the current signing key, naming it in the kid
+// header. The typ header follows the purpose claim, see jwtType.
+func (k *jwtKeys) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	purpose, _ := claims["purpose"].(string)
+	token.Header["typ"] = jwtType(purpose)
+	if k.signing == nil {
+		return token.SignedString(k.secret)
+	}
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.privateKey)
+}
+
+// Parse verifies the signature and expiry of a token and returns its claims.
+// Only the configured algorithm is accepted, so that a token cannot pick a
+// weaker one, and the typ header must match the purpose claim.
+func (k *jwtKeys) Parse(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if k.signing == nil {
+			return k.secret, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.byID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key.publicKey, nil
+	}, jwt.WithValidMethods([]string{k.method.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	purpose, _ := claims["purpose"].(string)
+	if token.Header["typ"] != jwtType(purpose) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// jwtType returns the typ header of tokens with the given purpose. Access
+// tokens are at+jwt (RFC 9068); the internal tokens signed with the same
+// keys, such as pending MFA logins or invitations, get a type of their own,
+// so a consumer checking typ cannot mistake them for access tokens.
+func jwtType(purpose string) string {
+	switch purpose {
+	case "":
+		return "JWT"
+	case accessTokenPurpose:
+		return "at+jwt"
+	default:
+		return purpose + "+jwt"
+	}
+}
+
+// JWKS returns the public verification keys. It is empty with HS256, whose
+// secret cannot be published.
+func (k *jwtKeys) JWKS() *dtos.JWKSResponse {
+	response := &dtos.JWKSResponse{Keys: []dtos.JWK{}}
+	for _, key := range k.keys {
+		jwk := dtos.JWK{Kid: key.id, Use: "sig", Alg: k.method.Alg()}
+		switch publicKey := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			ecdhKey, err := publicKey.ECDH()
+			if err != nil {
+				continue
+			}
+			// Uncompressed point: 0x04 || X || Y
+			point := ecdhKey.Bytes()
+			size := (len(point) - 1) / 2
+			jwk.Kty = "EC"
+			jwk.Crv = "P-256"
+			jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+			jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+		}
+		response.Keys = append(response.Keys, jwk)
+	}
+	return response
+}
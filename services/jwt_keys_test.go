@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"hr-leave-request/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJWTKeys returns HS256 keys using the secret of setupTestConfig.
+func newTestJWTKeys() JWTKeys {
+	keys, err := NewJWTKeys(setupTestConfig())
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
+// writeRSAKey writes a fresh RSA key pair into dir and returns the paths of
+// the private and public PEM files.
+func writeRSAKey(t *testing.T, dir, name string) (string, string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	return writePEMPair(t, dir, name, privateDER, publicDER)
+}
+
+func writeECKey(t *testing.T, dir, name string) (string, string) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	return writePEMPair(t, dir, name, privateDER, publicDER)
+}
+
+func writePEMPair(t *testing.T, dir, name string, privateDER, publicDER []byte) (string, string) {
+	privatePath := filepath.Join(dir, name+".key")
+	publicPath := filepath.Join(dir, name+".pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644))
+	return privatePath, publicPath
+}
+
+func testClaims() jwt.MapClaims {
+	return jwt.MapClaims{"user_id": float64(1), "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func TestJWTKeysSignAndParse(t *testing.T) {
+	dir := t.TempDir()
+	rsaPrivate, _ := writeRSAKey(t, dir, "rsa")
+	ecPrivate, _ := writeECKey(t, dir, "ec")
+
+	tests := []struct {
+		name string
+		jwt  config.JWTConfig
+		alg  string
+		kid  string
+	}{
+		{
+			name: "HS256",
+			jwt:  config.JWTConfig{Algorithm: "HS256", Secret: "test-secret-key"},
+			alg:  "HS256",
+		},
+		{
+			name: "RS256",
+			jwt:  config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "rsa-1", PrivateKeyFile: rsaPrivate}}},
+			alg:  "RS256",
+			kid:  "rsa-1",
+		},
+		{
+			name: "ES256",
+			jwt:  config.JWTConfig{Algorithm: "es256", Keys: []config.JWTKeyConfig{{ID: "ec-1", PrivateKeyFile: ecPrivate}}},
+			alg:  "ES256",
+			kid:  "ec-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewJWTKeys(&config.ApplicationConfig{JWT: tt.jwt})
+			require.NoError(t, err)
+
+			tokenString, err := keys.Sign(testClaims())
+			require.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, token.Method.Alg())
+			if tt.kid == "" {
+				assert.NotContains(t, token.Header, "kid")
+			} else {
+				assert.Equal(t, tt.kid, token.Header["kid"])
+			}
+
+			claims, err := keys.Parse(tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, float64(1), claims["user_id"])
+		})
+	}
+}
+
+func TestJWTKeysRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeRSAKey(t, dir, "old")
+	newPrivate, _ := writeRSAKey(t, dir, "new")
+
+	oldKeys, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm: "RS256",
+		Keys:      []config.JWTKeyConfig{{ID: "old", PrivateKeyFile: oldPrivate}},
+	}})
+	require.NoError(t, err)
+	oldToken, err := oldKeys.Sign(testClaims())
+	require.NoError(t, err)
+
+	// The old key is retired to verification only and the new one signs
+	rotated, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm:    "RS256",
+		SigningKeyID: "new",
+		Keys: []config.JWTKeyConfig{
+			{ID: "old", PublicKeyFile: oldPublic},
+			{ID: "new", PrivateKeyFile: newPrivate},
+		},
+	}})
+	require.NoError(t, err)
+
+	_, err = rotated.Parse(oldToken)
+	assert.NoError(t, err)
+
+	newToken, err := rotated.Sign(testClaims())
+	require.NoError(t, err)
+	token, _, err := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", token.Header["kid"])
+
+	// Once the old key is removed its tokens stop verifying
+	newOnly, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm: "RS256",
+		Keys:      []config.JWTKeyConfig{{ID: "new", PrivateKeyFile: newPrivate}},
+	}})
+	require.NoError(t, err)
+	_, err = newOnly.Parse(oldToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = newOnly.Parse(newToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTKeysRejectsOtherAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+	rsaPrivate, _ := writeRSAKey(t, dir, "rsa")
+
+	rsaKeys, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm: "RS256",
+		Keys:      []config.JWTKeyConfig{{ID: "rsa-1", PrivateKeyFile: rsaPrivate}},
+	}})
+	require.NoError(t, err)
+	hmacKeys := newTestJWTKeys()
+
+	rsaToken, err := rsaKeys.Sign(testClaims())
+	require.NoError(t, err)
+	hmacToken, err := hmacKeys.Sign(testClaims())
+	require.NoError(t, err)
+
+	_, err = hmacKeys.Parse(rsaToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = rsaKeys.Parse(hmacToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	expired, err := hmacKeys.Sign(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})
+	require.NoError(t, err)
+	_, err = hmacKeys.Parse(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTKeysTokenType(t *testing.T) {
+	keys := newTestJWTKeys()
+
+	// The typ header tells access tokens from the internal ones
+	for purpose, typ := range map[string]string{"access": "at+jwt", "mfa_pending": "mfa_pending+jwt", "": "JWT"} {
+		claims := testClaims()
+		if purpose != "" {
+			claims["purpose"] = purpose
+		}
+		tokenString, err := keys.Sign(claims)
+		require.NoError(t, err)
+		token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, typ, token.Header["typ"], purpose)
+	}
+
+	// A token whose typ does not match its purpose is refused
+	claims := testClaims()
+	claims["purpose"] = "access"
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+	require.NoError(t, err)
+	_, err = keys.Parse(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWTKeysJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaPrivate, _ := writeRSAKey(t, dir, "rsa")
+	ecPrivate, _ := writeECKey(t, dir, "ec")
+
+	assert.Empty(t, newTestJWTKeys().JWKS().Keys)
+
+	rsaKeys, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm: "RS256",
+		Keys:      []config.JWTKeyConfig{{ID: "rsa-1", PrivateKeyFile: rsaPrivate}},
+	}})
+	require.NoError(t, err)
+	jwks := rsaKeys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "rsa-1", jwks.Keys[0].Kid)
+	assert.Equal(t, "RS256", jwks.Keys[0].Alg)
+	assert.Equal(t, "sig", jwks.Keys[0].Use)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+
+	ecKeys, err := NewJWTKeys(&config.ApplicationConfig{JWT: config.JWTConfig{
+		Algorithm: "ES256",
+		Keys:      []config.JWTKeyConfig{{ID: "ec-1", PrivateKeyFile: ecPrivate}},
+	}})
+	require.NoError(t, err)
+	jwks = ecKeys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "EC", jwks.Keys[0].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[0].Crv)
+	assert.Len(t, jwks.Keys[0].X, 43)
+	assert.Len(t, jwks.Keys[0].Y, 43)
+}
+
+func TestNewJWTKeysConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	rsaPrivate, rsaPublic := writeRSAKey(t, dir, "rsa")
+	ecPrivate, _ := writeECKey(t, dir, "ec")
+
+	tests := []struct {
+		name string
+		jwt  config.JWTConfig
+	}{
+		{name: "HS256 without secret", jwt: config.JWTConfig{Algorithm: "HS256"}},
+		{name: "unsupported algorithm", jwt: config.JWTConfig{Algorithm: "none", Secret: "x"}},
+		{name: "RS256 without keys", jwt: config.JWTConfig{Algorithm: "RS256"}},
+		{name: "key without id", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{PrivateKeyFile: rsaPrivate}}}},
+		{name: "key without files", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "a"}}}},
+		{name: "missing file", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: filepath.Join(dir, "missing.key")}}}},
+		{name: "wrong key type", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: ecPrivate}}}},
+		{name: "duplicate id", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: rsaPrivate}, {ID: "a", PublicKeyFile: rsaPublic}}}},
+		{name: "only public keys", jwt: config.JWTConfig{Algorithm: "RS256", Keys: []config.JWTKeyConfig{{ID: "a", PublicKeyFile: rsaPublic}}}},
+		{name: "unknown signing key", jwt: config.JWTConfig{Algorithm: "RS256", SigningKeyID: "b", Keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: rsaPrivate}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJWTKeys(&config.ApplicationConfig{JWT: tt.jwt})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// leaveTypes lists the leave types in the order balances are reported.
+var leaveTypes = []string{"vacation", "sick", "personal", "other"}
+
+type LeaveBalanceService interface {
+	WithTx(tx *gorm.DB) LeaveBalanceService
+	GetBalances(employeeID uint, year int) ([]dtos.LeaveBalanceResponse, error)
+	GetEmployeeBalances(employeeID uint, viewerID uint, userRole string, year int) ([]dtos.LeaveBalanceResponse, error)
+	GetEmployeeBalancesForAPIKey(employeeID uint, apiKey *APIKeyIdentity, year int) ([]dtos.LeaveBalanceResponse, error)
+	SetEntitlement(employeeID uint, userRole string, req *dtos.SetLeaveEntitlementRequest) (*dtos.LeaveBalanceResponse, error)
+	EnsureSufficientBalance(employeeID uint, leaveType string, year int, days float64) error
+	Debit(leaveRequest *models.LeaveRequest, days float64) error
+	Credit(leaveRequest *models.LeaveRequest) error
+}
+
+type leaveBalanceService struct {
+	repo         repositories.LeaveBalanceRepository
+	employeeRepo repositories.EmployeeRepository
+	permissions  PermissionChecker
+	cfg          *config.ApplicationConfig
+}
+
+func NewLeaveBalanceService(repo repositories.LeaveBalanceRepository, employeeRepo repositories.EmployeeRepository, permissions PermissionChecker, cfg *config.ApplicationConfig) LeaveBalanceService {
+	return &leaveBalanceService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+		permissions:  permissions,
+		cfg:          cfg,
+	}
+}
+
+func (s *leaveBalanceService) WithTx(tx *gorm.DB) LeaveBalanceService {
+	return &leaveBalanceService{
+		repo:         s.repo.WithTx(tx),
+		employeeRepo: s.employeeRepo,
+		permissions:  s.permissions,
+		cfg:          s.cfg,
+	}
+}
+
+// GetEmployeeBalances returns an employee's balances to a viewer who may read
+// their leave: the employee themselves, their manager with leave:read:team, or
+// anyone with leave:read:all. Others are told the employee does not exist.
+func (s *leaveBalanceService) GetEmployeeBalances(employeeID uint, viewerID uint, userRole string, year int) ([]dtos.LeaveBalanceResponse, error) {
+	if employeeID != viewerID {
+		permissions, err := s.permissions.PermissionsFor(userRole)
+		if err != nil {
+			return nil, err
+		}
+
+		if !permissions.Has(PermissionLeaveReadAll) {
+			employee, err := s.employeeRepo.FindByID(employeeID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, ErrEmployeeNotFound
+				}
+				return nil, err
+			}
+			if !permissions.Has(PermissionLeaveReadTeam) || employee.ManagerID == nil || *employee.ManagerID != viewerID {
+				return nil, ErrEmployeeNotFound
+			}
+		}
+	}
+
+	return s.GetBalances(employeeID, year)
+}
+
+// GetEmployeeBalancesForAPIKey returns an employee's balances to a service
+// API key, which needs leave:read:all as it has no employee of its own.
+func (s *leaveBalanceService) GetEmployeeBalancesForAPIKey(employeeID uint, apiKey *APIKeyIdentity, year int) ([]dtos.LeaveBalanceResponse, error) {
+	if !apiKey.Permissions.Has(PermissionLeaveReadAll) {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionLeaveReadAll)
+	}
+
+	return s.GetBalances(employeeID, year)
+}
+
+// GetBalances returns an employee's balances without checking who asks; it
+// backs the signed-in employee's own balances.
+func (s *leaveBalanceService) GetBalances(employeeID uint, year int) ([]dtos.LeaveBalanceResponse, error) {
+	// Validate employee exists
+	_, err := s.employeeRepo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	balances, err := s.repo.FindByEmployeeAndYear(employeeID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	balancesByType := make(map[string]models.LeaveBalance, len(balances))
+	for _, balance := range balances {
+		balancesByType[balance.Type] = balance
+	}
+
+	// Report every tracked leave type, falling back to the default entitlement
+	// for types that have not been touched in this year yet
+	responses := make([]dtos.LeaveBalanceResponse, 0, len(leaveTypes))
+	for _, leaveType := range leaveTypes {
+		balance, ok := balancesByType[leaveType]
+		if !ok {
+			entitledDays, tracked := s.defaultEntitlement(leaveType)
+			if !tracked {
+				continue
+			}
+			balance = models.LeaveBalance{
+				EmployeeID:   employeeID,
+				Type:         leaveType,
+				Year:         year,
+				EntitledDays: entitledDays,
+			}
+		}
+		responses = append(responses, *s.toLeaveBalanceResponse(&balance))
+	}
+
+	return responses, nil
+}
+
+// SetEntitlement sets the days an employee is entitled to for a leave type
+// and year, in place of the configured default. It needs leave:balance:manage
+// and cannot go below the days already used.
+func (s *leaveBalanceService) SetEntitlement(employeeID uint, userRole string, req *dtos.SetLeaveEntitlementRequest) (*dtos.LeaveBalanceResponse, error) {
+	allowed, err := s.permissions.HasPermission(userRole, PermissionLeaveBalanceManage)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrBalanceManagementForbidden
+	}
+
+	if _, err := s.employeeRepo.FindByID(employeeID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	entitledDays := *req.EntitledDays
+	balance, err := s.repo.FindOrCreate(employeeID, req.Type, req.Year, entitledDays)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.EntitledDays != entitledDays {
+		updated, err := s.repo.UpdateEntitledDays(balance, entitledDays)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, ErrEntitlementBelowUsed
+		}
+	}
+
+	return s.toLeaveBalanceResponse(balance), nil
+}
+
+func (s *leaveBalanceService) EnsureSufficientBalance(employeeID uint, leaveType string, year int, days float64) error {
+	balance, err := s.repo.FindByEmployeeTypeAndYear(employeeID, leaveType, year)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	remaining := 0.0
+	if balance != nil {
+		remaining = balance.RemainingDays()
+	} else {
+		entitledDays, tracked := s.defaultEntitlement(leaveType)
+		if !tracked {
+			return nil
+		}
+		remaining = entitledDays
+	}
+
+	if days > remaining {
+		return ErrInsufficientBalance
+	}
+
+	return nil
+}
+
+// Debit charges an approved leave request against the balance of its leave
+// year. Leave types without an entitlement are not tracked and are ignored.
+func (s *leaveBalanceService) Debit(leaveRequest *models.LeaveRequest, days float64) error {
+	year := leaveRequest.StartDate.Year()
+
+	var balance *models.LeaveBalance
+	var err error
+	if entitledDays, tracked := s.defaultEntitlement(leaveRequest.Type); tracked {
+		balance, err = s.repo.FindOrCreate(leaveRequest.EmployeeID, leaveRequest.Type, year, entitledDays)
+	} else {
+		balance, err = s.repo.FindByEmployeeTypeAndYear(leaveRequest.EmployeeID, leaveRequest.Type, year)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if days > balance.RemainingDays() {
+		return ErrInsufficientBalance
+	}
+
+	return s.repo.RecordTransaction(balance, &models.LeaveBalanceTransaction{
+		LeaveRequestID: &leaveRequest.ID,
+		Kind:           "debit",
+		Days:           days,
+	})
+}
+
+// Credit gives back the days debited for a leave request, as recorded in the
+// ledger. Nothing is credited when nothing was debited, such as for requests
+// approved before balances were tracked.
+func (s *leaveBalanceService) Credit(leaveRequest *models.LeaveRequest) error {
+	balance, err := s.repo.FindByEmployeeTypeAndYear(leaveRequest.EmployeeID, leaveRequest.Type, leaveRequest.StartDate.Year())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Nothing was debited for an untracked leave type
+			return nil
+		}
+		return err
+	}
+
+	days, err := s.repo.OutstandingDebit(balance.ID, leaveRequest.ID)
+	if err != nil {
+		return err
+	}
+	if days <= 0 {
+		return nil
+	}
+
+	return s.repo.RecordTransaction(balance, &models.LeaveBalanceTransaction{
+		LeaveRequestID: &leaveRequest.ID,
+		Kind:           "credit",
+		Days:           days,
+	})
+}
+
+func (s *leaveBalanceService) defaultEntitlement(leaveType string) (float64, bool) {
+	days, ok := s.cfg.Leave.Entitlements[leaveType]
+	return days, ok
+}
+
+func (s *leaveBalanceService) toLeaveBalanceResponse(balance *models.LeaveBalance) *dtos.LeaveBalanceResponse {
+	return &dtos.LeaveBalanceResponse{
+		Type:          balance.Type,
+		Year:          balance.Year,
+		EntitledDays:  balance.EntitledDays,
+		UsedDays:      balance.UsedDays,
+		RemainingDays: balance.RemainingDays(),
+	}
+}
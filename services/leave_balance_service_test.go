@@ -0,0 +1,349 @@
+package services
+
+import (
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func setupBalanceTestConfig() *config.ApplicationConfig {
+	cfg := setupTestConfig()
+	cfg.Leave = config.LeaveConfig{
+		Entitlements: map[string]float64{
+			"vacation": 12,
+			"sick":     10,
+		},
+	}
+	return cfg
+}
+
+func TestGetBalances(t *testing.T) {
+	year := 2026
+
+	tests := []struct {
+		name       string
+		employeeID uint
+		mockSetup  func(*mocks.MockLeaveBalanceRepository, *mocks.MockEmployeeRepository)
+		wantError  bool
+		errorMsg   string
+		checkFunc  func([]dtos.LeaveBalanceResponse)
+	}{
+		{
+			name:       "stored balances merged with default entitlements",
+			employeeID: 1,
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindByEmployeeAndYear", uint(1), year).Return([]models.LeaveBalance{
+					{ID: 1, EmployeeID: 1, Type: "vacation", Year: year, EntitledDays: 15, UsedDays: 4},
+				}, nil)
+			},
+			wantError: false,
+			checkFunc: func(balances []dtos.LeaveBalanceResponse) {
+				assert.Len(t, balances, 2)
+				assert.Equal(t, "vacation", balances[0].Type)
+				assert.Equal(t, float64(15), balances[0].EntitledDays)
+				assert.Equal(t, float64(11), balances[0].RemainingDays)
+				assert.Equal(t, "sick", balances[1].Type)
+				assert.Equal(t, float64(10), balances[1].RemainingDays)
+			},
+		},
+		{
+			name:       "employee not found",
+			employeeID: 999,
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				empRepo.On("FindByID", uint(999)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: true,
+			errorMsg:  "employee not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveBalanceRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo, mockEmpRepo)
+
+			service := NewLeaveBalanceService(mockRepo, mockEmpRepo, newTestPermissionChecker(), setupBalanceTestConfig())
+			result, err := service.GetBalances(tt.employeeID, year)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				if tt.errorMsg != "" {
+					assert.Equal(t, tt.errorMsg, err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				if tt.checkFunc != nil {
+					tt.checkFunc(result)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockEmpRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetEmployeeBalances(t *testing.T) {
+	year := 2026
+	managerID := uint(5)
+
+	tests := []struct {
+		name       string
+		employeeID uint
+		viewerID   uint
+		userRole   string
+		wantError  error
+	}{
+		{name: "own balances", employeeID: 1, viewerID: 1, userRole: "employee"},
+		{name: "manager reads a direct report", employeeID: 1, viewerID: 5, userRole: "manager"},
+		{name: "hr reads anyone", employeeID: 1, viewerID: 9, userRole: "hr"},
+		{name: "manager cannot read other teams", employeeID: 1, viewerID: 6, userRole: "manager", wantError: ErrEmployeeNotFound},
+		{name: "employee cannot read a colleague", employeeID: 1, viewerID: 2, userRole: "employee", wantError: ErrEmployeeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveBalanceRepository)
+			mockRepo.On("FindByEmployeeAndYear", uint(1), year).Return([]models.LeaveBalance{}, nil).Maybe()
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockEmpRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, ManagerID: &managerID}, nil)
+
+			service := NewLeaveBalanceService(mockRepo, mockEmpRepo, newTestPermissionChecker(), setupBalanceTestConfig())
+			result, err := service.GetEmployeeBalances(tt.employeeID, tt.viewerID, tt.userRole, year)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, 2)
+			}
+		})
+	}
+}
+
+func TestGetEmployeeBalancesForAPIKey(t *testing.T) {
+	mockRepo := new(mocks.MockLeaveBalanceRepository)
+	mockRepo.On("FindByEmployeeAndYear", uint(1), 2026).Return([]models.LeaveBalance{}, nil)
+	mockEmpRepo := new(mocks.MockEmployeeRepository)
+	mockEmpRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+	service := NewLeaveBalanceService(mockRepo, mockEmpRepo, newTestPermissionChecker(), setupBalanceTestConfig())
+
+	reader := &APIKeyIdentity{ID: 1, Permissions: Permissions{PermissionLeaveReadAll: true}}
+	result, err := service.GetEmployeeBalancesForAPIKey(1, reader, 2026)
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	other := &APIKeyIdentity{ID: 2, Permissions: Permissions{PermissionEmployeeRead: true}}
+	_, err = service.GetEmployeeBalancesForAPIKey(1, other, 2026)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+}
+
+func TestSetEntitlement(t *testing.T) {
+	days := func(d float64) *float64 { return &d }
+
+	tests := []struct {
+		name      string
+		role      string
+		req       dtos.SetLeaveEntitlementRequest
+		mockSetup func(*mocks.MockLeaveBalanceRepository, *mocks.MockEmployeeRepository)
+		wantError error
+		wantDays  float64
+	}{
+		{
+			name: "hr creates a balance with the entitlement",
+			role: "hr",
+			req:  dtos.SetLeaveEntitlementRequest{Type: "personal", Year: 2026, EntitledDays: days(3)},
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindOrCreate", uint(1), "personal", 2026, float64(3)).
+					Return(&models.LeaveBalance{ID: 4, EmployeeID: 1, Type: "personal", Year: 2026, EntitledDays: 3}, nil)
+			},
+			wantDays: 3,
+		},
+		{
+			name: "hr raises an existing entitlement",
+			role: "hr",
+			req:  dtos.SetLeaveEntitlementRequest{Type: "vacation", Year: 2026, EntitledDays: days(20)},
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				balance := &models.LeaveBalance{ID: 3, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 5}
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindOrCreate", uint(1), "vacation", 2026, float64(20)).Return(balance, nil)
+				repo.On("UpdateEntitledDays", balance, float64(20)).Return(true, nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.LeaveBalance).EntitledDays = 20
+				})
+			},
+			wantDays: 20,
+		},
+		{
+			name: "entitlement below the days already used",
+			role: "hr",
+			req:  dtos.SetLeaveEntitlementRequest{Type: "vacation", Year: 2026, EntitledDays: days(4)},
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				balance := &models.LeaveBalance{ID: 3, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 5}
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("FindOrCreate", uint(1), "vacation", 2026, float64(4)).Return(balance, nil)
+				repo.On("UpdateEntitledDays", balance, float64(4)).Return(false, nil)
+			},
+			wantError: ErrEntitlementBelowUsed,
+		},
+		{
+			name:      "manager cannot set entitlements",
+			role:      "manager",
+			req:       dtos.SetLeaveEntitlementRequest{Type: "vacation", Year: 2026, EntitledDays: days(20)},
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {},
+			wantError: ErrBalanceManagementForbidden,
+		},
+		{
+			name: "employee not found",
+			role: "hr",
+			req:  dtos.SetLeaveEntitlementRequest{Type: "vacation", Year: 2026, EntitledDays: days(20)},
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository, empRepo *mocks.MockEmployeeRepository) {
+				empRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrEmployeeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveBalanceRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo, mockEmpRepo)
+
+			service := NewLeaveBalanceService(mockRepo, mockEmpRepo, newTestPermissionChecker(), setupBalanceTestConfig())
+			result, err := service.SetEntitlement(1, tt.role, &tt.req)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantDays, result.EntitledDays)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockEmpRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEnsureSufficientBalance(t *testing.T) {
+	tests := []struct {
+		name      string
+		leaveType string
+		days      float64
+		mockSetup func(*mocks.MockLeaveBalanceRepository)
+		wantError bool
+	}{
+		{
+			name:      "within default entitlement",
+			leaveType: "vacation",
+			days:      5,
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository) {
+				repo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", 2026).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: false,
+		},
+		{
+			name:      "exceeds remaining balance",
+			leaveType: "vacation",
+			days:      3,
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository) {
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 10}
+				repo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", 2026).Return(balance, nil)
+			},
+			wantError: true,
+		},
+		{
+			name:      "untracked leave type",
+			leaveType: "other",
+			days:      30,
+			mockSetup: func(repo *mocks.MockLeaveBalanceRepository) {
+				repo.On("FindByEmployeeTypeAndYear", uint(1), "other", 2026).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveBalanceRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+
+			service := NewLeaveBalanceService(mockRepo, mockEmpRepo, newTestPermissionChecker(), setupBalanceTestConfig())
+			err := service.EnsureSufficientBalance(1, tt.leaveType, 2026, tt.days)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Equal(t, "insufficient leave balance for this leave type", err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDebitAndCredit(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	leaveRequest := &models.LeaveRequest{
+		ID:         7,
+		EmployeeID: 1,
+		StartDate:  start,
+		EndDate:    start.Add(48 * time.Hour),
+		Type:       "vacation",
+		Status:     "approved",
+	}
+
+	t.Run("debit creates the balance from the default entitlement", func(t *testing.T) {
+		mockRepo := new(mocks.MockLeaveBalanceRepository)
+		balance := &models.LeaveBalance{ID: 3, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12}
+		mockRepo.On("FindOrCreate", uint(1), "vacation", 2026, float64(12)).Return(balance, nil)
+		mockRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+			return tx.Kind == "debit" && tx.Days == 3 && *tx.LeaveRequestID == 7
+		})).Return(nil)
+
+		service := NewLeaveBalanceService(mockRepo, new(mocks.MockEmployeeRepository), newTestPermissionChecker(), setupBalanceTestConfig())
+		assert.NoError(t, service.Debit(leaveRequest, 3))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("credit returns days to the balance", func(t *testing.T) {
+		mockRepo := new(mocks.MockLeaveBalanceRepository)
+		balance := &models.LeaveBalance{ID: 3, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12, UsedDays: 3}
+		mockRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", 2026).Return(balance, nil)
+		mockRepo.On("OutstandingDebit", uint(3), uint(7)).Return(float64(3), nil)
+		mockRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+			return tx.Kind == "credit" && tx.Days == 3 && *tx.LeaveRequestID == 7
+		})).Return(nil)
+
+		service := NewLeaveBalanceService(mockRepo, new(mocks.MockEmployeeRepository), newTestPermissionChecker(), setupBalanceTestConfig())
+		assert.NoError(t, service.Credit(leaveRequest))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing is credited without a debit", func(t *testing.T) {
+		mockRepo := new(mocks.MockLeaveBalanceRepository)
+		balance := &models.LeaveBalance{ID: 3, EmployeeID: 1, Type: "vacation", Year: 2026, EntitledDays: 12}
+		mockRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", 2026).Return(balance, nil)
+		mockRepo.On("OutstandingDebit", uint(3), uint(7)).Return(float64(0), nil)
+
+		service := NewLeaveBalanceService(mockRepo, new(mocks.MockEmployeeRepository), newTestPermissionChecker(), setupBalanceTestConfig())
+		assert.NoError(t, service.Credit(leaveRequest))
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "RecordTransaction", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// normalizeLeavePeriod snaps the requested range onto the window its
+// granularity actually covers, so that overlap checks can compare periods
+// directly: full days span whole calendar dates, half days cover the morning
+// (00:00-12:00) or the afternoon (12:00-23:59:59) of a single date, and hourly
+// leave keeps the requested times within a single date.
+func normalizeLeavePeriod(granularity string, startDate, endDate time.Time) (time.Time, time.Time, error) {
+	if startDate.After(endDate) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+
+	switch granularity {
+	case "full_day":
+		return startOfDay(startDate), endOfDay(endDate), nil
+	case "half_day_am", "half_day_pm":
+		if !sameDate(startDate, endDate) {
+			return time.Time{}, time.Time{}, ErrHalfDayMultipleDays
+		}
+		noon := startOfDay(startDate).Add(12 * time.Hour)
+		if granularity == "half_day_am" {
+			return startOfDay(startDate), noon, nil
+		}
+		return noon, endOfDay(startDate), nil
+	case "hours":
+		if !sameDate(startDate, endDate) {
+			return time.Time{}, time.Time{}, ErrHourlyMultipleDays
+		}
+		if !endDate.After(startDate) {
+			return time.Time{}, time.Time{}, ErrHourlyEndsBeforeStart
+		}
+		return startDate, endDate, nil
+	default:
+		return time.Time{}, time.Time{}, ErrInvalidGranularity
+	}
+}
+
+// chargeableDays computes the working days a normalized leave period consumes.
+// Half days count as 0.5 and hourly leave as a fraction of a working day;
+// neither is charged when it falls on a weekend or holiday.
+func chargeableDays(calendar WorkingCalendar, granularity string, startDate, endDate time.Time) (float64, error) {
+	if granularity == "full_day" {
+		return calendar.WorkingDays(startDate, endDate)
+	}
+
+	isWorkingDay, err := calendar.IsWorkingDay(startDate)
+	if err != nil || !isWorkingDay {
+		return 0, err
+	}
+
+	if granularity == "hours" {
+		hours := endDate.Sub(startDate).Hours()
+		if hours > calendar.HoursPerDay() {
+			return 0, ErrHourlyExceedsWorkingDay
+		}
+		return math.Round(hours/calendar.HoursPerDay()*100) / 100, nil
+	}
+
+	return 0.5, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// endOfDay returns the last second of t's date, matching the precision of the
+// datetime columns.
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
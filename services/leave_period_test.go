@@ -0,0 +1,103 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLeavePeriod(t *testing.T) {
+	day := time.Date(2026, 12, 21, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		granularity string
+		startDate   time.Time
+		endDate     time.Time
+		wantStart   time.Time
+		wantEnd     time.Time
+		errorMsg    string
+	}{
+		{
+			name:        "full days cover whole dates",
+			granularity: "full_day",
+			startDate:   day,
+			endDate:     day.AddDate(0, 0, 1),
+			wantStart:   time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC),
+			wantEnd:     time.Date(2026, 12, 22, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "morning half day",
+			granularity: "half_day_am",
+			startDate:   day,
+			endDate:     day,
+			wantStart:   time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC),
+			wantEnd:     time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "afternoon half day",
+			granularity: "half_day_pm",
+			startDate:   day,
+			endDate:     day,
+			wantStart:   time.Date(2026, 12, 21, 12, 0, 0, 0, time.UTC),
+			wantEnd:     time.Date(2026, 12, 21, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "hours keep the requested times",
+			granularity: "hours",
+			startDate:   day,
+			endDate:     day.Add(90 * time.Minute),
+			wantStart:   day,
+			wantEnd:     day.Add(90 * time.Minute),
+		},
+		{
+			name:        "hours need a positive duration",
+			granularity: "hours",
+			startDate:   day,
+			endDate:     day,
+			errorMsg:    "hourly leave must end after it starts",
+		},
+		{
+			name:        "hours across midnight",
+			granularity: "hours",
+			startDate:   day.Add(14 * time.Hour),
+			endDate:     day.Add(16 * time.Hour),
+			errorMsg:    "hourly leave must start and end on the same day",
+		},
+		{
+			name:        "start after end",
+			granularity: "full_day",
+			startDate:   day.AddDate(0, 0, 1),
+			endDate:     day,
+			errorMsg:    "start date cannot be after end date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := normalizeLeavePeriod(tt.granularity, tt.startDate, tt.endDate)
+
+			if tt.errorMsg != "" {
+				assert.EqualError(t, err, tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStart, start)
+				assert.Equal(t, tt.wantEnd, end)
+			}
+		})
+	}
+}
+
+func TestHalfDaysOnTheSameDateDoNotOverlap(t *testing.T) {
+	day := time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC)
+	amStart, amEnd, _ := normalizeLeavePeriod("half_day_am", day, day)
+	pmStart, pmEnd, _ := normalizeLeavePeriod("half_day_pm", day, day)
+
+	// Same predicate as HasOverlappingApprovedLeave: start < other end AND end > other start
+	assert.False(t, amStart.Before(pmEnd) && amEnd.After(pmStart))
+
+	fullStart, fullEnd, _ := normalizeLeavePeriod("full_day", day, day)
+	assert.True(t, fullStart.Before(amEnd) && fullEnd.After(amStart))
+	assert.True(t, fullStart.Before(pmEnd) && fullEnd.After(pmStart))
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"reflect"
+	"time"
+)
+
+// leaveRequestFieldChange is the before and after value of one audited field.
+type leaveRequestFieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// leaveRequestAuditFields snapshots the fields of a leave request that are
+// tracked in its audit trail. A nil request yields no fields, so creations
+// and deletions show every field going from or to null.
+func leaveRequestAuditFields(lr *models.LeaveRequest) map[string]any {
+	if lr == nil {
+		return map[string]any{}
+	}
+
+	fields := map[string]any{
+		"start_date":   lr.StartDate.Format(time.RFC3339),
+		"end_date":     lr.EndDate.Format(time.RFC3339),
+		"granularity":  lr.Granularity,
+		"working_days": lr.WorkingDays,
+		"type":         lr.Type,
+		"status":       lr.Status,
+	}
+	if lr.Reason != nil {
+		fields["reason"] = *lr.Reason
+	}
+	if lr.DecisionComment != nil {
+		fields["decision_comment"] = *lr.DecisionComment
+	}
+	if lr.DecidedBy != nil {
+		fields["decided_by"] = *lr.DecidedBy
+	}
+	if lr.DecidedAt != nil {
+		fields["decided_at"] = lr.DecidedAt.Format(time.RFC3339)
+	}
+	return fields
+}
+
+// diffLeaveRequests returns the JSON diff between two states of a leave
+// request, keyed by field name.
+func diffLeaveRequests(before, after *models.LeaveRequest) (string, error) {
+	beforeFields := leaveRequestAuditFields(before)
+	afterFields := leaveRequestAuditFields(after)
+
+	changes := make(map[string]leaveRequestFieldChange)
+	for field, from := range beforeFields {
+		if to := afterFields[field]; !reflect.DeepEqual(from, to) {
+			changes[field] = leaveRequestFieldChange{From: from, To: to}
+		}
+	}
+	for field, to := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = leaveRequestFieldChange{From: nil, To: to}
+		}
+	}
+
+	// Map keys are marshalled in sorted order, keeping the output stable
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// diffApprovalStep returns the JSON diff of a decision on an approval step,
+// keyed by "approval_step_<n>" so intermediate sign-offs read like any other
+// change in the audit trail.
+func diffApprovalStep(step *models.LeaveRequestApproval, fromStatus string) (string, error) {
+	changes := map[string]leaveRequestFieldChange{
+		fmt.Sprintf("approval_step_%d", step.Step): {From: fromStatus, To: step.Status},
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// statusAction names the audit action of a move into the given status.
+func statusAction(status string) string {
+	if status == "pending" {
+		return "submitted"
+	}
+	return status
+}
+
+// recordLeaveRequestEvent appends an entry to the audit trail of a leave
+// request with the diff between its previous and new state.
+func recordLeaveRequestEvent(eventRepo repositories.LeaveRequestEventRepository, leaveRequestID, actorID uint, action string, before, after *models.LeaveRequest) error {
+	changes, err := diffLeaveRequests(before, after)
+	if err != nil {
+		return err
+	}
+
+	return eventRepo.Create(&models.LeaveRequestEvent{
+		LeaveRequestID: leaveRequestID,
+		ActorID:        actorID,
+		Action:         action,
+		Changes:        changes,
+	})
+}
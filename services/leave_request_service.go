@@ -1,11 +1,13 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"hr-leave-request/dtos"
 	"hr-leave-request/models"
 	"hr-leave-request/repositories"
 	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,23 +15,46 @@ import (
 
 type LeaveRequestService interface {
 	CreateLeaveRequest(employeeID uint, req *dtos.CreateLeaveRequestRequest) (*dtos.LeaveRequestResponse, error)
-	GetLeaveRequestByID(id uint) (*dtos.LeaveRequestResponse, error)
-	GetLeaveRequests(req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error)
+	GetLeaveRequestByID(id uint, viewerID uint, userRole string) (*dtos.LeaveRequestResponse, error)
+	GetLeaveRequests(viewerID uint, userRole string, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error)
+	GetOwnLeaveRequests(employeeID uint, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error)
+	GetLeaveRequestByIDForAPIKey(id uint, apiKey *APIKeyIdentity) (*dtos.LeaveRequestResponse, error)
+	GetLeaveRequestsForAPIKey(apiKey *APIKeyIdentity, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error)
 	UpdateLeaveRequest(id uint, employeeID uint, userRole string, req *dtos.UpdateLeaveRequestRequest) (*dtos.LeaveRequestResponse, error)
 	DeleteLeaveRequest(id uint, employeeID uint, userRole string) error
-	ApproveLeaveRequest(id uint, userRole string) (*dtos.LeaveRequestResponse, error)
-	RejectLeaveRequest(id uint, userRole string) (*dtos.LeaveRequestResponse, error)
+	ApproveLeaveRequest(id uint, approverID uint, userRole string, req *dtos.DecideLeaveRequestRequest) (*dtos.LeaveRequestResponse, error)
+	RejectLeaveRequest(id uint, approverID uint, userRole string, req *dtos.DecideLeaveRequestRequest) (*dtos.LeaveRequestResponse, error)
+	SubmitLeaveRequest(id uint, employeeID uint) (*dtos.LeaveRequestResponse, error)
+	CancelLeaveRequest(id uint, employeeID uint, userRole string) (*dtos.LeaveRequestResponse, error)
+	WithdrawLeaveRequest(id uint, employeeID uint) (*dtos.LeaveRequestResponse, error)
+	GetLeaveRequestHistory(id uint, viewerID uint, userRole string) ([]dtos.LeaveRequestEventResponse, error)
+	GetLeaveRequestApprovals(id uint, viewerID uint, userRole string) ([]dtos.LeaveRequestApprovalResponse, error)
+	GetPendingApprovals(approverID uint, userRole string, req *dtos.GetPendingApprovalsRequest) (*dtos.GetLeaveRequestsResponse, error)
 }
 
 type leaveRequestService struct {
-	repo         repositories.LeaveRequestRepository
-	employeeRepo repositories.EmployeeRepository
+	repo           repositories.LeaveRequestRepository
+	eventRepo      repositories.LeaveRequestEventRepository
+	approvalRepo   repositories.LeaveRequestApprovalRepository
+	employeeRepo   repositories.EmployeeRepository
+	balanceService LeaveBalanceService
+	calendar       WorkingCalendar
+	approvalPolicy ApprovalPolicy
+	permissions    PermissionChecker
+	transactor     repositories.Transactor
 }
 
-func NewLeaveRequestService(repo repositories.LeaveRequestRepository, employeeRepo repositories.EmployeeRepository) LeaveRequestService {
+func NewLeaveRequestService(repo repositories.LeaveRequestRepository, eventRepo repositories.LeaveRequestEventRepository, approvalRepo repositories.LeaveRequestApprovalRepository, employeeRepo repositories.EmployeeRepository, balanceService LeaveBalanceService, calendar WorkingCalendar, approvalPolicy ApprovalPolicy, permissions PermissionChecker, transactor repositories.Transactor) LeaveRequestService {
 	return &leaveRequestService{
-		repo:         repo,
-		employeeRepo: employeeRepo,
+		repo:           repo,
+		eventRepo:      eventRepo,
+		approvalRepo:   approvalRepo,
+		employeeRepo:   employeeRepo,
+		balanceService: balanceService,
+		calendar:       calendar,
+		approvalPolicy: approvalPolicy,
+		permissions:    permissions,
+		transactor:     transactor,
 	}
 }
 
@@ -38,41 +63,80 @@ func (s *leaveRequestService) CreateLeaveRequest(employeeID uint, req *dtos.Crea
 	_, err := s.employeeRepo.FindByID(employeeID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("employee not found")
+			return nil, ErrEmployeeNotFound
 		}
 		return nil, err
 	}
 
-	// Validate date range
-	if req.StartDate.After(req.EndDate) {
-		return nil, errors.New("start date cannot be after end date")
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = "full_day"
+	}
+
+	// Validate date range and snap it onto the granularity's window
+	startDate, endDate, err := normalizeLeavePeriod(granularity, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate not in the past (check if start date is before current time)
 	now := time.Now()
 	if req.StartDate.Before(now) {
-		return nil, errors.New("cannot create leave request for past dates")
+		return nil, ErrLeaveRequestInPast
 	}
 
 	// Check for overlapping approved leave requests
-	hasOverlap, err := s.repo.HasOverlappingApprovedLeave(employeeID, req.StartDate, req.EndDate, nil)
+	hasOverlap, err := s.repo.HasOverlappingApprovedLeave(employeeID, startDate, endDate, nil)
 	if err != nil {
 		return nil, err
 	}
 	if hasOverlap {
-		return nil, errors.New("overlapping approved leave request exists for this date range")
+		return nil, ErrOverlappingLeave
 	}
 
-	leaveRequest := &models.LeaveRequest{
-		EmployeeID: employeeID,
-		StartDate:  req.StartDate,
-		EndDate:    req.EndDate,
-		Type:       req.Type,
-		Status:     "pending",
-		Reason:     req.Reason,
+	// Reject requests that do not cover a single working day
+	workingDays, err := chargeableDays(s.calendar, granularity, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if workingDays == 0 {
+		return nil, ErrNoWorkingDays
+	}
+
+	// Check the request fits in the remaining balance of its leave year
+	if err := s.balanceService.EnsureSufficientBalance(employeeID, req.Type, startDate.Year(), workingDays); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.Create(leaveRequest); err != nil {
+	// Drafts are kept aside until the employee submits them for approval
+	status := "pending"
+	if req.Draft {
+		status = "draft"
+	}
+
+	leaveRequest := &models.LeaveRequest{
+		EmployeeID:  employeeID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Granularity: granularity,
+		WorkingDays: workingDays,
+		Type:        req.Type,
+		Status:      status,
+		Reason:      req.Reason,
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(leaveRequest); err != nil {
+			return err
+		}
+		if leaveRequest.Status == "pending" {
+			if err := s.approvalRepo.WithTx(tx).CreateSteps(s.approvalPolicy.StepsFor(leaveRequest)); err != nil {
+				return err
+			}
+		}
+		return recordLeaveRequestEvent(s.eventRepo.WithTx(tx), leaveRequest.ID, employeeID, "created", nil, leaveRequest)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -85,11 +149,45 @@ func (s *leaveRequestService) CreateLeaveRequest(employeeID uint, req *dtos.Crea
 	return s.toLeaveRequestResponse(leaveRequest), nil
 }
 
-func (s *leaveRequestService) GetLeaveRequestByID(id uint) (*dtos.LeaveRequestResponse, error) {
+func (s *leaveRequestService) GetLeaveRequestByID(id uint, viewerID uint, userRole string) (*dtos.LeaveRequestResponse, error) {
+	leaveRequest, err := s.findVisibleLeaveRequest(id, viewerID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toLeaveRequestResponse(leaveRequest), nil
+}
+
+// GetLeaveRequests lists the leave requests the viewer may see: their own,
+// those of their direct reports with leave:read:team, and everyone's with
+// leave:read:all.
+func (s *leaveRequestService) GetLeaveRequests(viewerID uint, userRole string, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.listLeaveRequests(viewerID, permissions, req)
+}
+
+// GetOwnLeaveRequests lists the employee's own leave requests, whatever
+// else their role lets them see.
+func (s *leaveRequestService) GetOwnLeaveRequests(employeeID uint, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error) {
+	req.EmployeeID = &employeeID
+	return s.listLeaveRequests(employeeID, Permissions{}, req)
+}
+
+// GetLeaveRequestByIDForAPIKey reads a leave request for a service API key,
+// which needs leave:read:all as it has no employee of its own.
+func (s *leaveRequestService) GetLeaveRequestByIDForAPIKey(id uint, apiKey *APIKeyIdentity) (*dtos.LeaveRequestResponse, error) {
+	if !apiKey.Permissions.Has(PermissionLeaveReadAll) {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionLeaveReadAll)
+	}
+
 	leaveRequest, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("leave request not found")
+			return nil, ErrLeaveRequestNotFound
 		}
 		return nil, err
 	}
@@ -97,7 +195,17 @@ func (s *leaveRequestService) GetLeaveRequestByID(id uint) (*dtos.LeaveRequestRe
 	return s.toLeaveRequestResponse(leaveRequest), nil
 }
 
-func (s *leaveRequestService) GetLeaveRequests(req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error) {
+// GetLeaveRequestsForAPIKey lists leave requests for a service API key, which
+// needs leave:read:all as it has no employee of its own.
+func (s *leaveRequestService) GetLeaveRequestsForAPIKey(apiKey *APIKeyIdentity, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error) {
+	if !apiKey.Permissions.Has(PermissionLeaveReadAll) {
+		return nil, ErrPermissionDenied.Withf("missing permission %s", PermissionLeaveReadAll)
+	}
+
+	return s.listLeaveRequests(0, apiKey.Permissions, req)
+}
+
+func (s *leaveRequestService) listLeaveRequests(viewerID uint, permissions Permissions, req *dtos.GetLeaveRequestsRequest) (*dtos.GetLeaveRequestsResponse, error) {
 	// Set default values
 	if req.Page < 1 {
 		req.Page = 1
@@ -115,10 +223,21 @@ func (s *leaveRequestService) GetLeaveRequests(req *dtos.GetLeaveRequestsRequest
 		req.SortBy = "created_at"
 	}
 
+	employeeID := req.EmployeeID
+	var managerID *uint
+	switch {
+	case permissions.Has(PermissionLeaveReadAll):
+	case permissions.Has(PermissionLeaveReadTeam):
+		managerID = &viewerID
+	default:
+		employeeID = &viewerID
+	}
+
 	leaveRequests, total, err := s.repo.FindAll(
 		req.Page,
 		req.PageSize,
-		req.EmployeeID,
+		employeeID,
+		managerID,
 		req.Status,
 		req.Type,
 		req.StartDate,
@@ -148,26 +267,76 @@ func (s *leaveRequestService) GetLeaveRequests(req *dtos.GetLeaveRequestsRequest
 	}, nil
 }
 
+// GetPendingApprovals lists the leave requests waiting on a decision from the
+// caller: those whose current approval step is theirs to sign off and the
+// cancellation requests of their direct reports.
+func (s *leaveRequestService) GetPendingApprovals(approverID uint, userRole string, req *dtos.GetPendingApprovalsRequest) (*dtos.GetLeaveRequestsResponse, error) {
+	// Set default values
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 {
+		req.PageSize = 10
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveRequests, total, err := s.repo.FindPendingApproval(approverID, strings.ToLower(userRole), permissions.Has(PermissionLeaveApproveAll), req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	leaveRequestResponses := make([]dtos.LeaveRequestResponse, len(leaveRequests))
+	for i, lr := range leaveRequests {
+		leaveRequestResponses[i] = *s.toLeaveRequestResponse(&lr)
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(req.PageSize)))
+
+	return &dtos.GetLeaveRequestsResponse{
+		Data: leaveRequestResponses,
+		Pagination: dtos.PaginationMetadata{
+			CurrentPage: req.Page,
+			PageSize:    req.PageSize,
+			TotalPages:  totalPages,
+			TotalItems:  total,
+		},
+	}, nil
+}
+
 func (s *leaveRequestService) UpdateLeaveRequest(id uint, employeeID uint, userRole string, req *dtos.UpdateLeaveRequestRequest) (*dtos.LeaveRequestResponse, error) {
 	// Get existing leave request
 	leaveRequest, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("leave request not found")
+			return nil, ErrLeaveRequestNotFound
 		}
 		return nil, err
 	}
 
-	// Authorization check: only owner or HR/manager can update
-	if leaveRequest.EmployeeID != employeeID && userRole != "hr" && userRole != "manager" {
-		return nil, errors.New("unauthorized to update this leave request")
+	// Authorization check: only the owner, their manager with
+	// leave:update:team or leave:update:all can update
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+	if !canUpdateLeaveRequest(leaveRequest, employeeID, permissions) {
+		return nil, ErrUpdateForbidden
 	}
 
-	// Only HR/manager can update status
-	if req.Status != nil && userRole != "hr" && userRole != "manager" {
-		return nil, errors.New("only HR or manager can update leave request status")
+	// Decided requests can only change through status transitions
+	if leaveRequest.Status != "draft" && leaveRequest.Status != "pending" {
+		return nil, ErrLeaveRequestNotEditable
 	}
 
+	previous := *leaveRequest
+
 	// Update fields if provided
 	if req.StartDate != nil {
 		leaveRequest.StartDate = *req.StartDate
@@ -175,26 +344,31 @@ func (s *leaveRequestService) UpdateLeaveRequest(id uint, employeeID uint, userR
 	if req.EndDate != nil {
 		leaveRequest.EndDate = *req.EndDate
 	}
+	if req.Granularity != nil {
+		leaveRequest.Granularity = *req.Granularity
+	}
 	if req.Type != nil {
 		leaveRequest.Type = *req.Type
 	}
-	if req.Status != nil {
-		leaveRequest.Status = *req.Status
-	}
 	if req.Reason != nil {
 		leaveRequest.Reason = req.Reason
 	}
 
-	// Validate date range
-	if leaveRequest.StartDate.After(leaveRequest.EndDate) {
-		return nil, errors.New("start date cannot be after end date")
-	}
+	// Re-validate the period only if it is being changed
+	if req.StartDate != nil || req.EndDate != nil || req.Granularity != nil {
+		if leaveRequest.Granularity == "" {
+			leaveRequest.Granularity = "full_day"
+		}
+
+		requestedStart := leaveRequest.StartDate
+		leaveRequest.StartDate, leaveRequest.EndDate, err = normalizeLeavePeriod(leaveRequest.Granularity, leaveRequest.StartDate, leaveRequest.EndDate)
+		if err != nil {
+			return nil, err
+		}
 
-	// Validate not in the past (only if dates are being changed)
-	if req.StartDate != nil || req.EndDate != nil {
 		now := time.Now()
-		if leaveRequest.StartDate.Before(now) {
-			return nil, errors.New("cannot update leave request to past dates")
+		if requestedStart.Before(now) {
+			return nil, ErrLeaveRequestInPast.Withf("cannot update leave request to past dates")
 		}
 
 		// Check for overlapping approved leave (exclude current request)
@@ -203,11 +377,37 @@ func (s *leaveRequestService) UpdateLeaveRequest(id uint, employeeID uint, userR
 			return nil, err
 		}
 		if hasOverlap {
-			return nil, errors.New("overlapping approved leave request exists for this date range")
+			return nil, ErrOverlappingLeave
+		}
+
+		workingDays, err := chargeableDays(s.calendar, leaveRequest.Granularity, leaveRequest.StartDate, leaveRequest.EndDate)
+		if err != nil {
+			return nil, err
 		}
+		if workingDays == 0 {
+			return nil, ErrNoWorkingDays
+		}
+		leaveRequest.WorkingDays = workingDays
 	}
 
-	if err := s.repo.Update(leaveRequest); err != nil {
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Update(leaveRequest); err != nil {
+			return err
+		}
+		// Earlier sign-offs were given on the old terms, so the approval
+		// chain starts over
+		if leaveRequest.Status == "pending" {
+			approvalRepo := s.approvalRepo.WithTx(tx)
+			if err := approvalRepo.SupersedeOpenSteps(id); err != nil {
+				return err
+			}
+			if err := approvalRepo.CreateSteps(s.approvalPolicy.StepsFor(leaveRequest)); err != nil {
+				return err
+			}
+		}
+		return recordLeaveRequestEvent(s.eventRepo.WithTx(tx), id, employeeID, "updated", &previous, leaveRequest)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -220,86 +420,263 @@ func (s *leaveRequestService) UpdateLeaveRequest(id uint, employeeID uint, userR
 	return s.toLeaveRequestResponse(leaveRequest), nil
 }
 
+// DeleteLeaveRequest removes an undecided leave request. Approved leave is
+// not deleted but cancelled through CancelLeaveRequest.
 func (s *leaveRequestService) DeleteLeaveRequest(id uint, employeeID uint, userRole string) error {
 	// Get existing leave request
 	leaveRequest, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("leave request not found")
+			return ErrLeaveRequestNotFound
 		}
 		return err
 	}
 
-	// Authorization check: only owner or HR/manager can delete
-	if leaveRequest.EmployeeID != employeeID && userRole != "hr" && userRole != "manager" {
-		return errors.New("unauthorized to delete this leave request")
+	// Authorization check: only the owner, their manager with
+	// leave:update:team or leave:update:all can delete
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return err
+	}
+	if !canUpdateLeaveRequest(leaveRequest, employeeID, permissions) {
+		return ErrDeleteForbidden
+	}
+
+	if leaveRequest.Status != "draft" && leaveRequest.Status != "pending" {
+		return ErrLeaveRequestNotEditable.Withf("only draft or pending leave requests can be deleted")
 	}
 
-	return s.repo.Delete(id)
+	return s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Delete(id); err != nil {
+			return err
+		}
+		if err := s.syncBalance(s.balanceService.WithTx(tx), leaveRequest, nil); err != nil {
+			return err
+		}
+		return recordLeaveRequestEvent(s.eventRepo.WithTx(tx), id, employeeID, "deleted", leaveRequest, nil)
+	})
 }
 
-func (s *leaveRequestService) ApproveLeaveRequest(id uint, userRole string) (*dtos.LeaveRequestResponse, error) {
-	// Only HR can approve
-	if userRole != "hr" && userRole != "HR" {
-		return nil, errors.New("only HR can approve leave requests")
-	}
-
+// ApproveLeaveRequest signs off the current step of the approval chain. The
+// leave request is approved once its last step is. Approving a cancellation
+// request declines the cancellation.
+func (s *leaveRequestService) ApproveLeaveRequest(id uint, approverID uint, userRole string, req *dtos.DecideLeaveRequestRequest) (*dtos.LeaveRequestResponse, error) {
 	// Get existing leave request
 	leaveRequest, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("leave request not found")
+			return nil, ErrLeaveRequestNotFound
+		}
+		return nil, err
+	}
+
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if leaveRequest.Status != "pending" {
+		// Only the requester's manager, or leave:approve:all as an override,
+		// can approve
+		if !canDecideLeaveRequest(leaveRequest, approverID, permissions) {
+			return nil, ErrApproveForbidden
 		}
+
+		recordDecision(leaveRequest, approverID, req)
+		return s.changeStatus(leaveRequest, approverID, "approved", nil)
+	}
+
+	steps, step, err := s.currentApprovalStep(leaveRequest)
+	if err != nil {
 		return nil, err
 	}
+	if !s.approvalPolicy.CanApprove(step, leaveRequest, approverID, userRole, permissions) {
+		if step.Approver == lineManagerApprover {
+			return nil, ErrApproveForbidden
+		}
+		return nil, ErrApprovalStepForbidden
+	}
+
+	decideApprovalStep(step, "approved", approverID, req)
+	if step.Step < len(steps) {
+		return s.advanceApprovalChain(leaveRequest, approverID, steps, step)
+	}
 
-	// Check for overlapping approved leave requests before approving
+	// The leave is about to take effect, so it must not clash with leave
+	// approved in the meantime
 	hasOverlap, err := s.repo.HasOverlappingApprovedLeave(leaveRequest.EmployeeID, leaveRequest.StartDate, leaveRequest.EndDate, &id)
 	if err != nil {
 		return nil, err
 	}
 	if hasOverlap {
-		return nil, errors.New("overlapping approved leave request exists for this date range")
+		return nil, ErrOverlappingLeave
+	}
+
+	recordDecision(leaveRequest, approverID, req)
+	return s.changeStatus(leaveRequest, approverID, "approved", func(tx *gorm.DB) error {
+		return saveApprovalDecision(s.approvalRepo.WithTx(tx), steps, step)
+	})
+}
+
+// RejectLeaveRequest turns down the leave request at its current approval
+// step, ending the chain.
+func (s *leaveRequestService) RejectLeaveRequest(id uint, approverID uint, userRole string, req *dtos.DecideLeaveRequestRequest) (*dtos.LeaveRequestResponse, error) {
+	// Employees must be told why their leave was refused
+	if req == nil || req.Comment == nil || strings.TrimSpace(*req.Comment) == "" {
+		return nil, ErrRejectionReasonRequired
 	}
 
-	// Update status to approved
-	leaveRequest.Status = "approved"
-	if err := s.repo.Update(leaveRequest); err != nil {
+	// Get existing leave request
+	leaveRequest, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveRequestNotFound
+		}
 		return nil, err
 	}
 
-	// Reload to get updated employee data
-	leaveRequest, err = s.repo.FindByID(id)
+	permissions, err := s.permissions.PermissionsFor(userRole)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.toLeaveRequestResponse(leaveRequest), nil
+	if leaveRequest.Status != "pending" {
+		// Only the requester's manager, or leave:approve:all as an override,
+		// can reject
+		if !canDecideLeaveRequest(leaveRequest, approverID, permissions) {
+			return nil, ErrRejectForbidden
+		}
+
+		recordDecision(leaveRequest, approverID, req)
+		return s.changeStatus(leaveRequest, approverID, "rejected", nil)
+	}
+
+	steps, step, err := s.currentApprovalStep(leaveRequest)
+	if err != nil {
+		return nil, err
+	}
+	if !s.approvalPolicy.CanApprove(step, leaveRequest, approverID, userRole, permissions) {
+		if step.Approver == lineManagerApprover {
+			return nil, ErrRejectForbidden
+		}
+		return nil, ErrApprovalStepForbidden
+	}
+
+	decideApprovalStep(step, "rejected", approverID, req)
+	recordDecision(leaveRequest, approverID, req)
+	return s.changeStatus(leaveRequest, approverID, "rejected", func(tx *gorm.DB) error {
+		return saveApprovalDecision(s.approvalRepo.WithTx(tx), steps, step)
+	})
 }
 
-func (s *leaveRequestService) RejectLeaveRequest(id uint, userRole string) (*dtos.LeaveRequestResponse, error) {
-	// Only HR can reject
-	if userRole != "hr" && userRole != "HR" {
-		return nil, errors.New("only HR can reject leave requests")
+// SubmitLeaveRequest sends a draft leave request for approval.
+func (s *leaveRequestService) SubmitLeaveRequest(id uint, employeeID uint) (*dtos.LeaveRequestResponse, error) {
+	leaveRequest, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveRequestNotFound
+		}
+		return nil, err
 	}
 
-	// Get existing leave request
+	// Only the requester can submit their draft
+	if leaveRequest.EmployeeID != employeeID {
+		return nil, ErrSubmitForbidden
+	}
+
+	return s.changeStatus(leaveRequest, employeeID, "pending", func(tx *gorm.DB) error {
+		return s.approvalRepo.WithTx(tx).CreateSteps(s.approvalPolicy.StepsFor(leaveRequest))
+	})
+}
+
+// CancelLeaveRequest cancels an approved leave request. HR and the requester's
+// manager cancel it outright, crediting the balance back; the requester can only ask for the
+// cancellation, which HR then confirms by cancelling or declines by approving.
+// HR here stands for any role with leave:approve:all.
+func (s *leaveRequestService) CancelLeaveRequest(id uint, employeeID uint, userRole string) (*dtos.LeaveRequestResponse, error) {
+	leaveRequest, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveRequestNotFound
+		}
+		return nil, err
+	}
+
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if canDecideLeaveRequest(leaveRequest, employeeID, permissions) {
+		return s.changeStatus(leaveRequest, employeeID, "cancelled", nil)
+	}
+
+	// Authorization check: only owner can request a cancellation
+	if leaveRequest.EmployeeID != employeeID {
+		return nil, ErrCancelForbidden
+	}
+
+	return s.changeStatus(leaveRequest, employeeID, "cancellation_requested", nil)
+}
+
+// WithdrawLeaveRequest lets the requester take back a leave request that has
+// not been decided yet.
+func (s *leaveRequestService) WithdrawLeaveRequest(id uint, employeeID uint) (*dtos.LeaveRequestResponse, error) {
 	leaveRequest, err := s.repo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("leave request not found")
+			return nil, ErrLeaveRequestNotFound
 		}
 		return nil, err
 	}
 
-	// Update status to rejected
-	leaveRequest.Status = "rejected"
-	if err := s.repo.Update(leaveRequest); err != nil {
+	// Only the requester can withdraw their leave request
+	if leaveRequest.EmployeeID != employeeID {
+		return nil, ErrWithdrawForbidden
+	}
+
+	return s.changeStatus(leaveRequest, employeeID, "withdrawn", nil)
+}
+
+// changeStatus moves the leave request through the state machine and keeps
+// the balance ledger and audit trail in step within a single transaction.
+// alsoInTx, when set, runs within the same transaction.
+func (s *leaveRequestService) changeStatus(leaveRequest *models.LeaveRequest, actorID uint, status string, alsoInTx func(tx *gorm.DB) error) (*dtos.LeaveRequestResponse, error) {
+	previous := *leaveRequest
+	if err := transitionLeaveRequest(leaveRequest, status); err != nil {
+		return nil, err
+	}
+	// Requests from before working days were stored still have none. They
+	// are counted with the working calendar, holidays included, when they are
+	// approved; credits only give back what the ledger shows was debited.
+	if status == "approved" && leaveRequest.WorkingDays == 0 {
+		workingDays, err := chargeableDays(s.calendar, leaveRequest.Granularity, leaveRequest.StartDate, leaveRequest.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		leaveRequest.WorkingDays = workingDays
+	}
+
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Update(leaveRequest); err != nil {
+			return err
+		}
+		if err := s.syncBalance(s.balanceService.WithTx(tx), &previous, leaveRequest); err != nil {
+			return err
+		}
+		if alsoInTx != nil {
+			if err := alsoInTx(tx); err != nil {
+				return err
+			}
+		}
+		return recordLeaveRequestEvent(s.eventRepo.WithTx(tx), leaveRequest.ID, actorID, statusAction(status), &previous, leaveRequest)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Reload to get updated employee data
-	leaveRequest, err = s.repo.FindByID(id)
+	leaveRequest, err = s.repo.FindByID(leaveRequest.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -307,17 +684,269 @@ func (s *leaveRequestService) RejectLeaveRequest(id uint, userRole string) (*dto
 	return s.toLeaveRequestResponse(leaveRequest), nil
 }
 
+// GetLeaveRequestHistory returns the audit trail of a leave request, oldest
+// first. The history of deleted leave requests stays available with
+// leave:read:all.
+func (s *leaveRequestService) GetLeaveRequestHistory(id uint, viewerID uint, userRole string) ([]dtos.LeaveRequestEventResponse, error) {
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if !permissions.Has(PermissionLeaveReadAll) {
+		if _, err := s.findVisibleLeaveRequest(id, viewerID, userRole); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := s.eventRepo.FindByLeaveRequestID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, ErrLeaveRequestNotFound
+	}
+
+	eventResponses := make([]dtos.LeaveRequestEventResponse, len(events))
+	for i, event := range events {
+		eventResponses[i] = dtos.LeaveRequestEventResponse{
+			ID:             event.ID,
+			LeaveRequestID: event.LeaveRequestID,
+			ActorID:        event.ActorID,
+			Action:         event.Action,
+			Changes:        json.RawMessage(event.Changes),
+			CreatedAt:      event.CreatedAt,
+		}
+	}
+
+	return eventResponses, nil
+}
+
+// GetLeaveRequestApprovals returns the current approval chain of a leave
+// request in step order.
+func (s *leaveRequestService) GetLeaveRequestApprovals(id uint, viewerID uint, userRole string) ([]dtos.LeaveRequestApprovalResponse, error) {
+	leaveRequest, err := s.findVisibleLeaveRequest(id, viewerID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := s.approvalRepo.FindByLeaveRequestID(id)
+	if err != nil {
+		return nil, err
+	}
+	// Requests submitted before approval chains existed get theirs on the
+	// first decision
+	if len(steps) == 0 && leaveRequest.Status == "pending" {
+		steps = s.approvalPolicy.StepsFor(leaveRequest)
+	}
+
+	approvalResponses := make([]dtos.LeaveRequestApprovalResponse, len(steps))
+	for i, step := range steps {
+		approvalResponses[i] = dtos.LeaveRequestApprovalResponse{
+			Step:      step.Step,
+			Approver:  step.Approver,
+			Status:    step.Status,
+			DecidedBy: step.DecidedBy,
+			DecidedAt: step.DecidedAt,
+			Comment:   step.Comment,
+		}
+	}
+
+	return approvalResponses, nil
+}
+
+// currentApprovalStep loads the approval chain of a pending leave request and
+// returns it with its first undecided step. Requests submitted before
+// approval chains existed get a chain built from the current configuration.
+func (s *leaveRequestService) currentApprovalStep(leaveRequest *models.LeaveRequest) ([]models.LeaveRequestApproval, *models.LeaveRequestApproval, error) {
+	steps, err := s.approvalRepo.FindByLeaveRequestID(leaveRequest.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(steps) == 0 {
+		steps = s.approvalPolicy.StepsFor(leaveRequest)
+	}
+
+	for i := range steps {
+		if steps[i].Status == "pending" {
+			return steps, &steps[i], nil
+		}
+	}
+	return nil, nil, errors.New("leave request has no pending approval step")
+}
+
+// advanceApprovalChain records an intermediate sign-off; the leave request
+// stays pending until the next step is decided.
+func (s *leaveRequestService) advanceApprovalChain(leaveRequest *models.LeaveRequest, actorID uint, steps []models.LeaveRequestApproval, step *models.LeaveRequestApproval) (*dtos.LeaveRequestResponse, error) {
+	changes, err := diffApprovalStep(step, "pending")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := saveApprovalDecision(s.approvalRepo.WithTx(tx), steps, step); err != nil {
+			return err
+		}
+		return s.eventRepo.WithTx(tx).Create(&models.LeaveRequestEvent{
+			LeaveRequestID: leaveRequest.ID,
+			ActorID:        actorID,
+			Action:         "step_approved",
+			Changes:        changes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toLeaveRequestResponse(leaveRequest), nil
+}
+
+// decideApprovalStep records the decision on an approval step.
+func decideApprovalStep(step *models.LeaveRequestApproval, status string, deciderID uint, req *dtos.DecideLeaveRequestRequest) {
+	now := time.Now()
+	step.Status = status
+	step.DecidedBy = &deciderID
+	step.DecidedAt = &now
+	if req != nil && req.Comment != nil {
+		if comment := strings.TrimSpace(*req.Comment); comment != "" {
+			step.Comment = &comment
+		}
+	}
+}
+
+// saveApprovalDecision stores the decided step. A chain that was built on
+// the fly for an older request is stored as a whole.
+func saveApprovalDecision(approvalRepo repositories.LeaveRequestApprovalRepository, steps []models.LeaveRequestApproval, step *models.LeaveRequestApproval) error {
+	if step.ID == 0 {
+		return approvalRepo.CreateSteps(steps)
+	}
+	return approvalRepo.Update(step)
+}
+
+// findVisibleLeaveRequest loads a leave request the viewer may see. Leave
+// requests outside the viewer's scope are reported as not found, so their
+// existence is not disclosed.
+func (s *leaveRequestService) findVisibleLeaveRequest(id uint, viewerID uint, userRole string) (*models.LeaveRequest, error) {
+	leaveRequest, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaveRequestNotFound
+		}
+		return nil, err
+	}
+
+	permissions, err := s.permissions.PermissionsFor(userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canViewLeaveRequest(leaveRequest, viewerID, permissions) {
+		return nil, ErrLeaveRequestNotFound
+	}
+
+	return leaveRequest, nil
+}
+
+// canViewLeaveRequest reports whether the viewer may read the leave request:
+// their own, their direct reports' with leave:read:team, or any with
+// leave:read:all.
+func canViewLeaveRequest(leaveRequest *models.LeaveRequest, viewerID uint, permissions Permissions) bool {
+	if leaveRequest.EmployeeID == viewerID || permissions.Has(PermissionLeaveReadAll) {
+		return true
+	}
+	if !permissions.Has(PermissionLeaveReadTeam) {
+		return false
+	}
+	employee := leaveRequest.Employee
+	return employee != nil && employee.ManagerID != nil && *employee.ManagerID == viewerID
+}
+
+// canUpdateLeaveRequest reports whether the actor may edit or delete the
+// leave request: their own, their direct reports' with leave:update:team, or
+// any with leave:update:all.
+func canUpdateLeaveRequest(leaveRequest *models.LeaveRequest, actorID uint, permissions Permissions) bool {
+	if leaveRequest.EmployeeID == actorID || permissions.Has(PermissionLeaveUpdateAll) {
+		return true
+	}
+	if !permissions.Has(PermissionLeaveUpdateTeam) {
+		return false
+	}
+	employee := leaveRequest.Employee
+	return employee != nil && employee.ManagerID != nil && *employee.ManagerID == actorID
+}
+
+// canDecideLeaveRequest reports whether the actor may approve, reject or
+// cancel the leave request: the requester's line manager can, and holders of
+// leave:approve:all can override. Nobody decides on their own leave.
+func canDecideLeaveRequest(leaveRequest *models.LeaveRequest, actorID uint, permissions Permissions) bool {
+	if leaveRequest.EmployeeID == actorID {
+		return false
+	}
+	if permissions.Has(PermissionLeaveApproveAll) {
+		return true
+	}
+	employee := leaveRequest.Employee
+	return employee != nil && employee.ManagerID != nil && *employee.ManagerID == actorID
+}
+
+// recordDecision stamps who decided on the leave request, when, and with
+// which comment, replacing any earlier decision.
+func recordDecision(leaveRequest *models.LeaveRequest, deciderID uint, req *dtos.DecideLeaveRequestRequest) {
+	decidedAt := time.Now()
+	leaveRequest.DecidedBy = &deciderID
+	leaveRequest.DecidedAt = &decidedAt
+	leaveRequest.DecisionComment = nil
+	if req != nil && req.Comment != nil {
+		if comment := strings.TrimSpace(*req.Comment); comment != "" {
+			leaveRequest.DecisionComment = &comment
+		}
+	}
+}
+
+// syncBalance keeps the balance ledger in step with a change to a leave request.
+// The previous state is credited back if it held balance and the new state is
+// debited if it does; after is nil when the request is being deleted.
+func (s *leaveRequestService) syncBalance(balanceService LeaveBalanceService, before, after *models.LeaveRequest) error {
+	wasApproved := before != nil && holdsBalance(before.Status)
+	isApproved := after != nil && holdsBalance(after.Status)
+
+	if wasApproved && isApproved &&
+		before.Type == after.Type &&
+		before.StartDate.Year() == after.StartDate.Year() &&
+		before.WorkingDays == after.WorkingDays {
+		return nil
+	}
+
+	if wasApproved {
+		if err := balanceService.Credit(before); err != nil {
+			return err
+		}
+	}
+	if isApproved {
+		if err := balanceService.Debit(after, after.WorkingDays); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *leaveRequestService) toLeaveRequestResponse(lr *models.LeaveRequest) *dtos.LeaveRequestResponse {
 	response := &dtos.LeaveRequestResponse{
-		ID:         lr.ID,
-		EmployeeID: lr.EmployeeID,
-		StartDate:  lr.StartDate,
-		EndDate:    lr.EndDate,
-		Type:       lr.Type,
-		Status:     lr.Status,
-		Reason:     lr.Reason,
-		CreatedAt:  lr.CreatedAt,
-		UpdatedAt:  lr.UpdatedAt,
+		ID:              lr.ID,
+		EmployeeID:      lr.EmployeeID,
+		StartDate:       lr.StartDate,
+		EndDate:         lr.EndDate,
+		Granularity:     lr.Granularity,
+		WorkingDays:     lr.WorkingDays,
+		Type:            lr.Type,
+		Status:          lr.Status,
+		Reason:          lr.Reason,
+		DecisionComment: lr.DecisionComment,
+		DecidedBy:       lr.DecidedBy,
+		DecidedAt:       lr.DecidedAt,
+		CreatedAt:       lr.CreatedAt,
+		UpdatedAt:       lr.UpdatedAt,
 	}
 
 	if lr.Employee != nil {
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
 	"hr-leave-request/dtos"
 	"hr-leave-request/models"
@@ -13,17 +14,51 @@ import (
 	"gorm.io/gorm"
 )
 
+func newTestLeaveRequestService(repo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) LeaveRequestService {
+	cfg := setupTestConfig()
+	balanceService := NewLeaveBalanceService(balanceRepo, empRepo, newTestPermissionChecker(), cfg)
+	holidayRepo := new(mocks.MockHolidayRepository)
+	holidayRepo.On("FindBetween", mock.Anything, mock.Anything).Return([]models.Holiday{}, nil).Maybe()
+	calendar, _ := NewWorkingCalendar(holidayRepo, cfg)
+	eventRepo := new(mocks.MockLeaveRequestEventRepository)
+	eventRepo.On("Create", mock.AnythingOfType("*models.LeaveRequestEvent")).Return(nil).Maybe()
+	approvalPolicy, _ := NewApprovalPolicy(cfg)
+	return NewLeaveRequestService(repo, eventRepo, newTestApprovalRepository(), empRepo, balanceService, calendar, approvalPolicy, newTestPermissionChecker(), &mocks.MockTransactor{})
+}
+
+// newTestApprovalRepository returns an approval repository without stored
+// chains, so leave requests get the configured chain on their first decision.
+func newTestApprovalRepository() *mocks.MockLeaveRequestApprovalRepository {
+	approvalRepo := new(mocks.MockLeaveRequestApprovalRepository)
+	approvalRepo.On("FindByLeaveRequestID", mock.Anything).Return([]models.LeaveRequestApproval{}, nil).Maybe()
+	approvalRepo.On("CreateSteps", mock.Anything).Return(nil).Maybe()
+	approvalRepo.On("SupersedeOpenSteps", mock.Anything).Return(nil).Maybe()
+	approvalRepo.On("Update", mock.Anything).Return(nil).Maybe()
+	return approvalRepo
+}
+
+// nextMonday returns 09:00 on the first Monday after t, giving tests a stable
+// number of working days regardless of the day they run on.
+func nextMonday(t time.Time) time.Time {
+	day := t.AddDate(0, 0, 1)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, day.Location())
+}
+
 func TestCreateLeaveRequest(t *testing.T) {
 	now := time.Now()
-	future := now.Add(48 * time.Hour)
-	futureEnd := now.Add(72 * time.Hour)
+	future := nextMonday(now.AddDate(0, 0, 2))
+	futureEnd := future.Add(24 * time.Hour)
+	periodStart, periodEnd := startOfDay(future), endOfDay(futureEnd)
 	reason := "Need vacation"
 
 	tests := []struct {
 		name       string
 		employeeID uint
 		request    *dtos.CreateLeaveRequestRequest
-		mockSetup  func(*mocks.MockLeaveRequestRepository, *mocks.MockEmployeeRepository)
+		mockSetup  func(*mocks.MockLeaveRequestRepository, *mocks.MockEmployeeRepository, *mocks.MockLeaveBalanceRepository)
 		wantError  bool
 		errorMsg   string
 		checkFunc  func(*dtos.LeaveRequestResponse)
@@ -37,15 +72,18 @@ func TestCreateLeaveRequest(t *testing.T) {
 				Type:      "vacation",
 				Reason:    &reason,
 			},
-			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository) {
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				employee := &models.Employee{
 					ID:    1,
 					Name:  "John Doe",
 					Email: "john@example.com",
 				}
 				empRepo.On("FindByID", uint(1)).Return(employee, nil)
-				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, (*uint)(nil)).Return(false, nil)
-				leaveRepo.On("Create", mock.AnythingOfType("*models.LeaveRequest")).
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), periodStart, periodEnd, (*uint)(nil)).Return(false, nil)
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(nil, gorm.ErrRecordNotFound)
+				leaveRepo.On("Create", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.WorkingDays == 2
+				})).
 					Return(nil).
 					Run(func(args mock.Arguments) {
 						lr := args.Get(0).(*models.LeaveRequest)
@@ -55,16 +93,17 @@ func TestCreateLeaveRequest(t *testing.T) {
 					})
 
 				leaveRequest := &models.LeaveRequest{
-					ID:         1,
-					EmployeeID: 1,
-					Employee:   employee,
-					StartDate:  future,
-					EndDate:    futureEnd,
-					Type:       "vacation",
-					Status:     "pending",
-					Reason:     &reason,
-					CreatedAt:  now,
-					UpdatedAt:  now,
+					ID:          1,
+					EmployeeID:  1,
+					Employee:    employee,
+					StartDate:   future,
+					EndDate:     futureEnd,
+					WorkingDays: 2,
+					Type:        "vacation",
+					Status:      "pending",
+					Reason:      &reason,
+					CreatedAt:   now,
+					UpdatedAt:   now,
 				}
 				leaveRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
 			},
@@ -74,7 +113,117 @@ func TestCreateLeaveRequest(t *testing.T) {
 				assert.Equal(t, uint(1), resp.EmployeeID)
 				assert.Equal(t, "vacation", resp.Type)
 				assert.Equal(t, "pending", resp.Status)
+				assert.Equal(t, float64(2), resp.WorkingDays)
+			},
+		},
+		{
+			name:       "afternoon half day",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate:   future,
+				EndDate:     future,
+				Granularity: "half_day_pm",
+				Type:        "vacation",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+				noon := startOfDay(future).Add(12 * time.Hour)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), noon, endOfDay(future), (*uint)(nil)).Return(false, nil)
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(nil, gorm.ErrRecordNotFound)
+				leaveRepo.On("Create", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Granularity == "half_day_pm" && lr.WorkingDays == 0.5
+				})).
+					Return(nil).
+					Run(func(args mock.Arguments) {
+						args.Get(0).(*models.LeaveRequest).ID = 2
+					})
+				leaveRepo.On("FindByID", uint(2)).Return(&models.LeaveRequest{
+					ID:          2,
+					EmployeeID:  1,
+					StartDate:   noon,
+					EndDate:     endOfDay(future),
+					Granularity: "half_day_pm",
+					WorkingDays: 0.5,
+					Type:        "vacation",
+					Status:      "pending",
+				}, nil)
+			},
+			wantError: false,
+			checkFunc: func(resp *dtos.LeaveRequestResponse) {
+				assert.Equal(t, "half_day_pm", resp.Granularity)
+				assert.Equal(t, 0.5, resp.WorkingDays)
+			},
+		},
+		{
+			name:       "hourly leave",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate:   future,
+				EndDate:     future.Add(2 * time.Hour),
+				Granularity: "hours",
+				Type:        "personal",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), future, future.Add(2*time.Hour), (*uint)(nil)).Return(false, nil)
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "personal", future.Year()).Return(nil, gorm.ErrRecordNotFound)
+				leaveRepo.On("Create", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Granularity == "hours" && lr.WorkingDays == 0.25
+				})).
+					Return(nil).
+					Run(func(args mock.Arguments) {
+						args.Get(0).(*models.LeaveRequest).ID = 3
+					})
+				leaveRepo.On("FindByID", uint(3)).Return(&models.LeaveRequest{
+					ID:          3,
+					EmployeeID:  1,
+					StartDate:   future,
+					EndDate:     future.Add(2 * time.Hour),
+					Granularity: "hours",
+					WorkingDays: 0.25,
+					Type:        "personal",
+					Status:      "pending",
+				}, nil)
+			},
+			wantError: false,
+			checkFunc: func(resp *dtos.LeaveRequestResponse) {
+				assert.Equal(t, 0.25, resp.WorkingDays)
+			},
+		},
+		{
+			name:       "half day spanning two dates",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate:   future,
+				EndDate:     futureEnd,
+				Granularity: "half_day_am",
+				Type:        "vacation",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+			},
+			wantError: true,
+			errorMsg:  "half-day leave must start and end on the same day",
+		},
+		{
+			name:       "hourly leave longer than a working day",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate:   future.Add(-8 * time.Hour),
+				EndDate:     future.Add(10 * time.Hour),
+				Granularity: "hours",
+				Type:        "personal",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), future.Add(-8*time.Hour), future.Add(10*time.Hour), (*uint)(nil)).Return(false, nil)
 			},
+			wantError: true,
+			errorMsg:  "hourly leave cannot exceed a working day",
 		},
 		{
 			name:       "employee not found",
@@ -84,7 +233,7 @@ func TestCreateLeaveRequest(t *testing.T) {
 				EndDate:   futureEnd,
 				Type:      "vacation",
 			},
-			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository) {
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				empRepo.On("FindByID", uint(999)).Return(nil, gorm.ErrRecordNotFound)
 			},
 			wantError: true,
@@ -98,7 +247,7 @@ func TestCreateLeaveRequest(t *testing.T) {
 				EndDate:   future,
 				Type:      "vacation",
 			},
-			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository) {
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				employee := &models.Employee{ID: 1}
 				empRepo.On("FindByID", uint(1)).Return(employee, nil)
 			},
@@ -113,7 +262,7 @@ func TestCreateLeaveRequest(t *testing.T) {
 				EndDate:   now.Add(24 * time.Hour),
 				Type:      "vacation",
 			},
-			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository) {
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				employee := &models.Employee{ID: 1}
 				empRepo.On("FindByID", uint(1)).Return(employee, nil)
 			},
@@ -128,23 +277,58 @@ func TestCreateLeaveRequest(t *testing.T) {
 				EndDate:   futureEnd,
 				Type:      "vacation",
 			},
-			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository) {
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				employee := &models.Employee{ID: 1}
 				empRepo.On("FindByID", uint(1)).Return(employee, nil)
-				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, (*uint)(nil)).Return(true, nil)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), periodStart, periodEnd, (*uint)(nil)).Return(true, nil)
 			},
 			wantError: true,
 			errorMsg:  "overlapping approved leave request exists for this date range",
 		},
+		{
+			name:       "request on weekend only",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate: future.AddDate(0, 0, -2),
+				EndDate:   future.AddDate(0, 0, -1),
+				Type:      "vacation",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), startOfDay(future.AddDate(0, 0, -2)), endOfDay(future.AddDate(0, 0, -1)), (*uint)(nil)).Return(false, nil)
+			},
+			wantError: true,
+			errorMsg:  "leave request falls entirely on weekends or holidays",
+		},
+		{
+			name:       "insufficient leave balance",
+			employeeID: 1,
+			request: &dtos.CreateLeaveRequestRequest{
+				StartDate: future,
+				EndDate:   futureEnd,
+				Type:      "vacation",
+			},
+			mockSetup: func(leaveRepo *mocks.MockLeaveRequestRepository, empRepo *mocks.MockEmployeeRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				employee := &models.Employee{ID: 1}
+				empRepo.On("FindByID", uint(1)).Return(employee, nil)
+				leaveRepo.On("HasOverlappingApprovedLeave", uint(1), periodStart, periodEnd, (*uint)(nil)).Return(false, nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12, UsedDays: 11}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+			},
+			wantError: true,
+			errorMsg:  "insufficient leave balance for this leave type",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockLeaveRepo := new(mocks.MockLeaveRequestRepository)
 			mockEmpRepo := new(mocks.MockEmployeeRepository)
-			tt.mockSetup(mockLeaveRepo, mockEmpRepo)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+			tt.mockSetup(mockLeaveRepo, mockEmpRepo, mockBalanceRepo)
 
-			service := NewLeaveRequestService(mockLeaveRepo, mockEmpRepo)
+			service := newTestLeaveRequestService(mockLeaveRepo, mockEmpRepo, mockBalanceRepo)
 			result, err := service.CreateLeaveRequest(tt.employeeID, tt.request)
 
 			if tt.wantError {
@@ -163,6 +347,7 @@ func TestCreateLeaveRequest(t *testing.T) {
 
 			mockLeaveRepo.AssertExpectations(t)
 			mockEmpRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -170,17 +355,22 @@ func TestCreateLeaveRequest(t *testing.T) {
 func TestGetLeaveRequestByID(t *testing.T) {
 	now := time.Now()
 	reason := "Vacation"
+	managerID := uint(9)
 
 	tests := []struct {
 		name      string
 		id        uint
+		viewerID  uint
+		userRole  string
 		mockSetup func(*mocks.MockLeaveRequestRepository)
 		wantError bool
 		checkFunc func(*dtos.LeaveRequestResponse)
 	}{
 		{
-			name: "leave request found",
-			id:   1,
+			name:     "leave request found",
+			id:       1,
+			viewerID: 1,
+			userRole: "employee",
 			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
 				employee := &models.Employee{
 					ID:    1,
@@ -210,13 +400,45 @@ func TestGetLeaveRequestByID(t *testing.T) {
 			},
 		},
 		{
-			name: "leave request not found",
-			id:   999,
+			name:     "leave request not found",
+			id:       999,
+			viewerID: 1,
+			userRole: "hr",
 			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
 				repo.On("FindByID", uint(999)).Return(nil, gorm.ErrRecordNotFound)
 			},
 			wantError: true,
 		},
+		{
+			name:     "another employee's leave request is hidden",
+			id:       1,
+			viewerID: 2,
+			userRole: "employee",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.LeaveRequest{ID: 1, EmployeeID: 1, Employee: &models.Employee{ID: 1, ManagerID: &managerID}}, nil)
+			},
+			wantError: true,
+		},
+		{
+			name:     "manager sees their report's leave request",
+			id:       1,
+			viewerID: 9,
+			userRole: "manager",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.LeaveRequest{ID: 1, EmployeeID: 1, Employee: &models.Employee{ID: 1, ManagerID: &managerID}}, nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "manager cannot see other teams' leave requests",
+			id:       1,
+			viewerID: 8,
+			userRole: "manager",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.LeaveRequest{ID: 1, EmployeeID: 1, Employee: &models.Employee{ID: 1, ManagerID: &managerID}}, nil)
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -225,11 +447,11 @@ func TestGetLeaveRequestByID(t *testing.T) {
 			mockEmpRepo := new(mocks.MockEmployeeRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewLeaveRequestService(mockRepo, mockEmpRepo)
-			result, err := service.GetLeaveRequestByID(tt.id)
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, new(mocks.MockLeaveBalanceRepository))
+			result, err := service.GetLeaveRequestByID(tt.id, tt.viewerID, tt.userRole)
 
 			if tt.wantError {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrLeaveRequestNotFound)
 				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
@@ -251,13 +473,17 @@ func TestGetLeaveRequests(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		viewerID  uint
+		userRole  string
 		request   *dtos.GetLeaveRequestsRequest
 		mockSetup func(*mocks.MockLeaveRequestRepository)
 		wantError bool
 		checkFunc func(*dtos.GetLeaveRequestsResponse)
 	}{
 		{
-			name: "get leave requests with default values",
+			name:     "get leave requests with default values",
+			viewerID: 5,
+			userRole: "hr",
 			request: &dtos.GetLeaveRequestsRequest{
 				Page:     0,
 				PageSize: 0,
@@ -267,7 +493,7 @@ func TestGetLeaveRequests(t *testing.T) {
 					{ID: 1, EmployeeID: 1, Type: "vacation", Status: "pending", CreatedAt: now},
 					{ID: 2, EmployeeID: 2, Type: "sick", Status: "approved", CreatedAt: now},
 				}
-				repo.On("FindAll", 1, 10, (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+				repo.On("FindAll", 1, 10, (*uint)(nil), (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
 					Return(leaveRequests, int64(2), nil)
 			},
 			wantError: false,
@@ -279,7 +505,9 @@ func TestGetLeaveRequests(t *testing.T) {
 			},
 		},
 		{
-			name: "filter by employee and status",
+			name:     "filter by employee and status",
+			viewerID: 5,
+			userRole: "hr",
 			request: &dtos.GetLeaveRequestsRequest{
 				Page:       1,
 				PageSize:   10,
@@ -292,7 +520,7 @@ func TestGetLeaveRequests(t *testing.T) {
 				leaveRequests := []models.LeaveRequest{
 					{ID: 1, EmployeeID: 1, Type: "vacation", Status: "pending", CreatedAt: now},
 				}
-				repo.On("FindAll", 1, 10, &empID, &status, (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "start_date", "asc").
+				repo.On("FindAll", 1, 10, &empID, (*uint)(nil), &status, (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "start_date", "asc").
 					Return(leaveRequests, int64(1), nil)
 			},
 			wantError: false,
@@ -302,13 +530,15 @@ func TestGetLeaveRequests(t *testing.T) {
 			},
 		},
 		{
-			name: "enforce max page size",
+			name:     "enforce max page size",
+			viewerID: 5,
+			userRole: "HR",
 			request: &dtos.GetLeaveRequestsRequest{
 				Page:     1,
 				PageSize: 150,
 			},
 			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
-				repo.On("FindAll", 1, 100, (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+				repo.On("FindAll", 1, 100, (*uint)(nil), (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
 					Return([]models.LeaveRequest{}, int64(0), nil)
 			},
 			wantError: false,
@@ -316,6 +546,32 @@ func TestGetLeaveRequests(t *testing.T) {
 				assert.Equal(t, 100, resp.Pagination.PageSize)
 			},
 		},
+		{
+			name:     "employees only see their own leave requests",
+			viewerID: 5,
+			userRole: "employee",
+			request: &dtos.GetLeaveRequestsRequest{
+				EmployeeID: &empID,
+			},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				viewerID := uint(5)
+				repo.On("FindAll", 1, 10, &viewerID, (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+					Return([]models.LeaveRequest{}, int64(0), nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "managers are scoped to their team",
+			viewerID: 9,
+			userRole: "manager",
+			request:  &dtos.GetLeaveRequestsRequest{},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				managerID := uint(9)
+				repo.On("FindAll", 1, 10, (*uint)(nil), &managerID, (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+					Return([]models.LeaveRequest{}, int64(0), nil)
+			},
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -324,8 +580,8 @@ func TestGetLeaveRequests(t *testing.T) {
 			mockEmpRepo := new(mocks.MockEmployeeRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewLeaveRequestService(mockRepo, mockEmpRepo)
-			result, err := service.GetLeaveRequests(tt.request)
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, new(mocks.MockLeaveBalanceRepository))
+			result, err := service.GetLeaveRequests(tt.viewerID, tt.userRole, tt.request)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -343,12 +599,62 @@ func TestGetLeaveRequests(t *testing.T) {
 	}
 }
 
+func TestGetLeaveRequestsForAPIKey(t *testing.T) {
+	mockRepo := new(mocks.MockLeaveRequestRepository)
+	mockRepo.On("FindAll", 1, 10, (*uint)(nil), (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+		Return([]models.LeaveRequest{{ID: 1, EmployeeID: 1}, {ID: 2, EmployeeID: 2}}, int64(2), nil)
+	mockRepo.On("FindByID", uint(2)).Return(&models.LeaveRequest{ID: 2, EmployeeID: 2}, nil)
+	mockRepo.On("FindByID", uint(3)).Return(nil, gorm.ErrRecordNotFound)
+
+	service := newTestLeaveRequestService(mockRepo, new(mocks.MockEmployeeRepository), new(mocks.MockLeaveBalanceRepository))
+	payroll := &APIKeyIdentity{ID: 4, Name: "Payroll export", Permissions: Permissions{PermissionLeaveReadAll: true}}
+	reporting := &APIKeyIdentity{ID: 5, Name: "Headcount report", Permissions: Permissions{PermissionEmployeeRead: true}}
+
+	// Keys read every leave request, not those of an employee
+	result, err := service.GetLeaveRequestsForAPIKey(payroll, &dtos.GetLeaveRequestsRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, result.Data, 2)
+
+	leaveRequest, err := service.GetLeaveRequestByIDForAPIKey(2, payroll)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), leaveRequest.ID)
+
+	_, err = service.GetLeaveRequestByIDForAPIKey(3, payroll)
+	assert.ErrorIs(t, err, ErrLeaveRequestNotFound)
+
+	_, err = service.GetLeaveRequestsForAPIKey(reporting, &dtos.GetLeaveRequestsRequest{})
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	_, err = service.GetLeaveRequestByIDForAPIKey(2, reporting)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOwnLeaveRequests(t *testing.T) {
+	otherEmployeeID := uint(2)
+	employeeID := uint(1)
+
+	mockRepo := new(mocks.MockLeaveRequestRepository)
+	// The employee filter of the request is overridden, and no team filter
+	// applies even though the employee may be a manager
+	mockRepo.On("FindAll", 1, 10, &employeeID, (*uint)(nil), (*string)(nil), (*string)(nil), (*time.Time)(nil), (*time.Time)(nil), "created_at", "desc").
+		Return([]models.LeaveRequest{{ID: 1, EmployeeID: 1}}, int64(1), nil)
+
+	service := newTestLeaveRequestService(mockRepo, new(mocks.MockEmployeeRepository), new(mocks.MockLeaveBalanceRepository))
+	result, err := service.GetOwnLeaveRequests(1, &dtos.GetLeaveRequestsRequest{EmployeeID: &otherEmployeeID})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, int64(1), result.Pagination.TotalItems)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUpdateLeaveRequest(t *testing.T) {
 	now := time.Now()
 	future := now.Add(48 * time.Hour)
 	futureEnd := now.Add(72 * time.Hour)
-	newStatus := "approved"
 	newType := "sick"
+	managerID := uint(5)
 
 	tests := []struct {
 		name       string
@@ -356,7 +662,7 @@ func TestUpdateLeaveRequest(t *testing.T) {
 		employeeID uint
 		userRole   string
 		request    *dtos.UpdateLeaveRequestRequest
-		mockSetup  func(*mocks.MockLeaveRequestRepository)
+		mockSetup  func(*mocks.MockLeaveRequestRepository, *mocks.MockLeaveBalanceRepository)
 		wantError  bool
 		errorMsg   string
 	}{
@@ -368,7 +674,7 @@ func TestUpdateLeaveRequest(t *testing.T) {
 			request: &dtos.UpdateLeaveRequestRequest{
 				Type: &newType,
 			},
-			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				leaveRequest := &models.LeaveRequest{
 					ID:         1,
 					EmployeeID: 1,
@@ -392,40 +698,59 @@ func TestUpdateLeaveRequest(t *testing.T) {
 			wantError: false,
 		},
 		{
-			name:       "hr updates status",
+			name:       "unauthorized update",
 			id:         1,
 			employeeID: 2,
-			userRole:   "hr",
+			userRole:   "employee",
 			request: &dtos.UpdateLeaveRequestRequest{
-				Status: &newStatus,
+				Type: &newType,
 			},
-			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				leaveRequest := &models.LeaveRequest{
 					ID:         1,
 					EmployeeID: 1,
-					StartDate:  future,
-					EndDate:    futureEnd,
 					Type:       "vacation",
 					Status:     "pending",
 				}
-				repo.On("FindByID", uint(1)).Return(leaveRequest, nil).Once()
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "unauthorized to update this leave request",
+		},
+		{
+			name:       "manager updates a direct report's request",
+			id:         1,
+			employeeID: 5,
+			userRole:   "manager",
+			request: &dtos.UpdateLeaveRequestRequest{
+				Type: &newType,
+			},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Employee:   &models.Employee{ID: 1, ManagerID: &managerID},
+					Type:       "vacation",
+					Status:     "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
 				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
-				repo.On("FindByID", uint(1)).Return(leaveRequest, nil).Once()
 			},
 			wantError: false,
 		},
 		{
-			name:       "unauthorized update",
+			name:       "manager cannot update other teams' requests",
 			id:         1,
-			employeeID: 2,
-			userRole:   "employee",
+			employeeID: 6,
+			userRole:   "manager",
 			request: &dtos.UpdateLeaveRequestRequest{
 				Type: &newType,
 			},
-			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				leaveRequest := &models.LeaveRequest{
 					ID:         1,
 					EmployeeID: 1,
+					Employee:   &models.Employee{ID: 1, ManagerID: &managerID},
 					Type:       "vacation",
 					Status:     "pending",
 				}
@@ -435,24 +760,24 @@ func TestUpdateLeaveRequest(t *testing.T) {
 			errorMsg:  "unauthorized to update this leave request",
 		},
 		{
-			name:       "employee tries to update status",
+			name:       "approved request cannot be updated",
 			id:         1,
 			employeeID: 1,
 			userRole:   "employee",
 			request: &dtos.UpdateLeaveRequestRequest{
-				Status: &newStatus,
+				Type: &newType,
 			},
-			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				leaveRequest := &models.LeaveRequest{
 					ID:         1,
 					EmployeeID: 1,
 					Type:       "vacation",
-					Status:     "pending",
+					Status:     "approved",
 				}
 				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
 			},
 			wantError: true,
-			errorMsg:  "only HR or manager can update leave request status",
+			errorMsg:  "only draft or pending leave requests can be updated",
 		},
 		{
 			name:       "update to past dates",
@@ -462,7 +787,7 @@ func TestUpdateLeaveRequest(t *testing.T) {
 			request: &dtos.UpdateLeaveRequestRequest{
 				StartDate: &now,
 			},
-			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
 				leaveRequest := &models.LeaveRequest{
 					ID:         1,
 					EmployeeID: 1,
@@ -482,9 +807,10 @@ func TestUpdateLeaveRequest(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := new(mocks.MockLeaveRequestRepository)
 			mockEmpRepo := new(mocks.MockEmployeeRepository)
-			tt.mockSetup(mockRepo)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+			tt.mockSetup(mockRepo, mockBalanceRepo)
 
-			service := NewLeaveRequestService(mockRepo, mockEmpRepo)
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
 			result, err := service.UpdateLeaveRequest(tt.id, tt.employeeID, tt.userRole, tt.request)
 
 			if tt.wantError {
@@ -499,11 +825,14 @@ func TestUpdateLeaveRequest(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
 		})
 	}
 }
 
 func TestDeleteLeaveRequest(t *testing.T) {
+	managerID := uint(5)
+
 	tests := []struct {
 		name       string
 		id         uint
@@ -564,6 +893,41 @@ func TestDeleteLeaveRequest(t *testing.T) {
 			wantError: true,
 			errorMsg:  "unauthorized to delete this leave request",
 		},
+		{
+			name:       "manager cannot delete other teams' requests",
+			id:         1,
+			employeeID: 6,
+			userRole:   "manager",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Employee:   &models.Employee{ID: 1, ManagerID: &managerID},
+					Type:       "vacation",
+					Status:     "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "unauthorized to delete this leave request",
+		},
+		{
+			name:       "approved leave cannot be deleted",
+			id:         1,
+			employeeID: 1,
+			userRole:   "employee",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Type:       "vacation",
+					Status:     "approved",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "only draft or pending leave requests can be deleted",
+		},
 		{
 			name:       "leave request not found",
 			id:         999,
@@ -600,7 +964,7 @@ func TestDeleteLeaveRequest(t *testing.T) {
 			mockEmpRepo := new(mocks.MockEmployeeRepository)
 			tt.mockSetup(mockRepo)
 
-			service := NewLeaveRequestService(mockRepo, mockEmpRepo)
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, new(mocks.MockLeaveBalanceRepository))
 			err := service.DeleteLeaveRequest(tt.id, tt.employeeID, tt.userRole)
 
 			if tt.wantError {
@@ -616,3 +980,805 @@ func TestDeleteLeaveRequest(t *testing.T) {
 		})
 	}
 }
+
+// TestDeleteLeaveRequestKeepsDebit checks that leave which holds a balance
+// debit cannot be deleted, so the ledger is only credited through a
+// confirmed cancellation.
+func TestDeleteLeaveRequestKeepsDebit(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour)
+
+	for _, status := range []string{"approved", "cancellation_requested"} {
+		t.Run(status, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+			leaveRequest := &models.LeaveRequest{
+				ID:          1,
+				EmployeeID:  1,
+				StartDate:   future,
+				EndDate:     future,
+				WorkingDays: 2,
+				Type:        "vacation",
+				Status:      status,
+			}
+			mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12, UsedDays: 2}
+			mockBalanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil).Maybe()
+			mockBalanceRepo.On("OutstandingDebit", uint(1), uint(1)).Return(float64(2), nil).Maybe()
+			mockBalanceRepo.On("RecordTransaction", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			service := newTestLeaveRequestService(mockRepo, new(mocks.MockEmployeeRepository), mockBalanceRepo)
+			err := service.DeleteLeaveRequest(1, 2, "hr")
+
+			assert.ErrorIs(t, err, ErrLeaveRequestNotEditable)
+			mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+			mockBalanceRepo.AssertNotCalled(t, "RecordTransaction", mock.Anything, mock.Anything)
+			assert.Equal(t, float64(2), balance.UsedDays)
+		})
+	}
+}
+
+func TestApproveLeaveRequest(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour)
+	futureEnd := future.Add(24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		id        uint
+		userRole  string
+		mockSetup func(*mocks.MockLeaveRequestRepository, *mocks.MockLeaveBalanceRepository)
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:     "approval debits the balance",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:          1,
+					EmployeeID:  1,
+					StartDate:   future,
+					EndDate:     futureEnd,
+					WorkingDays: 2,
+					Type:        "vacation",
+					Status:      "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, mock.Anything).Return(false, nil)
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "approved" && *lr.DecidedBy == 9 && lr.DecidedAt != nil && lr.DecisionComment == nil
+				})).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+				balanceRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+					return tx.Kind == "debit" && tx.Days == 2 && *tx.LeaveRequestID == 1
+				})).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "working days of older requests are computed on approval",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				// Monday to Wednesday is three working days
+				monday := startOfDay(time.Now().AddDate(0, 0, 7))
+				for monday.Weekday() != time.Monday {
+					monday = monday.AddDate(0, 0, 1)
+				}
+				workingDays := 3.0
+				leaveRequest := &models.LeaveRequest{
+					ID:          1,
+					EmployeeID:  1,
+					StartDate:   monday,
+					EndDate:     endOfDay(monday.AddDate(0, 0, 2)),
+					Granularity: "full_day",
+					Type:        "vacation",
+					Status:      "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("HasOverlappingApprovedLeave", uint(1), leaveRequest.StartDate, leaveRequest.EndDate, mock.Anything).Return(false, nil)
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.WorkingDays == workingDays
+				})).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: monday.Year(), EntitledDays: 12}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", monday.Year()).Return(balance, nil)
+				balanceRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+					return tx.Kind == "debit" && tx.Days == workingDays
+				})).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "insufficient balance at approval",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:          1,
+					EmployeeID:  1,
+					StartDate:   future,
+					EndDate:     futureEnd,
+					WorkingDays: 2,
+					Type:        "vacation",
+					Status:      "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, mock.Anything).Return(false, nil)
+				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12, UsedDays: 11}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+			},
+			wantError: true,
+			errorMsg:  "insufficient leave balance for this leave type",
+		},
+		{
+			name:     "declining a cancellation keeps the debit",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:          1,
+					EmployeeID:  1,
+					StartDate:   future,
+					EndDate:     futureEnd,
+					WorkingDays: 2,
+					Type:        "vacation",
+					Status:      "cancellation_requested",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "approved"
+				})).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:     "rejected request cannot be approved",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Type:       "vacation",
+					Status:     "rejected",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "invalid leave request status transition from rejected to approved",
+		},
+		{
+			name:     "line manager approves",
+			id:       1,
+			userRole: "manager",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				managerID := uint(9)
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Employee:   &models.Employee{ID: 1, ManagerID: &managerID},
+					StartDate:  future,
+					EndDate:    futureEnd,
+					Type:       "other",
+					Status:     "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, mock.Anything).Return(false, nil)
+				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "other", future.Year()).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: false,
+		},
+		{
+			name:     "another manager cannot approve",
+			id:       1,
+			userRole: "manager",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				managerID := uint(5)
+				leaveRequest := &models.LeaveRequest{
+					ID:         1,
+					EmployeeID: 1,
+					Employee:   &models.Employee{ID: 1, ManagerID: &managerID},
+					Type:       "vacation",
+					Status:     "pending",
+				}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "only the employee's manager or HR can approve leave requests",
+		},
+		{
+			name:     "hr cannot approve their own leave",
+			id:       1,
+			userRole: "hr",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 9, Type: "vacation", Status: "pending"}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "only the employee's manager or HR can approve leave requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+			tt.mockSetup(mockRepo, mockBalanceRepo)
+
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
+			result, err := service.ApproveLeaveRequest(tt.id, 9, tt.userRole, &dtos.DecideLeaveRequestRequest{})
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				if tt.errorMsg != "" {
+					assert.Equal(t, tt.errorMsg, err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRejectLeaveRequest(t *testing.T) {
+	comment := "  Team is short-staffed that week  "
+	blank := "   "
+
+	tests := []struct {
+		name      string
+		userRole  string
+		request   *dtos.DecideLeaveRequestRequest
+		mockSetup func(*mocks.MockLeaveRequestRepository)
+		wantError bool
+		errorMsg  string
+	}{
+		{
+			name:     "rejection records the decision",
+			userRole: "hr",
+			request:  &dtos.DecideLeaveRequestRequest{Comment: &comment},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Type: "vacation", Status: "pending"}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "rejected" &&
+						*lr.DecidedBy == 9 &&
+						lr.DecidedAt != nil &&
+						*lr.DecisionComment == "Team is short-staffed that week"
+				})).Return(nil)
+			},
+			wantError: false,
+		},
+		{
+			name:      "comment is required",
+			userRole:  "hr",
+			request:   &dtos.DecideLeaveRequestRequest{},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {},
+			wantError: true,
+			errorMsg:  "rejection reason is required",
+		},
+		{
+			name:      "blank comment is rejected",
+			userRole:  "hr",
+			request:   &dtos.DecideLeaveRequestRequest{Comment: &blank},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {},
+			wantError: true,
+			errorMsg:  "rejection reason is required",
+		},
+		{
+			name:     "only the manager or hr can reject",
+			userRole: "employee",
+			request:  &dtos.DecideLeaveRequestRequest{Comment: &comment},
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository) {
+				leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Type: "vacation", Status: "pending"}
+				repo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			},
+			wantError: true,
+			errorMsg:  "only the employee's manager or HR can reject leave requests",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+			tt.mockSetup(mockRepo)
+
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
+			result, err := service.RejectLeaveRequest(1, 9, tt.userRole, tt.request)
+
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.errorMsg, err.Error())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "Team is short-staffed that week", *result.DecisionComment)
+				assert.Equal(t, uint(9), *result.DecidedBy)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCancelLeaveRequest(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		employeeID uint
+		userRole   string
+		status     string
+		mockSetup  func(*mocks.MockLeaveRequestRepository, *mocks.MockLeaveBalanceRepository)
+		wantStatus string
+		wantError  error
+		errorMsg   string
+	}{
+		{
+			name:       "owner requests cancellation of approved leave",
+			employeeID: 1,
+			userRole:   "employee",
+			status:     "approved",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+			},
+			wantStatus: "cancellation_requested",
+		},
+		{
+			name:       "hr confirms cancellation and credits the balance",
+			employeeID: 2,
+			userRole:   "hr",
+			status:     "cancellation_requested",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12, UsedDays: 2}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+				balanceRepo.On("OutstandingDebit", uint(1), uint(1)).Return(float64(2), nil)
+				balanceRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+					return tx.Kind == "credit" && tx.Days == 2
+				})).Return(nil)
+			},
+			wantStatus: "cancelled",
+		},
+		{
+			name:       "only the debited days are credited",
+			employeeID: 2,
+			userRole:   "hr",
+			status:     "cancellation_requested",
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				repo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12, UsedDays: 1.5}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+				balanceRepo.On("OutstandingDebit", uint(1), uint(1)).Return(1.5, nil)
+				balanceRepo.On("RecordTransaction", balance, mock.MatchedBy(func(tx *models.LeaveBalanceTransaction) bool {
+					return tx.Kind == "credit" && tx.Days == 1.5
+				})).Return(nil)
+			},
+			wantStatus: "cancelled",
+		},
+		{
+			name:       "other employee cannot cancel",
+			employeeID: 2,
+			userRole:   "employee",
+			status:     "approved",
+			mockSetup:  func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {},
+			errorMsg:   "unauthorized to cancel this leave request",
+		},
+		{
+			name:       "pending leave is withdrawn rather than cancelled",
+			employeeID: 1,
+			userRole:   "employee",
+			status:     "pending",
+			mockSetup:  func(repo *mocks.MockLeaveRequestRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {},
+			wantError:  ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+			leaveRequest := &models.LeaveRequest{
+				ID:          1,
+				EmployeeID:  1,
+				StartDate:   future,
+				EndDate:     future,
+				WorkingDays: 2,
+				Type:        "vacation",
+				Status:      tt.status,
+			}
+			mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			tt.mockSetup(mockRepo, mockBalanceRepo)
+
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
+			result, err := service.CancelLeaveRequest(1, tt.employeeID, tt.userRole)
+
+			switch {
+			case tt.wantError != nil:
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			case tt.errorMsg != "":
+				assert.EqualError(t, err, tt.errorMsg)
+				assert.Nil(t, result)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, result.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWithdrawLeaveRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		employeeID uint
+		status     string
+		wantError  error
+		errorMsg   string
+	}{
+		{
+			name:       "owner withdraws pending request",
+			employeeID: 1,
+			status:     "pending",
+		},
+		{
+			name:       "owner withdraws draft",
+			employeeID: 1,
+			status:     "draft",
+		},
+		{
+			name:       "approved request cannot be withdrawn",
+			employeeID: 1,
+			status:     "approved",
+			wantError:  ErrInvalidStatusTransition,
+		},
+		{
+			name:       "only the requester can withdraw",
+			employeeID: 2,
+			status:     "pending",
+			errorMsg:   "only the requester can withdraw this leave request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+			leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Type: "vacation", Status: tt.status}
+			mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			if tt.wantError == nil && tt.errorMsg == "" {
+				mockRepo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "withdrawn"
+				})).Return(nil)
+			}
+
+			service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
+			result, err := service.WithdrawLeaveRequest(1, tt.employeeID)
+
+			switch {
+			case tt.wantError != nil:
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			case tt.errorMsg != "":
+				assert.EqualError(t, err, tt.errorMsg)
+				assert.Nil(t, result)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, "withdrawn", result.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmitLeaveRequest(t *testing.T) {
+	mockRepo := new(mocks.MockLeaveRequestRepository)
+	mockEmpRepo := new(mocks.MockEmployeeRepository)
+	mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+	leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Type: "vacation", Status: "draft"}
+	mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+	mockRepo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+		return lr.Status == "pending"
+	})).Return(nil)
+
+	service := newTestLeaveRequestService(mockRepo, mockEmpRepo, mockBalanceRepo)
+	result, err := service.SubmitLeaveRequest(1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+
+	_, err = service.SubmitLeaveRequest(1, 1)
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLeaveRequestEventsAreRecorded(t *testing.T) {
+	mockRepo := new(mocks.MockLeaveRequestRepository)
+	mockEventRepo := new(mocks.MockLeaveRequestEventRepository)
+	mockEmpRepo := new(mocks.MockEmployeeRepository)
+	mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+	cfg := setupTestConfig()
+	holidayRepo := new(mocks.MockHolidayRepository)
+	calendar, _ := NewWorkingCalendar(holidayRepo, cfg)
+	approvalPolicy, _ := NewApprovalPolicy(cfg)
+	service := NewLeaveRequestService(mockRepo, mockEventRepo, newTestApprovalRepository(), mockEmpRepo, NewLeaveBalanceService(mockBalanceRepo, mockEmpRepo, newTestPermissionChecker(), cfg), calendar, approvalPolicy, newTestPermissionChecker(), &mocks.MockTransactor{})
+
+	leaveRequest := &models.LeaveRequest{ID: 1, EmployeeID: 1, Type: "personal", Status: "pending"}
+	mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.LeaveRequest")).Return(nil)
+	mockEventRepo.On("Create", mock.MatchedBy(func(event *models.LeaveRequestEvent) bool {
+		var changes map[string]map[string]any
+		if err := json.Unmarshal([]byte(event.Changes), &changes); err != nil {
+			return false
+		}
+		return event.LeaveRequestID == 1 &&
+			event.ActorID == 1 &&
+			event.Action == "withdrawn" &&
+			len(changes) == 1 &&
+			changes["status"]["from"] == "pending" &&
+			changes["status"]["to"] == "withdrawn"
+	})).Return(nil)
+
+	_, err := service.WithdrawLeaveRequest(1, 1)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockEventRepo.AssertExpectations(t)
+}
+
+func TestGetLeaveRequestHistory(t *testing.T) {
+	mockRepo := new(mocks.MockLeaveRequestRepository)
+	mockEventRepo := new(mocks.MockLeaveRequestEventRepository)
+	service := NewLeaveRequestService(mockRepo, mockEventRepo, nil, nil, nil, nil, nil, newTestPermissionChecker(), nil)
+
+	mockEventRepo.On("FindByLeaveRequestID", uint(1)).Return([]models.LeaveRequestEvent{
+		{ID: 1, LeaveRequestID: 1, ActorID: 1, Action: "created", Changes: `{"status":{"from":null,"to":"pending"}}`},
+		{ID: 2, LeaveRequestID: 1, ActorID: 2, Action: "approved", Changes: `{"status":{"from":"pending","to":"approved"}}`},
+	}, nil)
+	mockEventRepo.On("FindByLeaveRequestID", uint(2)).Return([]models.LeaveRequestEvent{}, nil)
+
+	history, err := service.GetLeaveRequestHistory(1, 5, "hr")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "approved", history[1].Action)
+	assert.JSONEq(t, `{"status":{"from":"pending","to":"approved"}}`, string(history[1].Changes))
+
+	_, err = service.GetLeaveRequestHistory(2, 5, "hr")
+	assert.EqualError(t, err, "leave request not found")
+
+	// Outside HR, the history is only shown to those who can see the request
+	mockRepo.On("FindByID", uint(1)).Return(&models.LeaveRequest{ID: 1, EmployeeID: 1}, nil)
+	history, err = service.GetLeaveRequestHistory(1, 1, "employee")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	_, err = service.GetLeaveRequestHistory(1, 2, "employee")
+	assert.ErrorIs(t, err, ErrLeaveRequestNotFound)
+}
+
+func TestDiffLeaveRequests(t *testing.T) {
+	reason := "Family event"
+	before := &models.LeaveRequest{
+		StartDate:   time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2026, 12, 21, 23, 59, 59, 0, time.UTC),
+		Granularity: "full_day",
+		WorkingDays: 1,
+		Type:        "vacation",
+		Status:      "pending",
+	}
+	after := *before
+	after.Type = "personal"
+	after.Reason = &reason
+
+	changes, err := diffLeaveRequests(before, &after)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"reason":{"from":null,"to":"Family event"},"type":{"from":"vacation","to":"personal"}}`, changes)
+
+	changes, err = diffLeaveRequests(before, before)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", changes)
+
+	changes, err = diffLeaveRequests(before, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, changes, `"status":{"from":"pending","to":null}`)
+}
+
+func TestGetPendingApprovals(t *testing.T) {
+	tests := []struct {
+		name             string
+		userRole         string
+		approverRole     string
+		includeUnmanaged bool
+	}{
+		{name: "manager sees direct reports", userRole: "manager", approverRole: "manager"},
+		{name: "role is matched case-insensitively", userRole: "HR", approverRole: "hr", includeUnmanaged: true},
+		{name: "custom role sees its approval steps", userRole: "team_lead", approverRole: "team_lead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockRepo.On("FindPendingApproval", uint(9), tt.approverRole, tt.includeUnmanaged, 1, 10).Return([]models.LeaveRequest{
+				{ID: 1, EmployeeID: 1, Type: "vacation", Status: "pending"},
+				{ID: 2, EmployeeID: 2, Type: "sick", Status: "cancellation_requested"},
+			}, int64(2), nil)
+
+			service := newTestLeaveRequestService(mockRepo, new(mocks.MockEmployeeRepository), new(mocks.MockLeaveBalanceRepository))
+			result, err := service.GetPendingApprovals(9, tt.userRole, &dtos.GetPendingApprovalsRequest{})
+
+			assert.NoError(t, err)
+			assert.Len(t, result.Data, 2)
+			assert.Equal(t, int64(2), result.Pagination.TotalItems)
+			assert.Equal(t, 1, result.Pagination.TotalPages)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestApprovalChain(t *testing.T) {
+	future := nextMonday(time.Now())
+	futureEnd := future.AddDate(0, 0, 4)
+	managerID := uint(9)
+	comment := "Enjoy"
+
+	newLeaveRequest := func() *models.LeaveRequest {
+		return &models.LeaveRequest{
+			ID:          1,
+			EmployeeID:  1,
+			Employee:    &models.Employee{ID: 1, ManagerID: &managerID},
+			StartDate:   future,
+			EndDate:     futureEnd,
+			WorkingDays: 5,
+			Type:        "vacation",
+			Status:      "pending",
+		}
+	}
+	newSteps := func(firstStatus string) []models.LeaveRequestApproval {
+		return []models.LeaveRequestApproval{
+			{ID: 1, LeaveRequestID: 1, Step: 1, Approver: "line_manager", Status: firstStatus},
+			{ID: 2, LeaveRequestID: 1, Step: 2, Approver: "hr", Status: "pending"},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		approverID uint
+		userRole   string
+		reject     bool
+		steps      []models.LeaveRequestApproval
+		mockSetup  func(*mocks.MockLeaveRequestRepository, *mocks.MockLeaveRequestApprovalRepository, *mocks.MockLeaveRequestEventRepository, *mocks.MockLeaveBalanceRepository)
+		wantStatus string
+		errorMsg   string
+	}{
+		{
+			name:       "line manager signs off the first step",
+			approverID: 9,
+			userRole:   "manager",
+			steps:      newSteps("pending"),
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, approvalRepo *mocks.MockLeaveRequestApprovalRepository, eventRepo *mocks.MockLeaveRequestEventRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				approvalRepo.On("Update", mock.MatchedBy(func(step *models.LeaveRequestApproval) bool {
+					return step.Step == 1 && step.Status == "approved" && *step.DecidedBy == 9 && *step.Comment == "Enjoy"
+				})).Return(nil)
+				eventRepo.On("Create", mock.MatchedBy(func(event *models.LeaveRequestEvent) bool {
+					return event.Action == "step_approved" && event.Changes == `{"approval_step_1":{"from":"pending","to":"approved"}}`
+				})).Return(nil)
+			},
+			wantStatus: "pending",
+		},
+		{
+			name:       "line manager cannot sign off the HR step",
+			approverID: 9,
+			userRole:   "manager",
+			steps:      newSteps("approved"),
+			errorMsg:   "not authorized to decide the current approval step",
+		},
+		{
+			name:       "HR signs off the last step",
+			approverID: 7,
+			userRole:   "hr",
+			steps:      newSteps("approved"),
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, approvalRepo *mocks.MockLeaveRequestApprovalRepository, eventRepo *mocks.MockLeaveRequestEventRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				repo.On("HasOverlappingApprovedLeave", uint(1), future, futureEnd, mock.Anything).Return(false, nil)
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "approved" && *lr.DecidedBy == 7
+				})).Return(nil)
+				approvalRepo.On("Update", mock.MatchedBy(func(step *models.LeaveRequestApproval) bool {
+					return step.Step == 2 && step.Status == "approved" && *step.DecidedBy == 7
+				})).Return(nil)
+				balance := &models.LeaveBalance{ID: 1, EmployeeID: 1, Type: "vacation", Year: future.Year(), EntitledDays: 12}
+				balanceRepo.On("FindByEmployeeTypeAndYear", uint(1), "vacation", future.Year()).Return(balance, nil)
+				balanceRepo.On("RecordTransaction", balance, mock.AnythingOfType("*models.LeaveBalanceTransaction")).Return(nil)
+				eventRepo.On("Create", mock.MatchedBy(func(event *models.LeaveRequestEvent) bool {
+					return event.Action == "approved"
+				})).Return(nil)
+			},
+			wantStatus: "approved",
+		},
+		{
+			name:       "HR rejects at the last step",
+			approverID: 7,
+			userRole:   "hr",
+			reject:     true,
+			steps:      newSteps("approved"),
+			mockSetup: func(repo *mocks.MockLeaveRequestRepository, approvalRepo *mocks.MockLeaveRequestApprovalRepository, eventRepo *mocks.MockLeaveRequestEventRepository, balanceRepo *mocks.MockLeaveBalanceRepository) {
+				repo.On("Update", mock.MatchedBy(func(lr *models.LeaveRequest) bool {
+					return lr.Status == "rejected"
+				})).Return(nil)
+				approvalRepo.On("Update", mock.MatchedBy(func(step *models.LeaveRequestApproval) bool {
+					return step.Step == 2 && step.Status == "rejected" && *step.Comment == "Enjoy"
+				})).Return(nil)
+				eventRepo.On("Create", mock.MatchedBy(func(event *models.LeaveRequestEvent) bool {
+					return event.Action == "rejected"
+				})).Return(nil)
+			},
+			wantStatus: "rejected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockLeaveRequestRepository)
+			mockApprovalRepo := new(mocks.MockLeaveRequestApprovalRepository)
+			mockEventRepo := new(mocks.MockLeaveRequestEventRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockBalanceRepo := new(mocks.MockLeaveBalanceRepository)
+
+			leaveRequest := newLeaveRequest()
+			mockRepo.On("FindByID", uint(1)).Return(leaveRequest, nil)
+			mockApprovalRepo.On("FindByLeaveRequestID", uint(1)).Return(tt.steps, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockRepo, mockApprovalRepo, mockEventRepo, mockBalanceRepo)
+			}
+
+			cfg := setupApprovalChainConfig()
+			holidayRepo := new(mocks.MockHolidayRepository)
+			holidayRepo.On("FindBetween", mock.Anything, mock.Anything).Return([]models.Holiday{}, nil).Maybe()
+			calendar, _ := NewWorkingCalendar(holidayRepo, cfg)
+			approvalPolicy, err := NewApprovalPolicy(cfg)
+			assert.NoError(t, err)
+			service := NewLeaveRequestService(mockRepo, mockEventRepo, mockApprovalRepo, mockEmpRepo, NewLeaveBalanceService(mockBalanceRepo, mockEmpRepo, newTestPermissionChecker(), cfg), calendar, approvalPolicy, newTestPermissionChecker(), &mocks.MockTransactor{})
+
+			req := &dtos.DecideLeaveRequestRequest{Comment: &comment}
+			var result *dtos.LeaveRequestResponse
+			if tt.reject {
+				result, err = service.RejectLeaveRequest(1, tt.approverID, tt.userRole, req)
+			} else {
+				result, err = service.ApproveLeaveRequest(1, tt.approverID, tt.userRole, req)
+			}
+
+			if tt.errorMsg != "" {
+				assert.EqualError(t, err, tt.errorMsg)
+				mockApprovalRepo.AssertNotCalled(t, "Update", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, result.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockApprovalRepo.AssertExpectations(t)
+			mockEventRepo.AssertExpectations(t)
+			mockBalanceRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/models"
+	"slices"
+)
+
+// ErrInvalidStatusTransition is returned when an action is not allowed from
+// the current status of a leave request.
+var ErrInvalidStatusTransition = apperrors.Conflict("invalid_status_transition", "invalid leave request status transition")
+
+// leaveRequestTransitions lists the statuses each status may move to.
+// Rejected, cancelled and withdrawn requests are final. A cancellation
+// request goes back to approved when HR declines it. Pending requests are
+// cancelled when their employee is deactivated.
+var leaveRequestTransitions = map[string][]string{
+	"draft":                  {"pending", "withdrawn"},
+	"pending":                {"approved", "rejected", "withdrawn", "cancelled"},
+	"approved":               {"cancellation_requested", "cancelled"},
+	"cancellation_requested": {"approved", "cancelled"},
+}
+
+// transitionLeaveRequest moves the leave request to the given status if the
+// state machine allows it.
+func transitionLeaveRequest(leaveRequest *models.LeaveRequest, to string) error {
+	if !slices.Contains(leaveRequestTransitions[leaveRequest.Status], to) {
+		return ErrInvalidStatusTransition.Withf("%s from %s to %s", ErrInvalidStatusTransition.Message, leaveRequest.Status, to)
+	}
+	leaveRequest.Status = to
+	return nil
+}
+
+// holdsBalance reports whether a leave request in the given status has its
+// days debited from the balance. A request awaiting cancellation keeps them
+// until the cancellation is confirmed.
+func holdsBalance(status string) bool {
+	return status == "approved" || status == "cancellation_requested"
+}
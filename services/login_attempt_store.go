@@ -0,0 +1,109 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginAttemptStore keeps the failed login counters and lockouts of the login
+// throttle. The in-memory store suits a single instance; deployments running
+// several instances need a shared store, such as Redis, behind this interface.
+type LoginAttemptStore interface {
+	// AddFailure records a failed login for key and returns how many failures
+	// it has had within the window.
+	AddFailure(key string, at time.Time, window time.Duration) (int, error)
+	// Lock blocks key until the given time and clears its failures.
+	Lock(key string, until time.Time) error
+	// LockedUntil returns when the lock on key ends, or the zero time when
+	// key is not locked at the given time.
+	LockedUntil(key string, at time.Time) (time.Time, error)
+	// Reset forgets the failures and lock of key.
+	Reset(key string) error
+}
+
+type loginAttempts struct {
+	failures    int
+	windowStart time.Time
+	windowEnd   time.Time
+	lockedUntil time.Time
+}
+
+type memoryLoginAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string]*loginAttempts
+	lastSweep time.Time
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]*loginAttempts)}
+}
+
+func (s *memoryLoginAttemptStore) AddFailure(key string, at time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(at, window)
+
+	entry, ok := s.attempts[key]
+	if !ok {
+		entry = &loginAttempts{}
+		s.attempts[key] = entry
+	}
+	if !at.Before(entry.windowEnd) {
+		entry.failures = 0
+		entry.windowStart = at
+		entry.windowEnd = at.Add(window)
+	}
+	entry.failures++
+
+	return entry.failures, nil
+}
+
+func (s *memoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.attempts[key]
+	if !ok {
+		entry = &loginAttempts{}
+		s.attempts[key] = entry
+	}
+	entry.failures = 0
+	entry.windowEnd = time.Time{}
+	entry.lockedUntil = until
+
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) LockedUntil(key string, at time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.attempts[key]
+	if !ok || !at.Before(entry.lockedUntil) {
+		return time.Time{}, nil
+	}
+	return entry.lockedUntil, nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// sweep drops the entries whose window and lock are both over, at most once
+// per window, so that the map does not grow with every client ever seen.
+func (s *memoryLoginAttemptStore) sweep(at time.Time, window time.Duration) {
+	if at.Sub(s.lastSweep) < window {
+		return
+	}
+	for key, entry := range s.attempts {
+		if !at.Before(entry.windowEnd) && !at.Before(entry.lockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+	s.lastSweep = at
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+)
+
+// Scopes of a login lockout.
+const (
+	lockoutScopeAccount = "account"
+	lockoutScopeIP      = "ip"
+)
+
+// LoginThrottle counts failed logins per account and per client IP, and locks
+// either one out for a while once it has too many failures in the window.
+type LoginThrottle interface {
+	Check(email, clientIP string) error
+	RecordFailure(email, clientIP string, employeeID *uint) error
+	RecordSuccess(email, clientIP string) error
+}
+
+type loginThrottle struct {
+	store           LoginAttemptStore
+	lockoutRepo     repositories.LoginLockoutRepository
+	maxAttempts     int
+	maxIPAttempts   int
+	window          time.Duration
+	lockoutDuration time.Duration
+}
+
+func NewLoginThrottle(store LoginAttemptStore, lockoutRepo repositories.LoginLockoutRepository, cfg *config.ApplicationConfig) LoginThrottle {
+	throttleCfg := cfg.Auth.LoginThrottle
+	return &loginThrottle{
+		store:           store,
+		lockoutRepo:     lockoutRepo,
+		maxAttempts:     defaultInt(throttleCfg.MaxAttempts, 5),
+		maxIPAttempts:   defaultInt(throttleCfg.MaxIPAttempts, 20),
+		window:          time.Minute * time.Duration(defaultInt(throttleCfg.Window, 15)),
+		lockoutDuration: time.Minute * time.Duration(defaultInt(throttleCfg.LockoutDuration, 15)),
+	}
+}
+
+// Check fails with ErrTooManyLoginAttempts while the account or the client IP
+// is locked, telling the client when to retry.
+func (t *loginThrottle) Check(email, clientIP string) error {
+	now := time.Now()
+
+	lockedUntil, err := t.store.LockedUntil(accountThrottleKey(email), now)
+	if err != nil {
+		return err
+	}
+	if clientIP != "" {
+		ipLockedUntil, err := t.store.LockedUntil(ipThrottleKey(clientIP), now)
+		if err != nil {
+			return err
+		}
+		if ipLockedUntil.After(lockedUntil) {
+			lockedUntil = ipLockedUntil
+		}
+	}
+
+	if lockedUntil.After(now) {
+		return ErrTooManyLoginAttempts.WithRetryAfter(lockedUntil.Sub(now))
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the account and the client IP
+// and locks whichever reached its limit. employeeID is nil when the email
+// does not belong to an employee.
+func (t *loginThrottle) RecordFailure(email, clientIP string, employeeID *uint) error {
+	now := time.Now()
+
+	failures, err := t.store.AddFailure(accountThrottleKey(email), now, t.window)
+	if err != nil {
+		return err
+	}
+	if failures >= t.maxAttempts {
+		normalized := normalizeEmail(email)
+		err := t.lock(accountThrottleKey(email), &models.LoginLockout{
+			Scope:      lockoutScopeAccount,
+			Email:      &normalized,
+			IPAddress:  clientIP,
+			EmployeeID: employeeID,
+			Attempts:   failures,
+		}, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+	ipFailures, err := t.store.AddFailure(ipThrottleKey(clientIP), now, t.window)
+	if err != nil {
+		return err
+	}
+	if ipFailures >= t.maxIPAttempts {
+		return t.lock(ipThrottleKey(clientIP), &models.LoginLockout{
+			Scope:     lockoutScopeIP,
+			IPAddress: clientIP,
+			Attempts:  ipFailures,
+		}, now)
+	}
+	return nil
+}
+
+// RecordSuccess clears the failures of the account. Those of the client IP
+// are kept, so that logging into one account does not reset the count of
+// guesses against the others.
+func (t *loginThrottle) RecordSuccess(email, clientIP string) error {
+	return t.store.Reset(accountThrottleKey(email))
+}
+
+func (t *loginThrottle) lock(key string, lockout *models.LoginLockout, now time.Time) error {
+	lockout.LockedUntil = now.Add(t.lockoutDuration)
+	if err := t.store.Lock(key, lockout.LockedUntil); err != nil {
+		return err
+	}
+	return t.lockoutRepo.Create(lockout)
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + normalizeEmail(email)
+}
+
+func ipThrottleKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func defaultInt(value, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"hr-leave-request/apperrors"
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestLoginThrottle returns a throttle with the default limits that never
+// fails to record lockouts.
+func newTestLoginThrottle() LoginThrottle {
+	lockoutRepo := new(mocks.MockLoginLockoutRepository)
+	lockoutRepo.On("Create", mock.AnythingOfType("*models.LoginLockout")).Return(nil).Maybe()
+	return NewLoginThrottle(NewMemoryLoginAttemptStore(), lockoutRepo, setupTestConfig())
+}
+
+func throttleTestConfig() *config.ApplicationConfig {
+	cfg := setupTestConfig()
+	cfg.Auth.LoginThrottle = config.LoginThrottleConfig{
+		MaxAttempts:     3,
+		MaxIPAttempts:   5,
+		Window:          15,
+		LockoutDuration: 10,
+	}
+	return cfg
+}
+
+func TestLoginThrottleLocksAccount(t *testing.T) {
+	employeeID := uint(1)
+	lockoutRepo := new(mocks.MockLoginLockoutRepository)
+	lockoutRepo.On("Create", mock.MatchedBy(func(lockout *models.LoginLockout) bool {
+		return lockout.Scope == lockoutScopeAccount && *lockout.Email == "john@example.com" &&
+			*lockout.EmployeeID == 1 && lockout.Attempts == 3 && lockout.IPAddress == "10.0.0.1"
+	})).Return(nil).Once()
+
+	throttle := NewLoginThrottle(NewMemoryLoginAttemptStore(), lockoutRepo, throttleTestConfig())
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, throttle.Check("john@example.com", "10.0.0.1"))
+		assert.NoError(t, throttle.RecordFailure("John@Example.com", "10.0.0.1", &employeeID))
+	}
+
+	// The account is locked from any IP, and the client is told when to retry
+	err := throttle.Check("john@example.com", "10.0.0.2")
+	assert.ErrorIs(t, err, ErrTooManyLoginAttempts)
+	appErr, ok := apperrors.As(err)
+	assert.True(t, ok)
+	assert.InDelta(t, (10 * time.Minute).Seconds(), appErr.RetryAfter.Seconds(), 1)
+
+	// Other accounts are not affected
+	assert.NoError(t, throttle.Check("jane@example.com", "10.0.0.1"))
+	lockoutRepo.AssertExpectations(t)
+}
+
+func TestLoginThrottleLocksIP(t *testing.T) {
+	lockoutRepo := new(mocks.MockLoginLockoutRepository)
+	lockoutRepo.On("Create", mock.MatchedBy(func(lockout *models.LoginLockout) bool {
+		return lockout.Scope == lockoutScopeIP && lockout.Email == nil && lockout.Attempts == 5
+	})).Return(nil).Once()
+
+	throttle := NewLoginThrottle(NewMemoryLoginAttemptStore(), lockoutRepo, throttleTestConfig())
+
+	// Guesses spread over several accounts still add up for the IP
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+	for _, email := range emails {
+		assert.NoError(t, throttle.RecordFailure(email, "10.0.0.1", nil))
+	}
+
+	assert.ErrorIs(t, throttle.Check("f@example.com", "10.0.0.1"), ErrTooManyLoginAttempts)
+	assert.NoError(t, throttle.Check("f@example.com", "10.0.0.2"))
+	lockoutRepo.AssertExpectations(t)
+}
+
+func TestLoginThrottleSuccessResetsAccount(t *testing.T) {
+	lockoutRepo := new(mocks.MockLoginLockoutRepository)
+	throttle := NewLoginThrottle(NewMemoryLoginAttemptStore(), lockoutRepo, throttleTestConfig())
+
+	assert.NoError(t, throttle.RecordFailure("john@example.com", "10.0.0.1", nil))
+	assert.NoError(t, throttle.RecordFailure("john@example.com", "10.0.0.1", nil))
+	assert.NoError(t, throttle.RecordSuccess("john@example.com", "10.0.0.1"))
+	assert.NoError(t, throttle.RecordFailure("john@example.com", "10.0.0.1", nil))
+	assert.NoError(t, throttle.RecordFailure("john@example.com", "10.0.0.1", nil))
+
+	assert.NoError(t, throttle.Check("john@example.com", "10.0.0.1"))
+	lockoutRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestMemoryLoginAttemptStore(t *testing.T) {
+	store := NewMemoryLoginAttemptStore()
+	now := time.Now()
+
+	failures, _ := store.AddFailure("key", now, time.Minute)
+	assert.Equal(t, 1, failures)
+	failures, _ = store.AddFailure("key", now.Add(30*time.Second), time.Minute)
+	assert.Equal(t, 2, failures)
+
+	// Failures outside the window start a new count
+	failures, _ = store.AddFailure("key", now.Add(time.Minute), time.Minute)
+	assert.Equal(t, 1, failures)
+
+	assert.NoError(t, store.Lock("key", now.Add(5*time.Minute)))
+	lockedUntil, _ := store.LockedUntil("key", now.Add(time.Minute))
+	assert.Equal(t, now.Add(5*time.Minute), lockedUntil)
+	lockedUntil, _ = store.LockedUntil("key", now.Add(5*time.Minute))
+	assert.True(t, lockedUntil.IsZero())
+
+	// Locking clears the failures
+	failures, _ = store.AddFailure("key", now.Add(6*time.Minute), time.Minute)
+	assert.Equal(t, 1, failures)
+}
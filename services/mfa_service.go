@@ -0,0 +1,360 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaTokenPurpose marks the tokens handed out between the password and
+	// the second factor of a login.
+	mfaTokenPurpose = "mfa_pending"
+	// mfaTokenExpiration is how long the second factor can be entered after
+	// the password.
+	mfaTokenExpiration = 5 * time.Minute
+	// recoveryCodeCount is how many recovery codes an employee gets at a time.
+	recoveryCodeCount = 10
+)
+
+// MFAService manages TOTP two-factor authentication: enrollment, recovery
+// codes and the verification of the second factor.
+type MFAService interface {
+	Enroll(employeeID uint) (*dtos.MFAEnrollmentResponse, error)
+	Confirm(employeeID uint, req *dtos.MFACodeRequest) (*dtos.MFARecoveryCodesResponse, error)
+	Disable(employeeID uint, userRole string, req *dtos.MFACodeRequest) error
+	RegenerateRecoveryCodes(employeeID uint, req *dtos.MFACodeRequest) (*dtos.MFARecoveryCodesResponse, error)
+	Verify(employeeID uint, req *dtos.MFACodeRequest) error
+	IsEnabled(employeeID uint) (bool, error)
+	IsRequired(userRole string) (bool, error)
+	EnrollmentRequired(employeeID uint, userRole string) (bool, error)
+}
+
+type mfaService struct {
+	employeeRepo     repositories.EmployeeRepository
+	enrollmentRepo   repositories.MFAEnrollmentRepository
+	recoveryCodeRepo repositories.MFARecoveryCodeRepository
+	permissions      PermissionChecker
+	transactor       repositories.Transactor
+	cfg              *config.ApplicationConfig
+}
+
+func NewMFAService(employeeRepo repositories.EmployeeRepository, enrollmentRepo repositories.MFAEnrollmentRepository, recoveryCodeRepo repositories.MFARecoveryCodeRepository, permissions PermissionChecker, transactor repositories.Transactor, cfg *config.ApplicationConfig) MFAService {
+	return &mfaService{
+		employeeRepo:     employeeRepo,
+		enrollmentRepo:   enrollmentRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		permissions:      permissions,
+		transactor:       transactor,
+		cfg:              cfg,
+	}
+}
+
+// Enroll generates a new TOTP secret for the employee. It replaces any
+// enrollment that was not confirmed yet; two-factor authentication is only
+// enabled once Confirm succeeds.
+func (s *mfaService) Enroll(employeeID uint) (*dtos.MFAEnrollmentResponse, error) {
+	enrollment, err := s.findEnrollment(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment != nil && enrollment.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	employee, err := s.employeeRepo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmployeeNotFound
+		}
+		return nil, err
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		enrollmentRepo := s.enrollmentRepo.WithTx(tx)
+		if err := enrollmentRepo.DeleteByEmployee(employeeID); err != nil {
+			return err
+		}
+		return enrollmentRepo.Create(&models.MFAEnrollment{
+			EmployeeID: employeeID,
+			Secret:     secret,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(s.issuer(), employee.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication with a first code from the
+// authenticator and returns the employee's recovery codes.
+func (s *mfaService) Confirm(employeeID uint, req *dtos.MFACodeRequest) (*dtos.MFARecoveryCodesResponse, error) {
+	enrollment, err := s.findEnrollment(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if enrollment.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := verifyTOTP(enrollment.Secret, req.Code, now, 0)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	var codes []string
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		confirmed, err := s.enrollmentRepo.WithTx(tx).Confirm(enrollment.ID, step, now)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return ErrMFAAlreadyEnabled
+		}
+		codes, err = s.replaceRecoveryCodes(tx, employeeID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off after checking a code. It is
+// refused when the employee's role requires two-factor authentication.
+func (s *mfaService) Disable(employeeID uint, userRole string, req *dtos.MFACodeRequest) error {
+	required, err := s.IsRequired(userRole)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+
+	if err := s.Verify(employeeID, req); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.enrollmentRepo.WithTx(tx).DeleteByEmployee(employeeID); err != nil {
+			return err
+		}
+		return s.recoveryCodeRepo.WithTx(tx).DeleteByEmployee(employeeID)
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the employee after
+// checking a code.
+func (s *mfaService) RegenerateRecoveryCodes(employeeID uint, req *dtos.MFACodeRequest) (*dtos.MFARecoveryCodesResponse, error) {
+	if err := s.Verify(employeeID, req); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, employeeID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Verify checks the second factor of the employee: a TOTP code that was not
+// used before, or an unused recovery code, which is consumed.
+func (s *mfaService) Verify(employeeID uint, req *dtos.MFACodeRequest) error {
+	enrollment, err := s.findEnrollment(employeeID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil || enrollment.ConfirmedAt == nil {
+		return ErrMFANotEnrolled
+	}
+
+	now := time.Now()
+	if req.Code != "" {
+		step, ok := verifyTOTP(enrollment.Secret, req.Code, now, enrollment.LastUsedStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		used, err := s.enrollmentRepo.UseStep(enrollment.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	if req.RecoveryCode == "" {
+		return ErrInvalidMFACode
+	}
+	used, err := s.recoveryCodeRepo.MarkUsed(employeeID, hashToken(normalizeRecoveryCode(req.RecoveryCode)), now)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// IsEnabled reports whether the employee has confirmed a TOTP enrollment.
+func (s *mfaService) IsEnabled(employeeID uint) (bool, error) {
+	enrollment, err := s.findEnrollment(employeeID)
+	if err != nil {
+		return false, err
+	}
+	return enrollment != nil && enrollment.ConfirmedAt != nil, nil
+}
+
+// IsRequired reports whether two-factor authentication is mandatory for the
+// role, which is the case for the roles that can approve leave when
+// auth.mfa.required_for_approvers is set.
+func (s *mfaService) IsRequired(userRole string) (bool, error) {
+	if !s.cfg.Auth.MFA.RequiredForApprovers {
+		return false, nil
+	}
+
+	role := strings.ToLower(strings.TrimSpace(userRole))
+	if role == "" {
+		return false, nil
+	}
+	for _, steps := range s.cfg.Leave.ApprovalChains {
+		for _, step := range steps {
+			if strings.EqualFold(strings.TrimSpace(step.Approver), role) {
+				return true, nil
+			}
+		}
+	}
+
+	permissions, err := s.permissions.PermissionsFor(role)
+	if err != nil {
+		return false, err
+	}
+	return permissions.Has(PermissionLeaveApproveAll) || permissions.Has(PermissionLeaveReadTeam), nil
+}
+
+// EnrollmentRequired reports whether the employee has to set up two-factor
+// authentication before using the API.
+func (s *mfaService) EnrollmentRequired(employeeID uint, userRole string) (bool, error) {
+	required, err := s.IsRequired(userRole)
+	if err != nil || !required {
+		return false, err
+	}
+
+	enabled, err := s.IsEnabled(employeeID)
+	if err != nil {
+		return false, err
+	}
+	return !enabled, nil
+}
+
+func (s *mfaService) findEnrollment(employeeID uint) (*models.MFAEnrollment, error) {
+	enrollment, err := s.enrollmentRepo.FindByEmployee(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+// replaceRecoveryCodes swaps the employee's recovery codes for new ones and
+// returns them in clear, the only time they are available.
+func (s *mfaService) replaceRecoveryCodes(tx *gorm.DB, employeeID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]models.MFARecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := randomHex(5)
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+		stored[i] = models.MFARecoveryCode{
+			EmployeeID: employeeID,
+			CodeHash:   hashToken(code),
+		}
+	}
+
+	recoveryCodeRepo := s.recoveryCodeRepo.WithTx(tx)
+	if err := recoveryCodeRepo.DeleteByEmployee(employeeID); err != nil {
+		return nil, err
+	}
+	if err := recoveryCodeRepo.CreateBatch(stored); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) issuer() string {
+	if s.cfg.Auth.MFA.Issuer == "" {
+		return "HR Leave Request"
+	}
+	return s.cfg.Auth.MFA.Issuer
+}
+
+// normalizeRecoveryCode accepts recovery codes regardless of case, dashes and
+// spaces.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func signMFAToken(keys JWTKeys, employeeID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(mfaTokenExpiration)
+	claims := jwt.MapClaims{
+		"purpose": mfaTokenPurpose,
+		"user_id": employeeID,
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+	}
+
+	signed, err := keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// parseMFAToken verifies a pending two-factor login token and returns the ID
+// of its employee.
+func parseMFAToken(keys JWTKeys, tokenString string) (uint, error) {
+	claims, err := keys.Parse(tokenString)
+	if err != nil || claims["purpose"] != mfaTokenPurpose {
+		return 0, ErrInvalidMFAToken
+	}
+	employeeID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, ErrInvalidMFAToken
+	}
+
+	return uint(employeeID), nil
+}
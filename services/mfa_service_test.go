@@ -0,0 +1,285 @@
+package services
+
+import (
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// newTestMFAService returns an MFA service where nobody has enrolled and
+// two-factor authentication is optional.
+func newTestMFAService() MFAService {
+	enrollmentRepo := new(mocks.MockMFAEnrollmentRepository)
+	enrollmentRepo.On("FindByEmployee", mock.Anything).Return(nil, gorm.ErrRecordNotFound).Maybe()
+	return NewMFAService(new(mocks.MockEmployeeRepository), enrollmentRepo, new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := totpCode(secret, time.Now().Unix()/totpPeriod)
+	assert.NoError(t, err)
+	return code
+}
+
+func TestMFAEnroll(t *testing.T) {
+	confirmedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		mockSetup func(*mocks.MockMFAEnrollmentRepository, *mocks.MockEmployeeRepository)
+		wantError error
+	}{
+		{
+			name: "new enrollment",
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, employeeRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+				employeeRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "john@example.com"}, nil)
+				repo.On("DeleteByEmployee", uint(1)).Return(nil)
+				repo.On("Create", mock.MatchedBy(func(enrollment *models.MFAEnrollment) bool {
+					return enrollment.EmployeeID == 1 && len(enrollment.Secret) == 32 && enrollment.ConfirmedAt == nil
+				})).Return(nil)
+			},
+		},
+		{
+			name: "unconfirmed enrollment is replaced",
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, employeeRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret}, nil)
+				employeeRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "john@example.com"}, nil)
+				repo.On("DeleteByEmployee", uint(1)).Return(nil)
+				repo.On("Create", mock.AnythingOfType("*models.MFAEnrollment")).Return(nil)
+			},
+		},
+		{
+			name: "already enabled",
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, employeeRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, ConfirmedAt: &confirmedAt}, nil)
+			},
+			wantError: ErrMFAAlreadyEnabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockMFAEnrollmentRepository)
+			mockEmployeeRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo, mockEmployeeRepo)
+
+			service := NewMFAService(mockEmployeeRepo, mockRepo, new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+			result, err := service.Enroll(1)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result.Secret, 32)
+				assert.True(t, strings.HasPrefix(result.ProvisioningURI, "otpauth://totp/HR%20Leave%20Request:john@example.com?"))
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockEmployeeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMFAConfirm(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      func(*testing.T) string
+		mockSetup func(*mocks.MockMFAEnrollmentRepository, *mocks.MockMFARecoveryCodeRepository)
+		wantError error
+	}{
+		{
+			name: "valid code enables two-factor authentication",
+			code: func(t *testing.T) string { return currentTOTPCode(t, rfcTOTPSecret) },
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret}, nil)
+				repo.On("Confirm", uint(2), mock.AnythingOfType("int64"), mock.AnythingOfType("time.Time")).Return(true, nil)
+				codeRepo.On("DeleteByEmployee", uint(1)).Return(nil)
+				codeRepo.On("CreateBatch", mock.MatchedBy(func(codes []models.MFARecoveryCode) bool {
+					return len(codes) == recoveryCodeCount && codes[0].EmployeeID == 1 && len(codes[0].CodeHash) == 64
+				})).Return(nil)
+			},
+		},
+		{
+			name: "wrong code",
+			code: func(*testing.T) string { return "000000" },
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret}, nil)
+			},
+			wantError: ErrInvalidMFACode,
+		},
+		{
+			name: "not enrolled",
+			code: func(*testing.T) string { return "000000" },
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrMFANotEnrolled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockMFAEnrollmentRepository)
+			mockCodeRepo := new(mocks.MockMFARecoveryCodeRepository)
+			tt.mockSetup(mockRepo, mockCodeRepo)
+
+			service := NewMFAService(new(mocks.MockEmployeeRepository), mockRepo, mockCodeRepo, newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+			result, err := service.Confirm(1, &dtos.MFACodeRequest{Code: tt.code(t)})
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result.RecoveryCodes, recoveryCodeCount)
+				// Recovery codes are stored by the hash of their normalized form
+				mockCodeRepo.AssertCalled(t, "CreateBatch", mock.MatchedBy(func(codes []models.MFARecoveryCode) bool {
+					return codes[0].CodeHash == hashToken(normalizeRecoveryCode(result.RecoveryCodes[0]))
+				}))
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockCodeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMFAVerify(t *testing.T) {
+	confirmedAt := time.Now()
+	enrollment := &models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret, ConfirmedAt: &confirmedAt}
+
+	tests := []struct {
+		name      string
+		request   func(*testing.T) *dtos.MFACodeRequest
+		mockSetup func(*mocks.MockMFAEnrollmentRepository, *mocks.MockMFARecoveryCodeRepository)
+		wantError error
+	}{
+		{
+			name: "valid code",
+			request: func(t *testing.T) *dtos.MFACodeRequest {
+				return &dtos.MFACodeRequest{Code: currentTOTPCode(t, rfcTOTPSecret)}
+			},
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(enrollment, nil)
+				repo.On("UseStep", uint(2), mock.AnythingOfType("int64")).Return(true, nil)
+			},
+		},
+		{
+			name: "code replayed concurrently",
+			request: func(t *testing.T) *dtos.MFACodeRequest {
+				return &dtos.MFACodeRequest{Code: currentTOTPCode(t, rfcTOTPSecret)}
+			},
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(enrollment, nil)
+				repo.On("UseStep", uint(2), mock.Anything).Return(false, nil)
+			},
+			wantError: ErrInvalidMFACode,
+		},
+		{
+			name: "recovery code",
+			request: func(*testing.T) *dtos.MFACodeRequest {
+				return &dtos.MFACodeRequest{RecoveryCode: "ABCDE-12345"}
+			},
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(enrollment, nil)
+				codeRepo.On("MarkUsed", uint(1), hashToken("abcde12345"), mock.AnythingOfType("time.Time")).Return(true, nil)
+			},
+		},
+		{
+			name: "used recovery code",
+			request: func(*testing.T) *dtos.MFACodeRequest {
+				return &dtos.MFACodeRequest{RecoveryCode: "abcde-12345"}
+			},
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(enrollment, nil)
+				codeRepo.On("MarkUsed", uint(1), hashToken("abcde12345"), mock.AnythingOfType("time.Time")).Return(false, nil)
+			},
+			wantError: ErrInvalidMFACode,
+		},
+		{
+			name: "unconfirmed enrollment",
+			request: func(*testing.T) *dtos.MFACodeRequest {
+				return &dtos.MFACodeRequest{Code: "123456"}
+			},
+			mockSetup: func(repo *mocks.MockMFAEnrollmentRepository, codeRepo *mocks.MockMFARecoveryCodeRepository) {
+				repo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret}, nil)
+			},
+			wantError: ErrMFANotEnrolled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockMFAEnrollmentRepository)
+			mockCodeRepo := new(mocks.MockMFARecoveryCodeRepository)
+			tt.mockSetup(mockRepo, mockCodeRepo)
+
+			service := NewMFAService(new(mocks.MockEmployeeRepository), mockRepo, mockCodeRepo, newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+			err := service.Verify(1, tt.request(t))
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockCodeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMFAIsRequired(t *testing.T) {
+	cfg := setupTestConfig()
+	cfg.Auth.MFA.RequiredForApprovers = true
+	cfg.Leave.ApprovalChains = map[string][]config.ApprovalStepConfig{
+		"vacation": {{Approver: "line_manager"}, {Approver: "Finance"}},
+	}
+	service := NewMFAService(new(mocks.MockEmployeeRepository), new(mocks.MockMFAEnrollmentRepository), new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, cfg)
+
+	for role, want := range map[string]bool{"hr": true, "manager": true, "finance": true, "employee": false, "": false} {
+		required, err := service.IsRequired(role)
+		assert.NoError(t, err)
+		assert.Equal(t, want, required, role)
+	}
+
+	// Without the switch two-factor authentication stays optional
+	optional := NewMFAService(new(mocks.MockEmployeeRepository), new(mocks.MockMFAEnrollmentRepository), new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, setupTestConfig())
+	required, err := optional.IsRequired("hr")
+	assert.NoError(t, err)
+	assert.False(t, required)
+}
+
+func TestMFADisable(t *testing.T) {
+	confirmedAt := time.Now()
+	cfg := setupTestConfig()
+	cfg.Auth.MFA.RequiredForApprovers = true
+
+	mockRepo := new(mocks.MockMFAEnrollmentRepository)
+	mockRepo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 2, EmployeeID: 1, Secret: rfcTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+	mockRepo.On("UseStep", uint(2), mock.Anything).Return(true, nil)
+	mockRepo.On("DeleteByEmployee", uint(1)).Return(nil).Once()
+	mockCodeRepo := new(mocks.MockMFARecoveryCodeRepository)
+	mockCodeRepo.On("DeleteByEmployee", uint(1)).Return(nil).Once()
+
+	service := NewMFAService(new(mocks.MockEmployeeRepository), mockRepo, mockCodeRepo, newTestPermissionChecker(), &mocks.MockTransactor{}, cfg)
+
+	// Approvers cannot opt out when it is mandatory
+	err := service.Disable(1, "hr", &dtos.MFACodeRequest{Code: currentTOTPCode(t, rfcTOTPSecret)})
+	assert.ErrorIs(t, err, ErrMFARequired)
+
+	err = service.Disable(1, "employee", &dtos.MFACodeRequest{Code: currentTOTPCode(t, rfcTOTPSecret)})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCodeRepo.AssertExpectations(t)
+}
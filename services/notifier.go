@@ -0,0 +1,61 @@
+package services
+
+import (
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Notifier delivers messages to employees outside of the API, such as
+// password reset links. Bind a different implementation in the injector to
+// send them by email or chat.
+type Notifier interface {
+	SendPasswordReset(employee *models.Employee, token string, expiresAt time.Time) error
+}
+
+type logNotifier struct {
+	cfg *config.ApplicationConfig
+}
+
+// NewLogNotifier returns a notifier that writes messages to the application
+// log. It is meant for development: the reset links, which let anyone who
+// reads the log take over the account, are only logged in the development
+// environment and left out everywhere else.
+func NewLogNotifier(cfg *config.ApplicationConfig) Notifier {
+	if cfg.AppConfig.Environment != "development" {
+		logrus.Warn("No notifier is configured; password reset links will not be delivered")
+	}
+	return &logNotifier{cfg: cfg}
+}
+
+func (n *logNotifier) SendPasswordReset(employee *models.Employee, token string, expiresAt time.Time) error {
+	fields := logrus.Fields{
+		"employee_id": employee.ID,
+		"email":       employee.Email,
+		"expires_at":  expiresAt,
+	}
+	if n.cfg.AppConfig.Environment == "development" {
+		fields["reset_link"] = passwordResetLink(n.cfg.Auth.PasswordResetURL, token)
+	}
+	logrus.WithFields(fields).Info("Password reset requested")
+	return nil
+}
+
+// passwordResetLink appends the reset token to the configured reset page.
+// Without a page, the bare token is returned.
+func passwordResetLink(resetURL, token string) string {
+	if resetURL == "" {
+		return token
+	}
+	link, err := url.Parse(resetURL)
+	if err != nil {
+		return token
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	oidcStatePurpose    = "oidc_state"
+	oidcStateExpiration = 10 * time.Minute
+)
+
+// OIDCAuthorization starts a single sign-on login. The browser is sent to URL
+// and StateToken is kept by the client, in a cookie, until the callback.
+type OIDCAuthorization struct {
+	URL        string
+	StateToken string
+	ExpiresAt  time.Time
+}
+
+// OIDCService signs employees in through an OpenID Connect provider with the
+// authorization code flow, then issues our own tokens as Login does.
+type OIDCService interface {
+	AuthorizationURL() (*OIDCAuthorization, error)
+	Callback(req *dtos.OIDCCallbackRequest, stateToken string) (*dtos.LoginResponse, error)
+}
+
+type oidcService struct {
+	repo           repositories.EmployeeRepository
+	roleChangeRepo repositories.EmployeeRoleChangeRepository
+	tokens         TokenService
+	mfa            MFAService
+	keys           JWTKeys
+	provider       *oidcProvider
+	transactor     repositories.Transactor
+	cfg            *config.ApplicationConfig
+}
+
+func NewOIDCService(repo repositories.EmployeeRepository, roleChangeRepo repositories.EmployeeRoleChangeRepository, tokens TokenService, mfa MFAService, keys JWTKeys, transactor repositories.Transactor, cfg *config.ApplicationConfig) OIDCService {
+	oidcCfg := cfg.Auth.OIDC
+	return &oidcService{
+		repo:           repo,
+		roleChangeRepo: roleChangeRepo,
+		tokens:         tokens,
+		mfa:            mfa,
+		keys:           keys,
+		provider:       newOIDCProvider(oidcCfg.IssuerURL, oidcCfg.ClientID, oidcCfg.ClientSecret, oidcCfg.RedirectURL),
+		transactor:     transactor,
+		cfg:            cfg,
+	}
+}
+
+// AuthorizationURL returns the provider URL to sign in at. The state, nonce
+// and PKCE verifier of the attempt travel in a signed state token, so no
+// server-side session is needed.
+func (s *oidcService) AuthorizationURL() (*OIDCAuthorization, error) {
+	if !s.cfg.Auth.OIDC.Enabled {
+		return nil, ErrOIDCDisabled
+	}
+
+	state, err := randomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	authURL, err := s.provider.authCodeURL(s.scopes(), state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(oidcStateExpiration)
+	stateToken, err := s.keys.Sign(jwt.MapClaims{
+		"purpose":       oidcStatePurpose,
+		"state":         state,
+		"nonce":         nonce,
+		"code_verifier": codeVerifier,
+		"exp":           expiresAt.Unix(),
+		"iat":           now.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCAuthorization{URL: authURL, StateToken: stateToken, ExpiresAt: expiresAt}, nil
+}
+
+// Callback completes a single sign-on login: it checks the state against the
+// state token, redeems the code, verifies the ID token and maps it to an
+// employee, who is created first when auto-provisioning is on.
+func (s *oidcService) Callback(req *dtos.OIDCCallbackRequest, stateToken string) (*dtos.LoginResponse, error) {
+	if !s.cfg.Auth.OIDC.Enabled {
+		return nil, ErrOIDCDisabled
+	}
+
+	claims, err := s.keys.Parse(stateToken)
+	if err != nil || claims["purpose"] != oidcStatePurpose || claims["state"] != req.State {
+		return nil, ErrInvalidOIDCState
+	}
+	nonce, _ := claims["nonce"].(string)
+	codeVerifier, _ := claims["code_verifier"].(string)
+
+	if req.Error != "" {
+		logrus.WithFields(logrus.Fields{
+			"error":       req.Error,
+			"description": req.ErrorDescription,
+		}).Warn("Single sign-on refused by the identity provider")
+		return nil, ErrOIDCLoginFailed
+	}
+
+	rawIDToken, err := s.provider.exchange(req.Code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	idClaims, err := s.provider.verifyIDToken(rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.employeeFor(idClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	return completeLogin(employee, s.tokens, s.mfa, s.keys)
+}
+
+// employeeFor finds the employee of the ID token by email address, creating
+// or updating it according to the configuration.
+func (s *oidcService) employeeFor(claims jwt.MapClaims) (*models.Employee, error) {
+	oidcCfg := s.cfg.Auth.OIDC
+
+	email, _ := claims[defaultString(oidcCfg.EmailClaim, "email")].(string)
+	email = normalizeEmail(email)
+	if email == "" {
+		return nil, ErrOIDCLoginFailed
+	}
+	// An address the provider has not verified could belong to anyone
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, ErrOIDCLoginFailed
+	}
+	role := s.mapRole(oidcGroups(claims[defaultString(oidcCfg.GroupsClaim, "groups")]))
+
+	employee, err := s.repo.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if employee == nil {
+		if !oidcCfg.AutoProvision {
+			return nil, ErrOIDCAccountNotFound
+		}
+		// A deactivated employee must not come back through provisioning
+		if _, err := s.repo.FindByEmailWithDeleted(email); err == nil {
+			return nil, ErrOIDCAccountNotFound
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return s.provision(email, oidcName(claims, email), role)
+	}
+
+	if oidcCfg.SyncRoles && (employee.Role == nil || *employee.Role != role) {
+		// Roles synced from the provider are granted by nobody in particular
+		change := &models.EmployeeRoleChange{
+			EmployeeID: employee.ID,
+			FromRole:   employee.Role,
+			ToRole:     role,
+		}
+		employee.Role = &role
+		err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+			if err := s.repo.WithTx(tx).Update(employee); err != nil {
+				return err
+			}
+			return s.roleChangeRepo.WithTx(tx).Create(change)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return employee, nil
+}
+
+// provision creates the employee of a first single sign-on login. The
+// account gets an unusable random password; the employee can set one through
+// the password reset flow if local login is wanted.
+func (s *oidcService) provision(email, name, role string) (*models.Employee, error) {
+	password, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	employee := &models.Employee{
+		Name:     name,
+		Email:    email,
+		Password: string(hashedPassword),
+		Role:     &role,
+	}
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(employee); err != nil {
+			return err
+		}
+		return s.roleChangeRepo.WithTx(tx).Create(&models.EmployeeRoleChange{
+			EmployeeID: employee.ID,
+			ToRole:     role,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return employee, nil
+}
+
+// mapRole returns the role of the first mapping whose group the user is in.
+func (s *oidcService) mapRole(groups []string) string {
+	for _, mapping := range s.cfg.Auth.OIDC.RoleMappings {
+		for _, group := range groups {
+			if group == mapping.Group {
+				return mapping.Role
+			}
+		}
+	}
+	return defaultString(s.cfg.Auth.OIDC.DefaultRole, "employee")
+}
+
+func (s *oidcService) scopes() []string {
+	scopes := []string{"openid"}
+	configured := s.cfg.Auth.OIDC.Scopes
+	if len(configured) == 0 {
+		configured = []string{"email", "profile"}
+	}
+	for _, scope := range configured {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// oidcGroups reads a groups claim, which providers send as a list or, with a
+// single group, as a string.
+func oidcGroups(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		groups := make([]string, 0, len(value))
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+		return groups
+	default:
+		return nil
+	}
+}
+
+// oidcName picks the display name of a new employee, falling back to the
+// local part of the email address.
+func oidcName(claims jwt.MapClaims, email string) string {
+	name, _ := claims["name"].(string)
+	if strings.TrimSpace(name) == "" {
+		givenName, _ := claims["given_name"].(string)
+		familyName, _ := claims["family_name"].(string)
+		name = givenName + " " + familyName
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+	if len([]rune(name)) > 100 {
+		name = string([]rune(name)[:100])
+	}
+	return name
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
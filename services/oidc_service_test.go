@@ -0,0 +1,462 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	testOIDCClientID     = "hr-leave-request"
+	testOIDCClientSecret = "client-secret"
+)
+
+// mockOIDCProvider is a minimal OpenID Connect provider: it serves discovery
+// and keys, and redeems the codes handed out by signIn for ID tokens after
+// checking the client credentials and PKCE verifier.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockOIDCCode
+}
+
+type mockOIDCCode struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, codes: map[string]mockOIDCCode{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "idp-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != testOIDCClientID || clientSecret != testOIDCClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		p.mu.Lock()
+		code, found := p.codes[r.PostFormValue("code")]
+		delete(p.codes, r.PostFormValue("code"))
+		p.mu.Unlock()
+
+		verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !found || base64.RawURLEncoding.EncodeToString(verifier[:]) != code.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "provider-access-token",
+			"token_type":   "Bearer",
+			"id_token":     p.idToken(t, code.claims),
+		})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// signIn plays the user signing in at the authorization URL and returns the
+// code the provider redirects back with. The ID token gets standard claims
+// for the request, overridden by claims; a nil claim is removed.
+func (p *mockOIDCProvider) signIn(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	params := parsed.Query()
+
+	idClaims := jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            params.Get("client_id"),
+		"sub":            "user-123",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"name":           "Jane Doe",
+		"groups":         []string{"everyone"},
+		"nonce":          params.Get("nonce"),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(idClaims, name)
+		} else {
+			idClaims[name] = value
+		}
+	}
+
+	code, err := randomToken(16)
+	require.NoError(t, err)
+	p.mu.Lock()
+	p.codes[code] = mockOIDCCode{challenge: params.Get("code_challenge"), claims: idClaims}
+	p.mu.Unlock()
+	return code
+}
+
+func (p *mockOIDCProvider) idToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "idp-1"
+	signed, err := token.SignedString(p.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func oidcTestConfig(issuer string) *config.ApplicationConfig {
+	cfg := setupTestConfig()
+	cfg.Auth.OIDC = config.OIDCConfig{
+		Enabled:      true,
+		IssuerURL:    issuer,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURL:  "http://localhost:9090/api/v1/auth/oidc/callback",
+		RoleMappings: []config.OIDCRoleMappingConfig{
+			{Group: "hr-team", Role: "hr"},
+			{Group: "people-managers", Role: "manager"},
+		},
+	}
+	return cfg
+}
+
+func TestOIDCAuthorizationURL(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	cfg := oidcTestConfig(provider.server.URL)
+	service := NewOIDCService(new(mocks.MockEmployeeRepository), new(mocks.MockEmployeeRoleChangeRepository), newTestTokenService(new(mocks.MockEmployeeRepository)), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+	authorization, err := service.AuthorizationURL()
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	assert.Equal(t, provider.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	params := parsed.Query()
+	assert.Equal(t, "code", params.Get("response_type"))
+	assert.Equal(t, testOIDCClientID, params.Get("client_id"))
+	assert.Equal(t, cfg.Auth.OIDC.RedirectURL, params.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", params.Get("scope"))
+	assert.Equal(t, "S256", params.Get("code_challenge_method"))
+
+	// The state token carries what the callback needs to check the response
+	claims, err := newTestJWTKeys().Parse(authorization.StateToken)
+	require.NoError(t, err)
+	assert.Equal(t, oidcStatePurpose, claims["purpose"])
+	assert.Equal(t, params.Get("state"), claims["state"])
+	assert.Equal(t, params.Get("nonce"), claims["nonce"])
+	challenge := sha256.Sum256([]byte(claims["code_verifier"].(string)))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), params.Get("code_challenge"))
+	assert.WithinDuration(t, time.Now().Add(oidcStateExpiration), authorization.ExpiresAt, time.Minute)
+
+	disabled := NewOIDCService(new(mocks.MockEmployeeRepository), new(mocks.MockEmployeeRoleChangeRepository), newTestTokenService(new(mocks.MockEmployeeRepository)), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+	_, err = disabled.AuthorizationURL()
+	assert.ErrorIs(t, err, ErrOIDCDisabled)
+}
+
+func TestOIDCCallback(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	role := "employee"
+	existing := &models.Employee{ID: 1, Name: "Jane Doe", Email: "jane@example.com", Role: &role}
+
+	tests := []struct {
+		name           string
+		configure      func(*config.OIDCConfig)
+		claims         jwt.MapClaims
+		tamper         func(req *dtos.OIDCCallbackRequest, stateToken *string)
+		mockSetup      func(*mocks.MockEmployeeRepository)
+		wantRoleChange *models.EmployeeRoleChange
+		wantError      error
+		wantRole       string
+		wantEmployeeID uint
+	}{
+		{
+			name: "existing employee",
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "jane@example.com").Return(existing, nil)
+			},
+			wantRole:       "employee",
+			wantEmployeeID: 1,
+		},
+		{
+			name:   "email is matched case-insensitively",
+			claims: jwt.MapClaims{"email": "Jane@Example.com"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "jane@example.com").Return(existing, nil)
+			},
+			wantRole:       "employee",
+			wantEmployeeID: 1,
+		},
+		{
+			name:      "new employee is provisioned with the mapped role",
+			configure: func(c *config.OIDCConfig) { c.AutoProvision = true },
+			claims:    jwt.MapClaims{"email": "new@example.com", "groups": []string{"everyone", "people-managers", "hr-team"}},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+				m.On("FindByEmailWithDeleted", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+				m.On("Create", mock.MatchedBy(func(e *models.Employee) bool {
+					return e.Email == "new@example.com" && e.Name == "Jane Doe" && *e.Role == "hr" && e.Password != ""
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.Employee).ID = 7
+				}).Return(nil)
+			},
+			wantRoleChange: &models.EmployeeRoleChange{EmployeeID: 7, ToRole: "hr"},
+			wantRole:       "hr",
+			wantEmployeeID: 7,
+		},
+		{
+			name:      "new employee without a matching group gets the default role",
+			configure: func(c *config.OIDCConfig) { c.AutoProvision = true },
+			claims:    jwt.MapClaims{"email": "new@example.com", "groups": nil},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+				m.On("FindByEmailWithDeleted", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+				m.On("Create", mock.MatchedBy(func(e *models.Employee) bool {
+					return *e.Role == "employee"
+				})).Return(nil)
+			},
+			wantRoleChange: &models.EmployeeRoleChange{ToRole: "employee"},
+			wantRole:       "employee",
+		},
+		{
+			name:      "deactivated employee is not provisioned again",
+			configure: func(c *config.OIDCConfig) { c.AutoProvision = true },
+			claims:    jwt.MapClaims{"email": "new@example.com"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+				m.On("FindByEmailWithDeleted", "new@example.com").
+					Return(&models.Employee{ID: 7, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, nil)
+			},
+			wantError: ErrOIDCAccountNotFound,
+		},
+		{
+			name:   "unknown employee without provisioning",
+			claims: jwt.MapClaims{"email": "new@example.com"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				m.On("FindByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrOIDCAccountNotFound,
+		},
+		{
+			name:      "roles are synced from groups",
+			configure: func(c *config.OIDCConfig) { c.SyncRoles = true },
+			claims:    jwt.MapClaims{"groups": "people-managers"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {
+				employeeRole := "employee"
+				m.On("FindByEmail", "jane@example.com").Return(&models.Employee{ID: 1, Email: "jane@example.com", Role: &employeeRole}, nil)
+				m.On("Update", mock.MatchedBy(func(e *models.Employee) bool {
+					return *e.Role == "manager"
+				})).Return(nil)
+			},
+			wantRoleChange: &models.EmployeeRoleChange{EmployeeID: 1, FromRole: &role, ToRole: "manager"},
+			wantRole:       "manager",
+			wantEmployeeID: 1,
+		},
+		{
+			name:      "unverified email",
+			claims:    jwt.MapClaims{"email_verified": false},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name:      "missing email",
+			claims:    jwt.MapClaims{"email": nil},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name:      "wrong nonce",
+			claims:    jwt.MapClaims{"nonce": "replayed"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name:      "token for another client",
+			claims:    jwt.MapClaims{"aud": "other-client"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name:      "token from another issuer",
+			claims:    jwt.MapClaims{"iss": "https://evil.example.com"},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name:      "expired token",
+			claims:    jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name: "state does not match",
+			tamper: func(req *dtos.OIDCCallbackRequest, stateToken *string) {
+				req.State = "forged"
+			},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidOIDCState,
+		},
+		{
+			name: "missing state cookie",
+			tamper: func(req *dtos.OIDCCallbackRequest, stateToken *string) {
+				*stateToken = ""
+			},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrInvalidOIDCState,
+		},
+		{
+			name: "code is refused",
+			tamper: func(req *dtos.OIDCCallbackRequest, stateToken *string) {
+				req.Code = "unknown"
+			},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+		{
+			name: "provider reports an error",
+			tamper: func(req *dtos.OIDCCallbackRequest, stateToken *string) {
+				req.Code = ""
+				req.Error = "access_denied"
+			},
+			mockSetup: func(m *mocks.MockEmployeeRepository) {},
+			wantError: ErrOIDCLoginFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := oidcTestConfig(provider.server.URL)
+			if tt.configure != nil {
+				tt.configure(&cfg.Auth.OIDC)
+			}
+			mockRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo)
+			// Roles granted through single sign-on have no actor
+			mockRoleChangeRepo := new(mocks.MockEmployeeRoleChangeRepository)
+			if tt.wantRoleChange != nil {
+				mockRoleChangeRepo.On("Create", tt.wantRoleChange).Return(nil)
+			}
+			service := NewOIDCService(mockRepo, mockRoleChangeRepo, newTestTokenService(mockRepo), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+			authorization, err := service.AuthorizationURL()
+			require.NoError(t, err)
+			parsed, err := url.Parse(authorization.URL)
+			require.NoError(t, err)
+
+			req := &dtos.OIDCCallbackRequest{
+				Code:  provider.signIn(t, authorization.URL, tt.claims),
+				State: parsed.Query().Get("state"),
+			}
+			stateToken := authorization.StateToken
+			if tt.tamper != nil {
+				tt.tamper(req, &stateToken)
+			}
+
+			response, err := service.Callback(req, stateToken)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, response)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, response.AuthResponse)
+				assert.NotEmpty(t, response.Token)
+				assert.Equal(t, tt.wantRole, *response.User.Role)
+				assert.Equal(t, tt.wantEmployeeID, response.User.ID)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockRoleChangeRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOIDCCallbackCodeIsSingleUse(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	role := "employee"
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmail", "jane@example.com").Return(&models.Employee{ID: 1, Email: "jane@example.com", Role: &role}, nil)
+	service := NewOIDCService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), newTestTokenService(mockRepo), newTestMFAService(), newTestJWTKeys(), &mocks.MockTransactor{}, oidcTestConfig(provider.server.URL))
+
+	authorization, err := service.AuthorizationURL()
+	require.NoError(t, err)
+	parsed, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	req := &dtos.OIDCCallbackRequest{Code: provider.signIn(t, authorization.URL, nil), State: parsed.Query().Get("state")}
+
+	_, err = service.Callback(req, authorization.StateToken)
+	assert.NoError(t, err)
+	_, err = service.Callback(req, authorization.StateToken)
+	assert.ErrorIs(t, err, ErrOIDCLoginFailed)
+}
+
+func TestOIDCCallbackWithMFA(t *testing.T) {
+	provider := newMockOIDCProvider(t)
+	role := "employee"
+	mockRepo := new(mocks.MockEmployeeRepository)
+	mockRepo.On("FindByEmail", "jane@example.com").Return(&models.Employee{ID: 1, Email: "jane@example.com", Role: &role}, nil)
+	confirmedAt := time.Now()
+	mockEnrollmentRepo := new(mocks.MockMFAEnrollmentRepository)
+	mockEnrollmentRepo.On("FindByEmployee", uint(1)).Return(&models.MFAEnrollment{ID: 1, EmployeeID: 1, ConfirmedAt: &confirmedAt}, nil)
+	cfg := oidcTestConfig(provider.server.URL)
+	mfa := NewMFAService(mockRepo, mockEnrollmentRepo, new(mocks.MockMFARecoveryCodeRepository), newTestPermissionChecker(), &mocks.MockTransactor{}, cfg)
+	service := NewOIDCService(mockRepo, new(mocks.MockEmployeeRoleChangeRepository), newTestTokenService(mockRepo), mfa, newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+	authorization, err := service.AuthorizationURL()
+	require.NoError(t, err)
+	parsed, err := url.Parse(authorization.URL)
+	require.NoError(t, err)
+	req := &dtos.OIDCCallbackRequest{Code: provider.signIn(t, authorization.URL, nil), State: parsed.Query().Get("state")}
+
+	response, err := service.Callback(req, authorization.StateToken)
+	require.NoError(t, err)
+	assert.True(t, response.MFARequired)
+	assert.NotEmpty(t, response.MFAToken)
+	assert.Nil(t, response.AuthResponse)
+}
+
+func TestOIDCName(t *testing.T) {
+	assert.Equal(t, "Jane Doe", oidcName(jwt.MapClaims{"name": "Jane Doe"}, "jane@example.com"))
+	assert.Equal(t, "Jane Doe", oidcName(jwt.MapClaims{"given_name": "Jane", "family_name": "Doe"}, "jane@example.com"))
+	assert.Equal(t, "jane", oidcName(jwt.MapClaims{}, "jane@example.com"))
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// PasswordService lets employees change a password they know and recover one
+// they forgot. Every password change ends all of the employee's sessions.
+type PasswordService interface {
+	ForgotPassword(req *dtos.ForgotPasswordRequest) error
+	ResetPassword(req *dtos.ResetPasswordRequest) error
+	ChangePassword(employeeID uint, req *dtos.ChangePasswordRequest) error
+}
+
+type passwordService struct {
+	repo           repositories.EmployeeRepository
+	resetTokenRepo repositories.PasswordResetTokenRepository
+	tokens         TokenService
+	notifier       Notifier
+	transactor     repositories.Transactor
+	cfg            *config.ApplicationConfig
+}
+
+func NewPasswordService(repo repositories.EmployeeRepository, resetTokenRepo repositories.PasswordResetTokenRepository, tokens TokenService, notifier Notifier, transactor repositories.Transactor, cfg *config.ApplicationConfig) PasswordService {
+	return &passwordService{
+		repo:           repo,
+		resetTokenRepo: resetTokenRepo,
+		tokens:         tokens,
+		notifier:       notifier,
+		transactor:     transactor,
+		cfg:            cfg,
+	}
+}
+
+// ForgotPassword sends a single-use reset token to the employee with the
+// given email. Unknown emails are ignored without an error, so the endpoint
+// does not reveal who has an account.
+func (s *passwordService) ForgotPassword(req *dtos.ForgotPasswordRequest) error {
+	employee, err := s.repo.FindByEmail(normalizeEmail(req.Email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	expiration := s.cfg.Auth.PasswordResetExpiration
+	if expiration <= 0 {
+		expiration = 30
+	}
+
+	resetToken := &models.PasswordResetToken{
+		EmployeeID: employee.ID,
+		TokenHash:  hashToken(token),
+		ExpiresAt:  time.Now().Add(time.Minute * time.Duration(expiration)),
+	}
+	if err := s.resetTokenRepo.Create(resetToken); err != nil {
+		return err
+	}
+
+	return s.notifier.SendPasswordReset(employee, token, resetToken.ExpiresAt)
+}
+
+// ResetPassword sets a new password with a reset token from ForgotPassword.
+func (s *passwordService) ResetPassword(req *dtos.ResetPasswordRequest) error {
+	resetToken, err := s.resetTokenRepo.FindByHash(hashToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if resetToken.UsedAt != nil || !now.Before(resetToken.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	// Consuming the token in the same transaction keeps it from being used
+	// twice
+	return s.updatePassword(resetToken.EmployeeID, req.Password, func(tx *gorm.DB) error {
+		used, err := s.resetTokenRepo.WithTx(tx).MarkUsed(resetToken.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidResetToken
+		}
+		return nil
+	})
+}
+
+// ChangePassword replaces the password of a signed-in employee, who has to
+// confirm the current one.
+func (s *passwordService) ChangePassword(employeeID uint, req *dtos.ChangePasswordRequest) error {
+	employee, err := s.repo.FindByID(employeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEmployeeNotFound
+		}
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte(req.CurrentPassword)); err != nil {
+		return ErrInvalidCurrentPassword
+	}
+
+	return s.updatePassword(employee.ID, req.NewPassword, nil)
+}
+
+// updatePassword stores the new password, running before within the same
+// transaction, and revokes every session of the employee.
+func (s *passwordService) updatePassword(employeeID uint, password string, before func(tx *gorm.DB) error) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// Access tokens carry their issue time in whole seconds
+	changedAt := time.Now().Truncate(time.Second)
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if before != nil {
+			if err := before(tx); err != nil {
+				return err
+			}
+		}
+		return s.repo.WithTx(tx).Update(&models.Employee{
+			ID:                employeeID,
+			Password:          string(hashedPassword),
+			PasswordChangedAt: &changedAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.tokens.RevokeEmployeeTokens(employeeID)
+}
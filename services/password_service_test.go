@@ -0,0 +1,249 @@
+package services
+
+import (
+	"hr-leave-request/dtos"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type mockNotifier struct {
+	mock.Mock
+}
+
+func (m *mockNotifier) SendPasswordReset(employee *models.Employee, token string, expiresAt time.Time) error {
+	args := m.Called(employee, token, expiresAt)
+	return args.Error(0)
+}
+
+// newTestPasswordService wires a password service whose token service revokes
+// sessions through refreshRepo.
+func newTestPasswordService(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository, notifier Notifier) PasswordService {
+	tokens := NewTokenService(repo, refreshRepo, new(mocks.MockRevokedTokenRepository), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+	return NewPasswordService(repo, resetRepo, tokens, notifier, &mocks.MockTransactor{}, setupTestConfig())
+}
+
+func TestForgotPassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		email     string
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockPasswordResetTokenRepository, *mockNotifier)
+	}{
+		{
+			name:  "reset token is sent",
+			email: "john@example.com",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, notifier *mockNotifier) {
+				employee := &models.Employee{ID: 1, Email: "john@example.com"}
+				repo.On("FindByEmail", "john@example.com").Return(employee, nil)
+				resetRepo.On("Create", mock.MatchedBy(func(token *models.PasswordResetToken) bool {
+					return token.EmployeeID == 1 && len(token.TokenHash) == 64 &&
+						token.ExpiresAt.After(time.Now().Add(29*time.Minute))
+				})).Return(nil)
+				// Only the hash of the token sent is stored
+				notifier.On("SendPasswordReset", employee, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil).Run(func(args mock.Arguments) {
+					resetRepo.AssertCalled(t, "Create", mock.MatchedBy(func(token *models.PasswordResetToken) bool {
+						return token.TokenHash == hashToken(args.String(1))
+					}))
+				})
+			},
+		},
+		{
+			name:  "email is matched case-insensitively",
+			email: "John@Example.com ",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, notifier *mockNotifier) {
+				employee := &models.Employee{ID: 1, Email: "john@example.com"}
+				repo.On("FindByEmail", "john@example.com").Return(employee, nil)
+				resetRepo.On("Create", mock.AnythingOfType("*models.PasswordResetToken")).Return(nil)
+				notifier.On("SendPasswordReset", employee, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name:  "unknown email is ignored",
+			email: "nobody@example.com",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, notifier *mockNotifier) {
+				repo.On("FindByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			mockResetRepo := new(mocks.MockPasswordResetTokenRepository)
+			notifier := new(mockNotifier)
+			tt.mockSetup(mockRepo, mockResetRepo, notifier)
+
+			service := newTestPasswordService(mockRepo, mockResetRepo, new(mocks.MockRefreshTokenRepository), notifier)
+			err := service.ForgotPassword(&dtos.ForgotPasswordRequest{Email: tt.email})
+
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+			mockResetRepo.AssertExpectations(t)
+			notifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	token := "reset-token"
+
+	tests := []struct {
+		name      string
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockPasswordResetTokenRepository, *mocks.MockRefreshTokenRepository)
+		wantError error
+	}{
+		{
+			name: "password is reset and sessions revoked",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				resetRepo.On("FindByHash", hashToken(token)).Return(&models.PasswordResetToken{ID: 4, EmployeeID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+				resetRepo.On("MarkUsed", uint(4), mock.AnythingOfType("time.Time")).Return(true, nil)
+				repo.On("Update", mock.MatchedBy(func(employee *models.Employee) bool {
+					return employee.ID == 1 && employee.PasswordChangedAt != nil &&
+						bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte("newsecret")) == nil
+				})).Return(nil)
+				refreshRepo.On("RevokeByEmployee", uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name: "used token",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				resetRepo.On("FindByHash", hashToken(token)).Return(&models.PasswordResetToken{ID: 4, EmployeeID: 1, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}, nil)
+			},
+			wantError: ErrInvalidResetToken,
+		},
+		{
+			name: "expired token",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				resetRepo.On("FindByHash", hashToken(token)).Return(&models.PasswordResetToken{ID: 4, EmployeeID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+			wantError: ErrInvalidResetToken,
+		},
+		{
+			name: "token used concurrently",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				resetRepo.On("FindByHash", hashToken(token)).Return(&models.PasswordResetToken{ID: 4, EmployeeID: 1, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+				resetRepo.On("MarkUsed", uint(4), mock.AnythingOfType("time.Time")).Return(false, nil)
+			},
+			wantError: ErrInvalidResetToken,
+		},
+		{
+			name: "unknown token",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, resetRepo *mocks.MockPasswordResetTokenRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				resetRepo.On("FindByHash", hashToken(token)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrInvalidResetToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			mockResetRepo := new(mocks.MockPasswordResetTokenRepository)
+			mockRefreshRepo := new(mocks.MockRefreshTokenRepository)
+			tt.mockSetup(mockRepo, mockResetRepo, mockRefreshRepo)
+
+			service := newTestPasswordService(mockRepo, mockResetRepo, mockRefreshRepo, new(mockNotifier))
+			err := service.ResetPassword(&dtos.ResetPasswordRequest{Token: token, Password: "newsecret"})
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockResetRepo.AssertExpectations(t)
+			mockRefreshRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("oldsecret"), bcrypt.DefaultCost)
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		mockSetup       func(*mocks.MockEmployeeRepository, *mocks.MockRefreshTokenRepository)
+		wantError       error
+	}{
+		{
+			name:            "password is changed and sessions revoked",
+			currentPassword: "oldsecret",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Password: string(hashedPassword)}, nil)
+				repo.On("Update", mock.MatchedBy(func(employee *models.Employee) bool {
+					return employee.ID == 1 && employee.PasswordChangedAt != nil &&
+						bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte("newsecret")) == nil
+				})).Return(nil)
+				refreshRepo.On("RevokeByEmployee", uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+			},
+		},
+		{
+			name:            "wrong current password",
+			currentPassword: "wrong",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Password: string(hashedPassword)}, nil)
+			},
+			wantError: ErrInvalidCurrentPassword,
+		},
+		{
+			name:            "employee not found",
+			currentPassword: "oldsecret",
+			mockSetup: func(repo *mocks.MockEmployeeRepository, refreshRepo *mocks.MockRefreshTokenRepository) {
+				repo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrEmployeeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockEmployeeRepository)
+			mockRefreshRepo := new(mocks.MockRefreshTokenRepository)
+			tt.mockSetup(mockRepo, mockRefreshRepo)
+
+			service := newTestPasswordService(mockRepo, new(mocks.MockPasswordResetTokenRepository), mockRefreshRepo, new(mockNotifier))
+			err := service.ChangePassword(1, &dtos.ChangePasswordRequest{CurrentPassword: tt.currentPassword, NewPassword: "newsecret"})
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockRefreshRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogNotifier(t *testing.T) {
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	employee := &models.Employee{ID: 1, Email: "john@example.com"}
+
+	// Reset links are only logged in development
+	cfg := setupTestConfig()
+	cfg.AppConfig.Environment = "production"
+	assert.NoError(t, NewLogNotifier(cfg).SendPasswordReset(employee, "abc", time.Now()))
+	assert.NotContains(t, hook.LastEntry().Data, "reset_link")
+
+	cfg.AppConfig.Environment = "development"
+	assert.NoError(t, NewLogNotifier(cfg).SendPasswordReset(employee, "abc", time.Now()))
+	assert.Equal(t, "abc", hook.LastEntry().Data["reset_link"])
+}
+
+func TestPasswordResetLink(t *testing.T) {
+	assert.Equal(t, "https://hr.example.com/reset?lang=en&token=abc", passwordResetLink("https://hr.example.com/reset?lang=en", "abc"))
+	assert.Equal(t, "abc", passwordResetLink("", "abc"))
+}
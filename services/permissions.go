@@ -0,0 +1,118 @@
+package services
+
+import (
+	"hr-leave-request/repositories"
+	"strings"
+)
+
+// Permissions guarded by the services and routes. Roles are granted them
+// through the role_permissions table, so a new role only needs rows there.
+const (
+	// PermissionLeaveReadAll allows reading every leave request and its
+	// history, including deleted ones.
+	PermissionLeaveReadAll = "leave:read:all"
+	// PermissionLeaveReadTeam allows reading the leave requests of direct
+	// reports.
+	PermissionLeaveReadTeam = "leave:read:team"
+	// PermissionLeaveUpdateAll allows editing and deleting other employees'
+	// undecided leave requests.
+	PermissionLeaveUpdateAll = "leave:update:all"
+	// PermissionLeaveUpdateTeam allows editing and deleting the undecided
+	// leave requests of direct reports.
+	PermissionLeaveUpdateTeam = "leave:update:team"
+	// PermissionLeaveApproveAll allows deciding any leave request and any
+	// approval step, overriding the line manager.
+	PermissionLeaveApproveAll = "leave:approve:all"
+	PermissionHolidayManage   = "holiday:manage"
+	PermissionEmployeeCreate  = "employee:create"
+	PermissionEmployeeUpdate  = "employee:update"
+	// PermissionEmployeeRoleAssign allows creating employees with a role
+	// other than employee and changing roles.
+	PermissionEmployeeRoleAssign = "employee:role:assign"
+	// PermissionEmployeeRead allows reading the whole employee directory,
+	// including deactivated employees and role history.
+	PermissionEmployeeRead = "employee:read"
+	// PermissionAPIKeyManage allows creating, listing and revoking service
+	// API keys.
+	PermissionAPIKeyManage = "api_key:manage"
+	// PermissionLeaveBalanceManage allows setting an employee's leave
+	// entitlement, overriding the configured default.
+	PermissionLeaveBalanceManage = "leave:balance:manage"
+)
+
+// Permissions is the set of permissions granted to a role.
+type Permissions map[string]bool
+
+// Has reports whether the permission is in the set.
+func (p Permissions) Has(permission string) bool {
+	return p[permission]
+}
+
+// PermissionChecker resolves the permissions of a role from the database.
+// Role names are case-insensitive; an unknown or empty role has none.
+type PermissionChecker interface {
+	PermissionsFor(role string) (Permissions, error)
+	HasPermission(role, permission string) (bool, error)
+	IsKnownRole(role string) (bool, error)
+}
+
+type permissionChecker struct {
+	repo repositories.RolePermissionRepository
+}
+
+func NewPermissionChecker(repo repositories.RolePermissionRepository) PermissionChecker {
+	return &permissionChecker{repo: repo}
+}
+
+func (c *permissionChecker) PermissionsFor(role string) (Permissions, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		return Permissions{}, nil
+	}
+
+	rolePermissions, err := c.repo.FindByRole(role)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(Permissions, len(rolePermissions))
+	for _, rolePermission := range rolePermissions {
+		permissions[rolePermission.Permission] = true
+	}
+	return permissions, nil
+}
+
+// IsKnownRole reports whether the role can be given to employees: the
+// employee role, which needs no permissions, or a role with rows in
+// role_permissions.
+func (c *permissionChecker) IsKnownRole(role string) (bool, error) {
+	if strings.ToLower(strings.TrimSpace(role)) == "employee" {
+		return true, nil
+	}
+	permissions, err := c.PermissionsFor(role)
+	if err != nil {
+		return false, err
+	}
+	return len(permissions) > 0, nil
+}
+
+func (c *permissionChecker) HasPermission(role, permission string) (bool, error) {
+	permissions, err := c.PermissionsFor(role)
+	if err != nil {
+		return false, err
+	}
+	return permissions.Has(permission), nil
+}
+
+// ensureKnownRole returns ErrUnknownRole unless the role can be given to
+// employees.
+func ensureKnownRole(checker PermissionChecker, role string) error {
+	known, err := checker.IsKnownRole(role)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return ErrUnknownRole
+	}
+	return nil
+}
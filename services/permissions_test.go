@@ -0,0 +1,83 @@
+package services
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// testRolePermissions mirrors the role permissions seeded by the migration.
+var testRolePermissions = map[string][]string{
+	"hr": {
+		PermissionLeaveReadAll,
+		PermissionLeaveReadTeam,
+		PermissionLeaveUpdateAll,
+		PermissionLeaveApproveAll,
+		PermissionHolidayManage,
+		PermissionEmployeeCreate,
+		PermissionEmployeeUpdate,
+		PermissionEmployeeRoleAssign,
+		PermissionEmployeeRead,
+		PermissionAPIKeyManage,
+		PermissionLeaveBalanceManage,
+	},
+	"manager": {
+		PermissionLeaveReadTeam,
+		PermissionLeaveUpdateTeam,
+	},
+}
+
+// newTestPermissionChecker returns a checker over the seeded role
+// permissions; any other role has none.
+func newTestPermissionChecker() PermissionChecker {
+	repo := new(mocks.MockRolePermissionRepository)
+	for role, permissions := range testRolePermissions {
+		rolePermissions := make([]models.RolePermission, len(permissions))
+		for i, permission := range permissions {
+			rolePermissions[i] = models.RolePermission{Role: role, Permission: permission}
+		}
+		repo.On("FindByRole", role).Return(rolePermissions, nil).Maybe()
+	}
+	repo.On("FindByRole", mock.Anything).Return([]models.RolePermission{}, nil).Maybe()
+	return NewPermissionChecker(repo)
+}
+
+func TestPermissionChecker(t *testing.T) {
+	repo := new(mocks.MockRolePermissionRepository)
+	repo.On("FindByRole", "team_lead").Return([]models.RolePermission{
+		{Role: "team_lead", Permission: PermissionLeaveReadTeam},
+	}, nil)
+	repo.On("FindByRole", "broken").Return(nil, gorm.ErrInvalidDB)
+	checker := NewPermissionChecker(repo)
+
+	// Role names are matched case-insensitively
+	allowed, err := checker.HasPermission("Team_Lead", PermissionLeaveReadTeam)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = checker.HasPermission("team_lead", PermissionLeaveApproveAll)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	// A missing role has no permissions and does not hit the database
+	permissions, err := checker.PermissionsFor("")
+	assert.NoError(t, err)
+	assert.Empty(t, permissions)
+
+	_, err = checker.HasPermission("broken", PermissionLeaveReadAll)
+	assert.ErrorIs(t, err, gorm.ErrInvalidDB)
+
+	// Roles are known when they have permissions; employee needs none
+	repo.On("FindByRole", "ceo").Return([]models.RolePermission{}, nil)
+	for role, want := range map[string]bool{"team_lead": true, "Employee": true, "ceo": false} {
+		known, err := checker.IsKnownRole(role)
+		assert.NoError(t, err)
+		assert.Equal(t, want, known, role)
+	}
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// accessTokenPurpose marks access tokens, so that other tokens signed with
+// the same keys, such as invitations, cannot be used to authenticate.
+const accessTokenPurpose = "access"
+
+// defaultTokenIssuer is the iss and aud of access tokens unless configured.
+const defaultTokenIssuer = "hr-leave-request"
+
+// TokenPair is what a successful authentication hands to the client.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// AccessClaims identifies the employee behind a validated access token. The
+// role is read from the employee record, so role changes apply immediately.
+type AccessClaims struct {
+	TokenID    string
+	EmployeeID uint
+	Email      string
+	Role       string
+	ExpiresAt  time.Time
+}
+
+// TokenService issues short-lived access tokens with rotating refresh tokens
+// and revokes them on logout or when an employee loses access.
+type TokenService interface {
+	IssueTokens(employee *models.Employee) (*TokenPair, error)
+	RefreshTokens(refreshToken string) (*TokenPair, *models.Employee, error)
+	ValidateAccessToken(accessToken string) (*AccessClaims, error)
+	RevokeAccessToken(claims *AccessClaims) error
+	RevokeRefreshToken(employeeID uint, refreshToken string) error
+	RevokeEmployeeTokens(employeeID uint) error
+}
+
+type tokenService struct {
+	employeeRepo     repositories.EmployeeRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	revokedTokenRepo repositories.RevokedTokenRepository
+	keys             JWTKeys
+	transactor       repositories.Transactor
+	cfg              *config.ApplicationConfig
+}
+
+func NewTokenService(employeeRepo repositories.EmployeeRepository, refreshTokenRepo repositories.RefreshTokenRepository, revokedTokenRepo repositories.RevokedTokenRepository, keys JWTKeys, transactor repositories.Transactor, cfg *config.ApplicationConfig) TokenService {
+	return &tokenService{
+		employeeRepo:     employeeRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		keys:             keys,
+		transactor:       transactor,
+		cfg:              cfg,
+	}
+}
+
+// IssueTokens starts a new session for the employee, with a refresh token
+// family of its own.
+func (s *tokenService) IssueTokens(employee *models.Employee) (*TokenPair, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, stored, err := s.newRefreshToken(employee.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshTokenRepo.Create(stored); err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(employee, refreshToken, stored)
+}
+
+// RefreshTokens exchanges a refresh token for a new access token and a new
+// refresh token. A refresh token that was already exchanged is treated as
+// stolen: its whole family is revoked, logging out both holders.
+func (s *tokenService) RefreshTokens(refreshToken string) (*TokenPair, *models.Employee, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		if stored.ReplacedByID != nil {
+			if err := s.refreshTokenRepo.RevokeFamily(stored.FamilyID, now); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if !now.Before(stored.ExpiresAt) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	employee, err := s.employeeRepo.FindByID(stored.EmployeeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	newRefreshToken, replacement, err := s.newRefreshToken(employee.ID, stored.FamilyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		refreshTokenRepo := s.refreshTokenRepo.WithTx(tx)
+		if err := refreshTokenRepo.Create(replacement); err != nil {
+			return err
+		}
+		rotated, err := refreshTokenRepo.Rotate(stored.ID, replacement.ID, now)
+		if err != nil {
+			return err
+		}
+		if !rotated {
+			return ErrInvalidRefreshToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pair, err := s.tokenPair(employee, newRefreshToken, replacement)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, employee, nil
+}
+
+// ValidateAccessToken verifies the signature, expiry and purpose of an access
+// token and checks that neither the token nor its employee was revoked, and
+// that it was issued after the employee's last password change.
+func (s *tokenService) ValidateAccessToken(accessToken string) (*AccessClaims, error) {
+	claims, err := s.keys.Parse(accessToken)
+	if err != nil || claims["purpose"] != accessTokenPurpose {
+		return nil, ErrInvalidToken
+	}
+	if issuer, err := claims.GetIssuer(); err != nil || issuer != s.issuer() {
+		return nil, ErrInvalidToken
+	}
+	if audience, err := claims.GetAudience(); err != nil || !slices.Contains(audience, s.audience()) {
+		return nil, ErrInvalidToken
+	}
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return nil, ErrInvalidToken
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, ErrInvalidToken
+	}
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.revokedTokenRepo.IsRevoked(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	// Deactivated employees lose access straight away
+	employee, err := s.employeeRepo.FindByID(uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenRevoked
+		}
+		return nil, err
+	}
+
+	// Changing the password ends every session started before the change
+	if employee.PasswordChangedAt != nil && issuedAt.Time.Before(*employee.PasswordChangedAt) {
+		return nil, ErrTokenRevoked
+	}
+
+	var role string
+	if employee.Role != nil {
+		role = *employee.Role
+	}
+
+	return &AccessClaims{
+		TokenID:    tokenID,
+		EmployeeID: employee.ID,
+		Email:      employee.Email,
+		Role:       role,
+		ExpiresAt:  expiresAt.Time,
+	}, nil
+}
+
+// RevokeAccessToken blocks the access token until it expires.
+func (s *tokenService) RevokeAccessToken(claims *AccessClaims) error {
+	return s.revokedTokenRepo.Create(&models.RevokedToken{
+		JTI:        claims.TokenID,
+		EmployeeID: claims.EmployeeID,
+		ExpiresAt:  claims.ExpiresAt,
+	})
+}
+
+// RevokeRefreshToken ends the session the refresh token belongs to. Only the
+// employee it was issued to can revoke it.
+func (s *tokenService) RevokeRefreshToken(employeeID uint, refreshToken string) error {
+	stored, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	if stored.EmployeeID != employeeID {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(stored.FamilyID, time.Now())
+}
+
+// RevokeEmployeeTokens ends every session of the employee. Access tokens
+// already handed out stop working once the employee record is gone.
+func (s *tokenService) RevokeEmployeeTokens(employeeID uint) error {
+	return s.refreshTokenRepo.RevokeByEmployee(employeeID, time.Now())
+}
+
+// newRefreshToken generates a refresh token and the record storing its hash.
+func (s *tokenService) newRefreshToken(employeeID uint, familyID string) (string, *models.RefreshToken, error) {
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiration := s.cfg.JWT.RefreshExpiration
+	if expiration <= 0 {
+		expiration = 720
+	}
+
+	return refreshToken, &models.RefreshToken{
+		EmployeeID: employeeID,
+		TokenHash:  hashToken(refreshToken),
+		FamilyID:   familyID,
+		ExpiresAt:  time.Now().Add(time.Hour * time.Duration(expiration)),
+	}, nil
+}
+
+func (s *tokenService) tokenPair(employee *models.Employee, refreshToken string, stored *models.RefreshToken) (*TokenPair, error) {
+	accessToken, accessExpiresAt, err := s.signAccessToken(employee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+func (s *tokenService) signAccessToken(employee *models.Employee) (string, time.Time, error) {
+	tokenID, err := randomHex(16)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiration := s.cfg.JWT.AccessExpiration
+	if expiration <= 0 {
+		expiration = 15
+	}
+	now := time.Now()
+	expiresAt := now.Add(time.Minute * time.Duration(expiration))
+
+	claims := jwt.MapClaims{
+		"purpose": accessTokenPurpose,
+		"iss":     s.issuer(),
+		"aud":     s.audience(),
+		"jti":     tokenID,
+		"user_id": employee.ID,
+		"email":   employee.Email,
+		"role":    employee.Role,
+		"exp":     expiresAt.Unix(),
+		"iat":     now.Unix(),
+	}
+
+	signed, err := s.keys.Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+func (s *tokenService) issuer() string {
+	return defaultString(s.cfg.JWT.Issuer, defaultTokenIssuer)
+}
+
+func (s *tokenService) audience() string {
+	return defaultString(s.cfg.JWT.Audience, defaultTokenIssuer)
+}
+
+// hashToken returns the hex-encoded SHA-256 of a token, as stored in the
+// database.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns n random bytes encoded for use in URLs.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// newTestTokenService returns a token service that stores refresh tokens
+// without checking them and has no revoked access tokens.
+func newTestTokenService(employeeRepo *mocks.MockEmployeeRepository) TokenService {
+	refreshTokenRepo := new(mocks.MockRefreshTokenRepository)
+	refreshTokenRepo.On("Create", mock.AnythingOfType("*models.RefreshToken")).Return(nil).Maybe()
+	revokedTokenRepo := new(mocks.MockRevokedTokenRepository)
+	revokedTokenRepo.On("IsRevoked", mock.Anything).Return(false, nil).Maybe()
+	return NewTokenService(employeeRepo, refreshTokenRepo, revokedTokenRepo, newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+}
+
+func TestIssueTokens(t *testing.T) {
+	role := "employee"
+	cfg := setupTestConfig()
+
+	tests := []struct {
+		name      string
+		employee  *models.Employee
+		checkFunc func(string)
+	}{
+		{
+			name: "generate valid token",
+			employee: &models.Employee{
+				ID:    1,
+				Name:  "John Doe",
+				Email: "john@example.com",
+				Role:  &role,
+			},
+			checkFunc: func(tokenString string) {
+				assert.NotEmpty(t, tokenString)
+
+				// Parse and verify token
+				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+					// Verify signing method
+					_, ok := token.Method.(*jwt.SigningMethodHMAC)
+					assert.True(t, ok)
+					return []byte(cfg.JWT.Secret), nil
+				})
+
+				assert.NoError(t, err)
+				assert.True(t, token.Valid)
+
+				// Verify claims
+				claims, ok := token.Claims.(jwt.MapClaims)
+				assert.True(t, ok)
+				assert.Equal(t, float64(1), claims["user_id"])
+				assert.Equal(t, "john@example.com", claims["email"])
+				assert.Equal(t, role, claims["role"])
+				assert.Equal(t, "access", claims["purpose"])
+				assert.Equal(t, "hr-leave-request", claims["iss"])
+				assert.Equal(t, "hr-leave-request", claims["aud"])
+				assert.Equal(t, "at+jwt", token.Header["typ"])
+				assert.Len(t, claims["jti"], 32)
+
+				// Verify the token is short-lived
+				exp, ok := claims["exp"].(float64)
+				assert.True(t, ok)
+				assert.Greater(t, exp, float64(time.Now().Unix()))
+				assert.LessOrEqual(t, exp, float64(time.Now().Add(15*time.Minute).Unix()))
+
+				// Verify issued at is set
+				iat, ok := claims["iat"].(float64)
+				assert.True(t, ok)
+				assert.LessOrEqual(t, iat, float64(time.Now().Unix()))
+			},
+		},
+		{
+			name: "generate token with nil role",
+			employee: &models.Employee{
+				ID:    2,
+				Name:  "Jane Doe",
+				Email: "jane@example.com",
+				Role:  nil,
+			},
+			checkFunc: func(tokenString string) {
+				assert.NotEmpty(t, tokenString)
+
+				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+					return []byte(cfg.JWT.Secret), nil
+				})
+
+				assert.NoError(t, err)
+				assert.True(t, token.Valid)
+
+				claims, ok := token.Claims.(jwt.MapClaims)
+				assert.True(t, ok)
+				assert.Nil(t, claims["role"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRefreshRepo := new(mocks.MockRefreshTokenRepository)
+			mockRefreshRepo.On("Create", mock.MatchedBy(func(token *models.RefreshToken) bool {
+				return token.EmployeeID == tt.employee.ID && len(token.TokenHash) == 64 && len(token.FamilyID) == 32
+			})).Return(nil)
+			service := NewTokenService(new(mocks.MockEmployeeRepository), mockRefreshRepo, new(mocks.MockRevokedTokenRepository), newTestJWTKeys(), &mocks.MockTransactor{}, cfg)
+
+			tokens, err := service.IssueTokens(tt.employee)
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, tokens.RefreshToken)
+			assert.WithinDuration(t, time.Now().Add(720*time.Hour), tokens.RefreshExpiresAt, time.Minute)
+			if tt.checkFunc != nil {
+				tt.checkFunc(tokens.AccessToken)
+			}
+			// Only the hash of the refresh token is stored
+			mockRefreshRepo.AssertCalled(t, "Create", mock.MatchedBy(func(token *models.RefreshToken) bool {
+				return token.TokenHash == hashToken(tokens.RefreshToken)
+			}))
+		})
+	}
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	role := "manager"
+	employee := &models.Employee{ID: 1, Email: "john@example.com", Role: &role}
+	tokens, err := newTestTokenService(new(mocks.MockEmployeeRepository)).IssueTokens(employee)
+	assert.NoError(t, err)
+	invitationToken, err := signInvitationToken(newTestJWTKeys(), &models.Invitation{ID: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	mfaToken, _, err := signMFAToken(newTestJWTKeys(), 1)
+	assert.NoError(t, err)
+	otherAudienceToken, err := newTestJWTKeys().Sign(jwt.MapClaims{
+		"purpose": accessTokenPurpose,
+		"iss":     "hr-leave-request",
+		"aud":     "payroll",
+		"jti":     "abc",
+		"user_id": 1,
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"iat":     time.Now().Unix(),
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		token     string
+		mockSetup func(*mocks.MockEmployeeRepository, *mocks.MockRevokedTokenRepository)
+		wantError error
+	}{
+		{
+			name:  "valid token",
+			token: tokens.AccessToken,
+			mockSetup: func(empRepo *mocks.MockEmployeeRepository, revokedRepo *mocks.MockRevokedTokenRepository) {
+				revokedRepo.On("IsRevoked", mock.Anything).Return(false, nil)
+				hr := "hr"
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "john@example.com", Role: &hr}, nil)
+			},
+		},
+		{
+			name:  "revoked token",
+			token: tokens.AccessToken,
+			mockSetup: func(empRepo *mocks.MockEmployeeRepository, revokedRepo *mocks.MockRevokedTokenRepository) {
+				revokedRepo.On("IsRevoked", mock.Anything).Return(true, nil)
+			},
+			wantError: ErrTokenRevoked,
+		},
+		{
+			name:  "employee no longer exists",
+			token: tokens.AccessToken,
+			mockSetup: func(empRepo *mocks.MockEmployeeRepository, revokedRepo *mocks.MockRevokedTokenRepository) {
+				revokedRepo.On("IsRevoked", mock.Anything).Return(false, nil)
+				empRepo.On("FindByID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrTokenRevoked,
+		},
+		{
+			name:  "password changed after the token was issued",
+			token: tokens.AccessToken,
+			mockSetup: func(empRepo *mocks.MockEmployeeRepository, revokedRepo *mocks.MockRevokedTokenRepository) {
+				revokedRepo.On("IsRevoked", mock.Anything).Return(false, nil)
+				changedAt := time.Now().Add(time.Minute)
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, PasswordChangedAt: &changedAt}, nil)
+			},
+			wantError: ErrTokenRevoked,
+		},
+		{
+			name:      "invitation token",
+			token:     invitationToken,
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockRevokedTokenRepository) {},
+			wantError: ErrInvalidToken,
+		},
+		{
+			name:      "pending MFA token",
+			token:     mfaToken,
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockRevokedTokenRepository) {},
+			wantError: ErrInvalidToken,
+		},
+		{
+			name:      "token for another audience",
+			token:     otherAudienceToken,
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockRevokedTokenRepository) {},
+			wantError: ErrInvalidToken,
+		},
+		{
+			name:      "malformed token",
+			token:     "not-a-token",
+			mockSetup: func(*mocks.MockEmployeeRepository, *mocks.MockRevokedTokenRepository) {},
+			wantError: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			mockRevokedRepo := new(mocks.MockRevokedTokenRepository)
+			tt.mockSetup(mockEmpRepo, mockRevokedRepo)
+
+			service := NewTokenService(mockEmpRepo, new(mocks.MockRefreshTokenRepository), mockRevokedRepo, newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+			claims, err := service.ValidateAccessToken(tt.token)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, claims)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint(1), claims.EmployeeID)
+				// The role comes from the employee record, not the token
+				assert.Equal(t, "hr", claims.Role)
+				assert.NotEmpty(t, claims.TokenID)
+			}
+
+			mockEmpRepo.AssertExpectations(t)
+			mockRevokedRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	refreshToken := "refresh-token"
+	revokedAt := time.Now().Add(-time.Minute)
+	replacedByID := uint(8)
+
+	tests := []struct {
+		name      string
+		mockSetup func(*mocks.MockRefreshTokenRepository, *mocks.MockEmployeeRepository)
+		wantError error
+	}{
+		{
+			name: "token is rotated",
+			mockSetup: func(repo *mocks.MockRefreshTokenRepository, empRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByHash", hashToken(refreshToken)).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1, Email: "john@example.com"}, nil)
+				repo.On("Create", mock.MatchedBy(func(token *models.RefreshToken) bool {
+					return token.FamilyID == "family" && token.TokenHash != hashToken(refreshToken)
+				})).Return(nil).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.RefreshToken).ID = 8
+				})
+				repo.On("Rotate", uint(7), uint(8), mock.AnythingOfType("time.Time")).Return(true, nil)
+			},
+		},
+		{
+			name: "reused token revokes its family",
+			mockSetup: func(repo *mocks.MockRefreshTokenRepository, empRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByHash", hashToken(refreshToken)).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt, ReplacedByID: &replacedByID}, nil)
+				repo.On("RevokeFamily", "family", mock.AnythingOfType("time.Time")).Return(nil)
+			},
+			wantError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			mockSetup: func(repo *mocks.MockRefreshTokenRepository, empRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByHash", hashToken(refreshToken)).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+			},
+			wantError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "concurrent refresh loses the race",
+			mockSetup: func(repo *mocks.MockRefreshTokenRepository, empRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByHash", hashToken(refreshToken)).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				empRepo.On("FindByID", uint(1)).Return(&models.Employee{ID: 1}, nil)
+				repo.On("Create", mock.Anything).Return(nil)
+				repo.On("Rotate", uint(7), uint(0), mock.AnythingOfType("time.Time")).Return(false, nil)
+			},
+			wantError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "unknown token",
+			mockSetup: func(repo *mocks.MockRefreshTokenRepository, empRepo *mocks.MockEmployeeRepository) {
+				repo.On("FindByHash", hashToken(refreshToken)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			wantError: ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockRefreshTokenRepository)
+			mockEmpRepo := new(mocks.MockEmployeeRepository)
+			tt.mockSetup(mockRepo, mockEmpRepo)
+
+			service := NewTokenService(mockEmpRepo, mockRepo, new(mocks.MockRevokedTokenRepository), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+			tokens, employee, err := service.RefreshTokens(refreshToken)
+
+			if tt.wantError != nil {
+				assert.ErrorIs(t, err, tt.wantError)
+				assert.Nil(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+				assert.Equal(t, uint(1), employee.ID)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockEmpRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	mockRepo := new(mocks.MockRefreshTokenRepository)
+	mockRepo.On("FindByHash", hashToken("refresh-token")).Return(&models.RefreshToken{ID: 7, EmployeeID: 1, FamilyID: "family"}, nil)
+	mockRepo.On("RevokeFamily", "family", mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	service := NewTokenService(new(mocks.MockEmployeeRepository), mockRepo, new(mocks.MockRevokedTokenRepository), newTestJWTKeys(), &mocks.MockTransactor{}, setupTestConfig())
+
+	// Another employee's refresh token cannot be revoked
+	assert.ErrorIs(t, service.RevokeRefreshToken(2, "refresh-token"), ErrInvalidRefreshToken)
+	assert.NoError(t, service.RevokeRefreshToken(1, "refresh-token"))
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfcTOTPSecret is the SHA-1 test key of RFC 6238, "12345678901234567890".
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors from RFC 6238, truncated to six digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := totpCode(rfcTOTPSecret, tt.unix/totpPeriod)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.code, code)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	step := at.Unix() / totpPeriod
+	previous, _ := totpCode(rfcTOTPSecret, step-1)
+
+	matched, ok := verifyTOTP(rfcTOTPSecret, "081804", at, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	// A code from the previous step is accepted for clock drift
+	matched, ok = verifyTOTP(rfcTOTPSecret, previous, at, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step-1, matched)
+
+	// Codes are refused once their step was used
+	_, ok = verifyTOTP(rfcTOTPSecret, "081804", at, step)
+	assert.False(t, ok)
+
+	_, ok = verifyTOTP(rfcTOTPSecret, "000000", at, 0)
+	assert.False(t, ok)
+	_, ok = verifyTOTP(rfcTOTPSecret, "81804", at, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri := totpProvisioningURI("HR Leave", "john@example.com", secret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/HR%20Leave:john@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=HR+Leave")
+}
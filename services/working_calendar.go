@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"hr-leave-request/config"
+	"hr-leave-request/repositories"
+	"strings"
+	"time"
+)
+
+// WorkingCalendar knows which days are working days and computes the
+// chargeable duration of a leave request from it. Public holidays come from
+// both the configuration and the holidays table.
+type WorkingCalendar interface {
+	IsWorkingDay(date time.Time) (bool, error)
+	WorkingDays(startDate, endDate time.Time) (float64, error)
+	HoursPerDay() float64
+}
+
+type workingCalendar struct {
+	holidayRepo repositories.HolidayRepository
+	weekend     map[time.Weekday]bool
+	holidays    map[string]bool
+	hoursPerDay float64
+}
+
+func NewWorkingCalendar(holidayRepo repositories.HolidayRepository, cfg *config.ApplicationConfig) (WorkingCalendar, error) {
+	weekendNames := cfg.Calendar.Weekend
+	if len(weekendNames) == 0 {
+		weekendNames = []string{"saturday", "sunday"}
+	}
+
+	weekend := make(map[time.Weekday]bool, len(weekendNames))
+	for _, name := range weekendNames {
+		weekday, err := parseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+		weekend[weekday] = true
+	}
+
+	holidays := make(map[string]bool, len(cfg.Calendar.Holidays))
+	for _, holiday := range cfg.Calendar.Holidays {
+		date, err := time.Parse(time.DateOnly, holiday)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: %w", holiday, err)
+		}
+		holidays[date.Format(time.DateOnly)] = true
+	}
+
+	hoursPerDay := cfg.Calendar.HoursPerDay
+	if hoursPerDay == 0 {
+		hoursPerDay = 8
+	}
+	if hoursPerDay < 0 || hoursPerDay > 24 {
+		return nil, fmt.Errorf("invalid hours per day %v", hoursPerDay)
+	}
+
+	return &workingCalendar{
+		holidayRepo: holidayRepo,
+		weekend:     weekend,
+		holidays:    holidays,
+		hoursPerDay: hoursPerDay,
+	}, nil
+}
+
+func (c *workingCalendar) IsWorkingDay(date time.Time) (bool, error) {
+	day := truncateToDate(date)
+
+	holidays, err := c.holidaysBetween(day, day)
+	if err != nil {
+		return false, err
+	}
+
+	return c.isWorkingDay(day, holidays), nil
+}
+
+// WorkingDays counts the working days between the calendar dates of startDate
+// and endDate, both inclusive. The time of day is ignored.
+func (c *workingCalendar) WorkingDays(startDate, endDate time.Time) (float64, error) {
+	day := truncateToDate(startDate)
+	last := truncateToDate(endDate)
+
+	holidays, err := c.holidaysBetween(day, last)
+	if err != nil {
+		return 0, err
+	}
+
+	days := 0.0
+	for !day.After(last) {
+		if c.isWorkingDay(day, holidays) {
+			days++
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return days, nil
+}
+
+// HoursPerDay is the length of a working day in hours.
+func (c *workingCalendar) HoursPerDay() float64 {
+	return c.hoursPerDay
+}
+
+func (c *workingCalendar) isWorkingDay(day time.Time, holidays map[string]bool) bool {
+	if c.weekend[day.Weekday()] {
+		return false
+	}
+	key := day.Format(time.DateOnly)
+	return !c.holidays[key] && !holidays[key]
+}
+
+// holidaysBetween loads the stored holidays in the date range as a set of
+// YYYY-MM-DD keys.
+func (c *workingCalendar) holidaysBetween(startDate, endDate time.Time) (map[string]bool, error) {
+	stored, err := c.holidayRepo.FindBetween(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := make(map[string]bool, len(stored))
+	for _, holiday := range stored {
+		holidays[holiday.Date.Format(time.DateOnly)] = true
+	}
+	return holidays, nil
+}
+
+// truncateToDate strips the time of day while keeping the calendar date as
+// seen in the time's own location.
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), name) {
+			return weekday, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", name)
+}
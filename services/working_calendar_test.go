@@ -0,0 +1,125 @@
+package services
+
+import (
+	"errors"
+	"hr-leave-request/config"
+	"hr-leave-request/models"
+	"hr-leave-request/repositories/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWorkingDays(t *testing.T) {
+	cfg := setupTestConfig()
+	cfg.Calendar = config.CalendarConfig{
+		Holidays: []string{"2026-12-25"},
+	}
+	holidayRepo := new(mocks.MockHolidayRepository)
+	holidayRepo.On("FindBetween", mock.Anything, mock.Anything).Return([]models.Holiday{
+		{ID: 1, Date: time.Date(2026, 12, 24, 0, 0, 0, 0, time.Local), Name: "Christmas Eve"},
+	}, nil)
+	calendar, err := NewWorkingCalendar(holidayRepo, cfg)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		want      float64
+	}{
+		{
+			name:      "single working day",
+			startDate: time.Date(2026, 12, 21, 9, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2026, 12, 21, 17, 0, 0, 0, time.UTC),
+			want:      1,
+		},
+		{
+			name:      "full week excludes the weekend",
+			startDate: time.Date(2026, 12, 14, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+			want:      5,
+		},
+		{
+			name:      "configured and stored holidays are not charged",
+			startDate: time.Date(2026, 12, 21, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2026, 12, 25, 23, 59, 0, 0, time.UTC),
+			want:      3,
+		},
+		{
+			name:      "weekend only",
+			startDate: time.Date(2026, 12, 19, 0, 0, 0, 0, time.UTC),
+			endDate:   time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+			want:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, err := calendar.WorkingDays(tt.startDate, tt.endDate)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, days)
+		})
+	}
+}
+
+func TestWorkingDaysHolidayLookupFails(t *testing.T) {
+	holidayRepo := new(mocks.MockHolidayRepository)
+	holidayRepo.On("FindBetween", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+	calendar, err := NewWorkingCalendar(holidayRepo, setupTestConfig())
+	assert.NoError(t, err)
+
+	_, err = calendar.WorkingDays(time.Now(), time.Now())
+	assert.Error(t, err)
+}
+
+func TestNewWorkingCalendar(t *testing.T) {
+	tests := []struct {
+		name      string
+		calendar  config.CalendarConfig
+		wantError bool
+	}{
+		{
+			name:      "custom weekend",
+			calendar:  config.CalendarConfig{Weekend: []string{"Friday", "saturday"}},
+			wantError: false,
+		},
+		{
+			name:      "invalid weekday",
+			calendar:  config.CalendarConfig{Weekend: []string{"funday"}},
+			wantError: true,
+		},
+		{
+			name:      "invalid holiday",
+			calendar:  config.CalendarConfig{Holidays: []string{"25/12/2026"}},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := setupTestConfig()
+			cfg.Calendar = tt.calendar
+
+			holidayRepo := new(mocks.MockHolidayRepository)
+			holidayRepo.On("FindBetween", mock.Anything, mock.Anything).Return([]models.Holiday{}, nil).Maybe()
+
+			calendar, err := NewWorkingCalendar(holidayRepo, cfg)
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Nil(t, calendar)
+			} else {
+				assert.NoError(t, err)
+				// 2026-12-18 is a Friday
+				friday, err := calendar.IsWorkingDay(time.Date(2026, 12, 18, 0, 0, 0, 0, time.UTC))
+				assert.NoError(t, err)
+				assert.False(t, friday)
+				sunday, err := calendar.IsWorkingDay(time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC))
+				assert.NoError(t, err)
+				assert.True(t, sunday)
+			}
+		})
+	}
+}